@@ -0,0 +1,459 @@
+package converter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ChangeAction classifies an event of a change list.
+type ChangeAction int
+
+const (
+	// ChangeListInsert inserts frames, moving everything after them later.
+	ChangeListInsert ChangeAction = iota
+	// ChangeListDelete deletes frames, moving everything after them earlier.
+	ChangeListDelete
+	// ChangeListReplace replaces frames with as many frames of other material, moving nothing.
+	ChangeListReplace
+	// ChangeListMove moves frames of the old version to another record frame, moving the material between the two
+	// the other way and leaving the duration as it is.
+	ChangeListMove
+)
+
+func (a ChangeAction) String() string {
+	switch a {
+	case ChangeListInsert:
+		return "insert"
+	case ChangeListDelete:
+		return "delete"
+	case ChangeListReplace:
+		return "replace"
+	case ChangeListMove:
+		return "move"
+	}
+
+	return "unknown"
+}
+
+// ChangeListOptions describes which tracks a change list considers.
+type ChangeListOptions struct {
+	// VideoTracks and AudioTracks are the tracks considered, counting from 1. Every track is considered when both
+	// are nil, e.g. VideoTracks: []int{1} considers the first video track only, and AudioTracks: []int{1, 2, 3, 4}
+	// the first four audio tracks only.
+	VideoTracks []int
+	AudioTracks []int
+}
+
+// ChangeListEvent describes an instruction of a change list.
+type ChangeListEvent struct {
+	// Number counts events from 1 in cut order.
+	Number int
+	Action ChangeAction
+	// Record is the record frame of the event in the new version, which is where it applies to the old version once
+	// every event before it has been applied.
+	Record int
+	// Frames is the number of frames inserted, deleted, replaced or moved.
+	Frames int
+	// From is the record frame of the old version moved frames played at, for a move.
+	From int
+	// Offset is the running offset once the event is applied, the frames inserted minus the frames deleted by the
+	// event and every event before it. Material of the old version after the event plays Offset frames later, except
+	// for the material between the ends of a move.
+	Offset int
+	// Clips names the clip items playing the frames inserted, replaced or moved in the new version, or the frames
+	// deleted from the old version.
+	Clips []string
+}
+
+// ChangeList describes the instructions that turn the old version of a sequence into the new version, in cut order,
+// e.g. for a sound editor to update a session cut to the old version.
+type ChangeList struct {
+	Events []*ChangeListEvent
+	// BeforeDuration and AfterDuration are the lengths of the versions in frames, up to the end of their last clip
+	// item on the tracks considered.
+	BeforeDuration int
+	AfterDuration  int
+
+	before *Sequence
+	after  *Sequence
+	tracks []string
+	// material that plays in both versions in another order
+	moves []changeSpan
+}
+
+// Difference returns the change in duration between the versions in frames, which is the offset after the last event.
+func (cl *ChangeList) Difference() int {
+	return cl.AfterDuration - cl.BeforeDuration
+}
+
+// Inserted returns the total number of frames inserted.
+func (cl *ChangeList) Inserted() int {
+	return cl.total(ChangeListInsert)
+}
+
+// Deleted returns the total number of frames deleted.
+func (cl *ChangeList) Deleted() int {
+	return cl.total(ChangeListDelete)
+}
+
+// Moved returns the total number of frames moved.
+func (cl *ChangeList) Moved() int {
+	return cl.total(ChangeListMove)
+}
+
+func (cl *ChangeList) total(a ChangeAction) int {
+	n := 0
+	for _, e := range cl.Events {
+		if e.Action == a {
+			n += e.Frames
+		}
+	}
+
+	return n
+}
+
+// changeSpan describes material that plays in both versions, from old to old+length in the old version and from
+// new to new+length in the new version.
+type changeSpan struct {
+	old    int
+	new    int
+	length int
+	// name is the name of the clip item of the new version playing the material
+	name string
+}
+
+// ChangeList returns the instructions that turn the old version of a sequence into the new version on the tracks
+// considered. Material of clip items that plays in both versions, in the same order, anchors the versions to each
+// other, and material that plays in both in another order, e.g. of a reordered clip item, is moved. Between anchors,
+// other frames only played by the new version are inserted, frames only played by the old version are deleted, and
+// frames played by both from different material are replaced. Both versions must have the same rate.
+func (d *SequenceDiff) ChangeList(o ChangeListOptions) (*ChangeList, error) {
+	if !d.Before.Rate.Equal(*d.After.Rate) {
+		return nil, errors.New("versions of the sequence have different rates")
+	}
+
+	considered := func(e *DiffEvent) bool {
+		if o.VideoTracks == nil && o.AudioTracks == nil {
+			return true
+		}
+
+		tracks := o.AudioTracks
+		if e.Media == "video" {
+			tracks = o.VideoTracks
+		}
+		for _, t := range tracks {
+			if t == e.Track {
+				return true
+			}
+		}
+		return false
+	}
+
+	cl := &ChangeList{before: d.Before, after: d.After, tracks: changeListTracks(o, d)}
+
+	var before, after []*DiffEvent
+	var spans, moves []changeSpan
+	for _, p := range d.pairs {
+		if !considered(p.before) || !considered(p.after) {
+			continue
+		}
+		before, after = append(before, p.before), append(after, p.after)
+		if s, ok := d.commonSpan(p); ok && p.reordered {
+			moves = append(moves, s)
+		} else if ok {
+			spans = append(spans, s)
+		}
+	}
+	for _, e := range d.deleted {
+		if considered(e) {
+			before = append(before, e)
+		}
+	}
+	for _, e := range d.inserted {
+		if considered(e) {
+			after = append(after, e)
+		}
+	}
+
+	for _, e := range before {
+		cl.BeforeDuration = maxInt(cl.BeforeDuration, e.End)
+	}
+	for _, e := range after {
+		cl.AfterDuration = maxInt(cl.AfterDuration, e.End)
+	}
+
+	spans, conflicts := chainSpans(spans)
+	spans = append(spans, changeSpan{old: cl.BeforeDuration, new: cl.AfterDuration})
+	cl.moves = mergeSpans(append(moves, conflicts...))
+
+	prev := changeSpan{}
+	for _, s := range spans {
+		cl.gap(prev.old+prev.length, s.old, prev.new+prev.length, s.new, before, after)
+		prev = s
+	}
+
+	return cl, nil
+}
+
+// commonSpan returns the material of a pair of clip items that plays in both versions.
+func (d *SequenceDiff) commonSpan(p *diffPair) (changeSpan, bool) {
+	b, a := p.before, p.after
+	from, to := maxInt(b.In, a.In), minInt(b.Out, a.Out)
+	if to <= from {
+		return changeSpan{}, false
+	}
+
+	r := *d.After.Rate
+	s := changeSpan{
+		old:    b.Start + ConvertFrames(from-b.In, b.rate, r),
+		new:    a.Start + ConvertFrames(from-a.In, a.rate, r),
+		length: ConvertFrames(to-from, a.rate, r),
+		name:   string(a.Item.Name),
+	}
+
+	// clip items that start or end in a transition play less of their source range on their own
+	s.length = minInt(s.length, minInt(b.End-s.old, a.End-s.new))
+
+	return s, s.length > 0
+}
+
+// chainSpans keeps the spans that play in the same order in both versions, in order, merging spans that overlap
+// with the same offset, e.g. the video and audio of a clip. Spans that conflict with the spans kept before them are
+// returned apart.
+func chainSpans(spans []changeSpan) ([]changeSpan, []changeSpan) {
+	sortSpans(spans)
+
+	var chain, conflicts []changeSpan
+	for _, s := range spans {
+		if len(chain) == 0 {
+			chain = append(chain, s)
+			continue
+		}
+
+		last := &chain[len(chain)-1]
+		lastOld, lastNew := last.old+last.length, last.new+last.length
+		switch {
+		case s.new-s.old == last.new-last.old && s.new <= lastNew:
+			last.length = maxInt(last.length, s.new+s.length-last.new)
+		case s.old >= lastOld && s.new >= lastNew:
+			chain = append(chain, s)
+		default:
+			conflicts = append(conflicts, s)
+		}
+	}
+
+	return chain, conflicts
+}
+
+// mergeSpans returns spans in order, merging spans that overlap with the same offset.
+func mergeSpans(spans []changeSpan) []changeSpan {
+	sortSpans(spans)
+
+	var merged []changeSpan
+	for _, s := range spans {
+		if n := len(merged); n > 0 {
+			last := &merged[n-1]
+			if s.new-s.old == last.new-last.old && s.new <= last.new+last.length {
+				last.length = maxInt(last.length, s.new+s.length-last.new)
+				continue
+			}
+		}
+		merged = append(merged, s)
+	}
+
+	return merged
+}
+
+// sortSpans sorts spans by their frame in the new version, longest first.
+func sortSpans(spans []changeSpan) {
+	sort.SliceStable(spans, func(i, j int) bool {
+		if spans[i].new != spans[j].new {
+			return spans[i].new < spans[j].new
+		}
+		return spans[i].length > spans[j].length
+	})
+}
+
+// gap adds the events that turn a range of the old version into a range of the new version. Moved material is moved
+// to where it plays in the range of the new version, and is neither inserted, deleted nor replaced.
+func (cl *ChangeList) gap(oldFrom int, oldTo int, newFrom int, newTo int, before []*DiffEvent, after []*DiffEvent) {
+	var events []*ChangeListEvent
+	movedIn, movedOut := 0, 0
+	rest := newFrom
+	for _, m := range cl.moves {
+		if from, to := maxInt(m.new, newFrom), minInt(m.new+m.length, newTo); to > from {
+			movedIn += to - from
+			events = append(events, &ChangeListEvent{Action: ChangeListMove, Record: from, Frames: to - from,
+				From: m.old + from - m.new, Clips: []string{m.name}})
+			if from == rest {
+				rest = to
+			}
+		}
+		if from, to := maxInt(m.old, oldFrom), minInt(m.old+m.length, oldTo); to > from {
+			movedOut += to - from
+		}
+	}
+
+	oldLength, newLength := oldTo-oldFrom-movedOut, newTo-newFrom-movedIn
+	oldClips := clipsBetween(cl.unmoved(before, true), oldFrom, oldTo)
+	newClips := clipsBetween(cl.unmoved(after, false), newFrom, newTo)
+
+	// frames of filler that only change length are inserted or deleted, not replaced
+	if oldLength > 0 && newLength > 0 && (len(oldClips) > 0 || len(newClips) > 0) {
+		events = append(events, &ChangeListEvent{Action: ChangeListReplace, Record: rest,
+			Frames: minInt(oldLength, newLength), Clips: newClips})
+	}
+
+	switch {
+	case newLength > oldLength:
+		events = append(events, &ChangeListEvent{Action: ChangeListInsert, Record: rest + maxInt(oldLength, 0),
+			Frames: newLength - oldLength, Clips: newClips})
+	case oldLength > newLength:
+		events = append(events, &ChangeListEvent{Action: ChangeListDelete, Record: rest + maxInt(newLength, 0),
+			Frames: oldLength - newLength, Clips: oldClips})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Record < events[j].Record
+	})
+	for _, e := range events {
+		cl.add(e)
+	}
+}
+
+// unmoved returns the clip items of a version that do not play within moved material, the old version when old is
+// set.
+func (cl *ChangeList) unmoved(events []*DiffEvent, old bool) []*DiffEvent {
+	var kept []*DiffEvent
+	for _, e := range events {
+		moved := false
+		for _, m := range cl.moves {
+			from := m.new
+			if old {
+				from = m.old
+			}
+			if e.Start >= from && e.End <= from+m.length {
+				moved = true
+				break
+			}
+		}
+		if !moved {
+			kept = append(kept, e)
+		}
+	}
+
+	return kept
+}
+
+// add numbers an event and works out its running offset.
+func (cl *ChangeList) add(e *ChangeListEvent) {
+	offset := 0
+	if n := len(cl.Events); n > 0 {
+		offset = cl.Events[n-1].Offset
+	}
+
+	switch e.Action {
+	case ChangeListInsert:
+		offset += e.Frames
+	case ChangeListDelete:
+		offset -= e.Frames
+	}
+
+	e.Number = len(cl.Events) + 1
+	e.Offset = offset
+	cl.Events = append(cl.Events, e)
+}
+
+// clipsBetween names the clip items that play between two record frames, in record order.
+func clipsBetween(events []*DiffEvent, from int, to int) []string {
+	var playing []*DiffEvent
+	for _, e := range events {
+		if e.Start < to && e.End > from {
+			playing = append(playing, e)
+		}
+	}
+
+	sort.SliceStable(playing, func(i, j int) bool {
+		return lessEvent(playing[i], playing[j])
+	})
+
+	var names []string
+	seen := map[string]bool{}
+	for _, e := range playing {
+		if n := string(e.Item.Name); !seen[n] {
+			seen[n] = true
+			names = append(names, n)
+		}
+	}
+
+	return names
+}
+
+// changeListTracks labels the tracks a change list considers, e.g. V1 or A2.
+func changeListTracks(o ChangeListOptions, d *SequenceDiff) []string {
+	var labels []string
+	if o.VideoTracks == nil && o.AudioTracks == nil {
+		for _, e := range append(diffEvents(d.Before), diffEvents(d.After)...) {
+			labels = append(labels, trackLabel(e))
+		}
+	} else {
+		for _, t := range o.VideoTracks {
+			labels = append(labels, trackLabel(&DiffEvent{Media: "video", Track: t}))
+		}
+		for _, t := range o.AudioTracks {
+			labels = append(labels, trackLabel(&DiffEvent{Media: "audio", Track: t}))
+		}
+	}
+
+	var unique []string
+	seen := map[string]bool{}
+	for _, l := range labels {
+		if !seen[l] {
+			seen[l] = true
+			unique = append(unique, l)
+		}
+	}
+
+	return unique
+}
+
+// WriteText writes a change list with record timecodes of the new version, followed by a summary of the frames
+// inserted and deleted and of the change in duration.
+func (cl *ChangeList) WriteText(w io.Writer) error {
+	r := *cl.after.Rate
+	record := timeCoder(cl.after.TimeCode, r)
+	source := timeCoder(cl.before.TimeCode, r)
+	length := func(frames int) string {
+		return FormatTimeCode(frames, r, false)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "CHANGE LIST: %s -> %s\n", cl.before.Name, cl.after.Name)
+	fmt.Fprintf(&b, "TRACKS: %s\n", strings.Join(cl.tracks, " "))
+	for _, e := range cl.Events {
+		fmt.Fprintf(&b, "\n%03d  %-7s %s  %6d frames  offset %+d", e.Number, strings.ToUpper(e.Action.String()),
+			record(e.Record), e.Frames, e.Offset)
+		if e.Action == ChangeListMove {
+			fmt.Fprintf(&b, "  from %s", source(e.From))
+		}
+		b.WriteString("\n")
+		for _, c := range e.Clips {
+			fmt.Fprintf(&b, "* CLIP NAME: %s\n", c)
+		}
+	}
+
+	fmt.Fprintf(&b, "\nEVENTS: %d\nINSERTED: %d frames\nDELETED: %d frames\n", len(cl.Events), cl.Inserted(), cl.Deleted())
+	if moved := cl.Moved(); moved > 0 {
+		fmt.Fprintf(&b, "MOVED: %d frames\n", moved)
+	}
+	fmt.Fprintf(&b, "DURATION: %s -> %s (%+d frames)\n", length(cl.BeforeDuration), length(cl.AfterDuration),
+		cl.Difference())
+
+	_, err := b.WriteTo(w)
+
+	return err
+}
@@ -0,0 +1,166 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func changeListEvents(cl *ChangeList) string {
+	var events []string
+	for _, e := range cl.Events {
+		events = append(events, fmt.Sprintf("%s %d %d %+d %s", e.Action, e.Record, e.Frames, e.Offset, strings.Join(e.Clips, ",")))
+	}
+
+	return strings.Join(events, "\n")
+}
+
+func TestMakingChangeLists(t *testing.T) {
+	before := diffSequence("Cut 1", []*ClipItem{
+		diffItem("A", 0, 50, 0, 50),
+		diffItem("B", 50, 100, 0, 50),
+		diffItem("C", 100, 150, 0, 50),
+		diffItem("D", 150, 200, 0, 50),
+	})
+	after := diffSequence("Cut 2", []*ClipItem{
+		diffItem("A", 0, 50, 0, 50),
+		diffItem("X", 50, 62, 0, 12),
+		diffItem("B", 62, 102, 0, 40),
+		diffItem("D", 102, 152, 0, 50),
+		diffItem("Y", 160, 170, 0, 10),
+	})
+
+	d, err := DiffSequences(before, after)
+	if err != nil {
+		t.Fatal("sequences could not be compared: " + err.Error())
+	}
+
+	cl, err := d.ChangeList(ChangeListOptions{})
+	if err != nil {
+		t.Fatal("change list could not be made: " + err.Error())
+	}
+
+	want := strings.Join([]string{
+		"insert 50 12 +12 X",
+		"delete 102 60 -48 B,C",
+		"insert 152 18 -30 Y",
+	}, "\n")
+	if got := changeListEvents(cl); got != want {
+		t.Errorf("events do not match expectations:\n%s", got)
+	}
+
+	if cl.BeforeDuration != 200 || cl.AfterDuration != 170 || cl.Difference() != -30 || cl.Inserted() != 30 || cl.Deleted() != 60 {
+		t.Error("summary does not match expectations")
+	}
+
+	if cl.Difference() != cl.Events[len(cl.Events)-1].Offset {
+		t.Error("running offset does not add up to the change in duration")
+	}
+}
+
+func TestMakingChangeListsOfTracks(t *testing.T) {
+	before := diffSequence("Cut 1", []*ClipItem{diffItem("A", 0, 50, 0, 50), diffItem("B", 50, 100, 0, 50)})
+	before.Media.Audio = &Audio{Tracks: []*Track{{ClipItems: []*ClipItem{diffItem("A", 0, 50, 0, 50), diffItem("B", 50, 100, 0, 50)}}}}
+
+	// the picture is replaced by a shot of the same length, the sound of B is slipped by 5 frames
+	after := diffSequence("Cut 2", []*ClipItem{diffItem("A", 0, 50, 0, 50), diffItem("Z", 50, 100, 0, 50)})
+	after.Media.Audio = &Audio{Tracks: []*Track{{ClipItems: []*ClipItem{diffItem("A", 0, 50, 0, 50), diffItem("B", 50, 100, 5, 55)}}}}
+
+	d, err := DiffSequences(before, after)
+	if err != nil {
+		t.Fatal("sequences could not be compared: " + err.Error())
+	}
+
+	picture, _ := d.ChangeList(ChangeListOptions{VideoTracks: []int{1}})
+	if got := changeListEvents(picture); got != "replace 50 50 +0 Z" {
+		t.Errorf("picture events do not match expectations:\n%s", got)
+	}
+
+	sound, _ := d.ChangeList(ChangeListOptions{AudioTracks: []int{1}})
+	if got := changeListEvents(sound); got != "delete 50 5 -5 B\ninsert 95 5 +0 B" || sound.Difference() != 0 {
+		t.Errorf("sound events do not match expectations:\n%s", got)
+	}
+
+	after.Rate = &Rate{TimeBase: 24}
+	if d, err := DiffSequences(before, after); err != nil {
+		t.Fatal(err)
+	} else if _, err := d.ChangeList(ChangeListOptions{}); err == nil {
+		t.Error("versions at different rates not reported")
+	}
+}
+
+func TestMakingChangeListsOfMovedClips(t *testing.T) {
+	before := diffSequence("Cut 1", []*ClipItem{
+		diffItem("A", 0, 50, 0, 50),
+		diffItem("B", 50, 100, 0, 50),
+		diffItem("C", 100, 150, 0, 50),
+		diffItem("D", 150, 200, 0, 50),
+	})
+	after := diffSequence("Cut 2", []*ClipItem{
+		diffItem("A", 0, 50, 0, 50),
+		diffItem("C", 50, 100, 0, 50),
+		diffItem("B", 100, 150, 0, 50),
+		diffItem("X", 150, 160, 0, 10),
+		diffItem("D", 160, 210, 0, 50),
+	})
+	after.TimeCode = &TimeCode{Rate: &Rate{TimeBase: 25}, TimeCodeString: "01:00:00:00", DisplayFormat: timeCodeNonDropFrame}
+	before.TimeCode = after.TimeCode
+
+	d, err := DiffSequences(before, after)
+	if err != nil {
+		t.Fatal("sequences could not be compared: " + err.Error())
+	}
+
+	cl, err := d.ChangeList(ChangeListOptions{})
+	if err != nil {
+		t.Fatal("change list could not be made: " + err.Error())
+	}
+
+	if got := changeListEvents(cl); got != "move 50 50 +0 C\ninsert 150 10 +10 X" {
+		t.Errorf("events do not match expectations:\n%s", got)
+	}
+
+	if cl.Events[0].From != 100 || cl.Moved() != 50 || cl.Inserted() != 10 || cl.Deleted() != 0 {
+		t.Error("move does not match expectations")
+	}
+
+	var b bytes.Buffer
+	if err := cl.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(b.String(), "001  MOVE    01:00:02:00      50 frames  offset +0  from 01:00:04:00\n* CLIP NAME: C\n") ||
+		!strings.Contains(b.String(), "MOVED: 50 frames\n") {
+		t.Errorf("move not written:\n%s", b.String())
+	}
+}
+
+func TestWritingChangeLists(t *testing.T) {
+	before := diffSequence("Cut 1", []*ClipItem{diffItem("A", 0, 50, 0, 50), diffItem("B", 50, 100, 0, 50)})
+	after := diffSequence("Cut 2", []*ClipItem{diffItem("A", 0, 50, 0, 50), diffItem("X", 50, 75, 0, 25), diffItem("B", 75, 125, 0, 50)})
+	after.TimeCode = &TimeCode{Rate: &Rate{TimeBase: 25}, TimeCodeString: "01:00:00:00", DisplayFormat: timeCodeNonDropFrame}
+
+	d, _ := DiffSequences(before, after)
+	cl, _ := d.ChangeList(ChangeListOptions{})
+
+	var b bytes.Buffer
+	if err := cl.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `CHANGE LIST: Cut 1 -> Cut 2
+TRACKS: V1
+
+001  INSERT  01:00:02:00      25 frames  offset +25
+* CLIP NAME: X
+
+EVENTS: 1
+INSERTED: 25 frames
+DELETED: 0 frames
+DURATION: 00:00:04:00 -> 00:00:05:00 (+25 frames)
+`
+	if b.String() != want {
+		t.Errorf("change list does not match expectations:\n%s", b.String())
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+
+	converter "github.com/codygibbs/fcp-converter"
+)
+
+var targets = map[string]converter.Application{
+	"":         converter.AnyApplication,
+	"fcp7":     converter.FinalCutPro7,
+	"premiere": converter.PremierePro,
+	"resolve":  converter.DaVinciResolve,
+}
+
+func runConvert(args []string, e *env) int {
+	fs := e.newFlagSet("convert", "--to edl|fcpxml|otio|xmeml [flags] [file]")
+	var in input
+	in.flags(fs)
+	to := fs.String("to", "", "format to convert to: edl, fcpxml, otio or xmeml")
+	output := fs.String("output", "-", "file to write, - for standard output")
+	sequence := fs.String("sequence", "", "name of the sequence to convert to EDL or FCPXML, the first sequence by default")
+	target := fs.String("target", "", "application to write XEML for: fcp7, premiere or resolve")
+	indent := fs.String("indent", "\t", "indent of XEML elements")
+	title := fs.String("title", "", "title of an EDL, the name of the sequence by default")
+	version := fs.String("fcpxml-version", "", "FCPXML version, 1.9 by default")
+
+	path, code, ok := parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+
+	app, ok := targets[*target]
+	if !ok {
+		return e.fail(exitUsage, "unknown target %q", *target)
+	}
+
+	var write func(w io.Writer, x converter.RawXEML) error
+	switch *to {
+	case "edl":
+		write = func(w io.Writer, x converter.RawXEML) error {
+			s, err := findSequence(x, *sequence)
+			if err != nil {
+				return err
+			}
+			return converter.WriteEDL(w, s, converter.EDLOptions{Title: *title})
+		}
+	case "fcpxml":
+		write = func(w io.Writer, x converter.RawXEML) error {
+			s, err := findSequence(x, *sequence)
+			if err != nil {
+				return err
+			}
+			return converter.WriteFCPXML(w, s, converter.FCPXMLOptions{Version: *version})
+		}
+	case "otio":
+		write = converter.WriteOTIO
+	case "xmeml", "xml":
+		write = func(w io.Writer, x converter.RawXEML) error {
+			return converter.WriteXEML(w, x, converter.XEMLOptions{Indent: *indent, Target: app})
+		}
+	case "":
+		fs.Usage()
+		return exitUsage
+	default:
+		return e.fail(exitUsage, "unknown output format %q", *to)
+	}
+
+	x, err := in.read(path, e)
+	if err != nil {
+		return e.fail(exitFailure, "%s: %v", path, err)
+	}
+
+	if err := writeOutput(*output, e, func(w io.Writer) error { return write(w, x) }); err != nil {
+		return e.fail(exitFailure, "%s: %v", *output, err)
+	}
+
+	return exitOK
+}
+
+// writeOutput writes to a file, or to standard output when the path is -. Output is only written once it is
+// complete, so that a failed conversion leaves no partial file behind.
+func writeOutput(path string, e *env, fn func(w io.Writer) error) error {
+	var b bytes.Buffer
+	if err := fn(&b); err != nil {
+		return err
+	}
+
+	if path == "-" {
+		_, err := b.WriteTo(e.stdout)
+		return err
+	}
+
+	return ioutil.WriteFile(path, b.Bytes(), 0644)
+}
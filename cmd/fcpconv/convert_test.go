@@ -0,0 +1,96 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	converter "github.com/codygibbs/fcp-converter"
+)
+
+func TestConvertingExports(t *testing.T) {
+	for to, want := range map[string]string{
+		"edl":    "TITLE: NAMI",
+		"fcpxml": "<fcpxml",
+		"otio":   `"OTIO_SCHEMA"`,
+		"xmeml":  "<!DOCTYPE xmeml>",
+	} {
+		code, stdout, stderr := runCLI("", "convert", "--to", to, premiereExport)
+		if code != exitOK {
+			t.Errorf("%s: conversion failed: %s", to, stderr)
+			continue
+		}
+		if !strings.Contains(stdout, want) {
+			t.Errorf("%s: output does not match expectations", to)
+		}
+	}
+}
+
+func TestConvertingFromStandardInput(t *testing.T) {
+	b, err := ioutil.ReadFile(premiereExport)
+	if err != nil {
+		t.Fatal("example export could not be read: " + err.Error())
+	}
+
+	code, stdout, stderr := runCLI(string(b), "convert", "--to", "xmeml", "--target", "resolve", "-")
+	if code != exitOK {
+		t.Fatal("conversion failed: " + stderr)
+	}
+	if !strings.Contains(stdout, "<ntsc>true</ntsc>") {
+		t.Error("document not written for the target")
+	}
+
+	code, otio, _ := runCLI(string(b), "convert", "--to", "otio")
+	if code != exitOK {
+		t.Fatal("conversion to OTIO failed")
+	}
+
+	code, back, stderr := runCLI(otio, "convert", "--from", "otio", "--to", "xmeml")
+	if code != exitOK {
+		t.Fatal("conversion from OTIO failed: " + stderr)
+	}
+	if _, err := converter.ParseRawXEML([]byte(back)); err != nil {
+		t.Error("converted document could not be read: " + err.Error())
+	}
+}
+
+func TestConvertingToAFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fcpconv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	out := filepath.Join(dir, "cut.edl")
+	if code, stdout, _ := runCLI("", "convert", "--to", "edl", "--output", out, premiereExport); code != exitOK || stdout != "" {
+		t.Fatal("conversion to a file failed")
+	}
+
+	code, stdout, stderr := runCLI("", "inspect", "--from", "edl", "--rate", "23.976", out)
+	if code != exitOK || !strings.Contains(stdout, "at 23.976 fps") {
+		t.Error("converted EDL could not be read: " + stderr)
+	}
+
+	missing := filepath.Join(dir, "missing.edl")
+	if code, _, _ := runCLI("", "convert", "--to", "edl", "--sequence", "Other", "--output", missing, premiereExport); code != exitFailure {
+		t.Error("missing sequence not reported")
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Error("failed conversion left a file behind")
+	}
+}
+
+func TestConvertingWithInvalidFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"convert", premiereExport},
+		{"convert", "--to", "avid", premiereExport},
+		{"convert", "--to", "xmeml", "--target", "avid", premiereExport},
+		{"convert", "--frobnicate", premiereExport},
+	} {
+		if code, _, _ := runCLI("", args...); code != exitUsage {
+			t.Errorf("%v: usage error not reported", args)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	converter "github.com/codygibbs/fcp-converter"
+)
+
+// summary describes the contents of a document.
+type summary struct {
+	Clips     []*clipSummary     `json:"clips"`
+	Sequences []*sequenceSummary `json:"sequences"`
+	Files     int                `json:"files"`
+}
+
+type clipSummary struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	Bins       string `json:"bins,omitempty"`
+	Rate       string `json:"rate,omitempty"`
+	Duration   int    `json:"duration"`
+	MasterClip bool   `json:"masterClip"`
+}
+
+type sequenceSummary struct {
+	Name     string          `json:"name"`
+	Path     string          `json:"path"`
+	Bins     string          `json:"bins,omitempty"`
+	Rate     string          `json:"rate,omitempty"`
+	Duration int             `json:"duration"`
+	Tracks   []*trackSummary `json:"tracks"`
+}
+
+type trackSummary struct {
+	Media       string `json:"media"`
+	Index       int    `json:"index"`
+	Enabled     bool   `json:"enabled"`
+	ClipItems   int    `json:"clipItems"`
+	Transitions int    `json:"transitions"`
+	Generators  int    `json:"generators"`
+	// Nested counts the clip items nesting a sequence.
+	Nested int `json:"nested"`
+}
+
+func runInspect(args []string, e *env) int {
+	fs := e.newFlagSet("inspect", "[--json] [flags] [file]")
+	var in input
+	in.flags(fs)
+	asJSON := fs.Bool("json", false, "write the summary as JSON")
+
+	path, code, ok := parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+
+	x, err := in.read(path, e)
+	if err != nil {
+		return e.fail(exitFailure, "%s: %v", path, err)
+	}
+
+	s := summarize(x)
+	write := func(w io.Writer) error { return s.write(w) }
+	if *asJSON {
+		write = func(w io.Writer) error { return writeJSON(w, s) }
+	}
+
+	if err := writeOutput("-", e, write); err != nil {
+		return e.fail(exitFailure, "%v", err)
+	}
+
+	return exitOK
+}
+
+func summarize(x converter.RawXEML) *summary {
+	s := &summary{
+		Clips:     []*clipSummary{},
+		Sequences: []*sequenceSummary{},
+		Files:     len(x.MediaRegistry().Files),
+	}
+
+	_ = x.Walk(func(item converter.BinItem) error {
+		if c := item.Clip; c != nil {
+			s.Clips = append(s.Clips, &clipSummary{
+				Name:       string(c.Name),
+				Path:       item.Path,
+				Bins:       item.BinPath(),
+				Rate:       formatRate(c.Rate),
+				Duration:   int(c.Duration),
+				MasterClip: bool(c.IsMasterClip),
+			})
+		}
+
+		if seq := item.Sequence; seq != nil {
+			ss := &sequenceSummary{
+				Name:     string(seq.Name),
+				Path:     item.Path,
+				Bins:     item.BinPath(),
+				Rate:     formatRate(seq.Rate),
+				Duration: int(seq.Duration),
+				Tracks:   []*trackSummary{},
+			}
+			if seq.Media != nil && seq.Media.Video != nil {
+				ss.Tracks = append(ss.Tracks, summarizeTracks("video", seq.Media.Video.Tracks)...)
+			}
+			if seq.Media != nil && seq.Media.Audio != nil {
+				ss.Tracks = append(ss.Tracks, summarizeTracks("audio", seq.Media.Audio.Tracks)...)
+			}
+			s.Sequences = append(s.Sequences, ss)
+		}
+
+		return nil
+	})
+
+	return s
+}
+
+func summarizeTracks(media string, ts []*converter.Track) []*trackSummary {
+	var summaries []*trackSummary
+	for i, t := range ts {
+		ts := &trackSummary{
+			Media:       media,
+			Index:       i + 1,
+			Enabled:     bool(t.Enabled),
+			ClipItems:   len(t.ClipItems),
+			Transitions: len(t.TransitionItems),
+			Generators:  len(t.GeneratorItems),
+		}
+		for _, ci := range t.ClipItems {
+			if ci.Sequence != nil {
+				ts.Nested++
+			}
+		}
+		summaries = append(summaries, ts)
+	}
+
+	return summaries
+}
+
+// write writes a summary as an indented tree.
+func (s *summary) write(w io.Writer) error {
+	ew := &errWriter{w: w}
+	for _, c := range s.Clips {
+		kind := "clip"
+		if c.MasterClip {
+			kind = "master clip"
+		}
+		ew.printf("%s %q%s, %s\n", kind, c.Name, bins(c.Bins), length(c.Duration, c.Rate))
+	}
+
+	for _, seq := range s.Sequences {
+		ew.printf("sequence %q%s, %s\n", seq.Name, bins(seq.Bins), length(seq.Duration, seq.Rate))
+		for _, t := range seq.Tracks {
+			ew.printf("  %s %d: %s, %s, %s", t.Media, t.Index,
+				count(t.ClipItems, "clip item"), count(t.Transitions, "transition"), count(t.Generators, "generator"))
+			if t.Nested > 0 {
+				ew.printf(", %s", count(t.Nested, "nested sequence"))
+			}
+			if !t.Enabled {
+				ew.printf(", disabled")
+			}
+			ew.printf("\n")
+		}
+	}
+
+	ew.printf("%s\n", count(s.Files, "file"))
+
+	return ew.err
+}
+
+func bins(path string) string {
+	if path == "" {
+		return ""
+	}
+
+	return " in " + path
+}
+
+func length(frames int, rate string) string {
+	if rate == "" {
+		return count(frames, "frame")
+	}
+
+	return fmt.Sprintf("%s at %s fps", count(frames, "frame"), rate)
+}
+
+func count(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// errWriter keeps the first error of a series of writes.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, a ...interface{}) {
+	if ew.err == nil {
+		_, ew.err = fmt.Fprintf(ew.w, format, a...)
+	}
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+
+	return e.Encode(v)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestInspectingExports(t *testing.T) {
+	code, stdout, _ := runCLI("", "inspect", premiereExport)
+	if code != exitOK {
+		t.Fatal("inspection failed")
+	}
+
+	for _, want := range []string{`sequence "NAMI", 3839 frames at 23.976 fps`, "  video 1: 1 clip item, 0 transitions", "  audio 2:", "1 file"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("summary does not contain %q", want)
+		}
+	}
+
+	code, stdout, _ = runCLI("", "inspect", "--json", premiereExport)
+	if code != exitOK {
+		t.Fatal("inspection failed")
+	}
+
+	var s summary
+	if err := json.Unmarshal([]byte(stdout), &s); err != nil {
+		t.Fatal("summary is not JSON: " + err.Error())
+	}
+
+	if len(s.Sequences) != 1 || s.Sequences[0].Rate != "23.976" || len(s.Sequences[0].Tracks) != 3 || s.Files != 1 {
+		t.Error("summary does not match expectations")
+	}
+
+	if tr := s.Sequences[0].Tracks[1]; tr.Media != "audio" || tr.Index != 1 || tr.ClipItems != 1 {
+		t.Error("track summary does not match expectations")
+	}
+}
+
+func TestInspectingBins(t *testing.T) {
+	doc := `<xmeml version="5"><bin><name>Dailies</name><children>
+		<clip id="a"><name>A001</name><duration>100</duration><rate><timebase>25</timebase></rate><ismasterclip>TRUE</ismasterclip></clip>
+	</children></bin></xmeml>`
+
+	code, stdout, _ := runCLI(doc, "inspect")
+	if code != exitOK || !strings.Contains(stdout, `master clip "A001" in Dailies, 100 frames at 25 fps`) {
+		t.Errorf("clip summary does not match expectations: %s", stdout)
+	}
+}
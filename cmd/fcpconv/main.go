@@ -0,0 +1,234 @@
+// Command fcpconv converts, inspects and validates Final Cut Pro XML documents.
+//
+// Usage:
+//
+//	fcpconv convert --to edl|fcpxml|otio|xmeml [flags] [file]
+//	fcpconv inspect [--json] [flags] [file]
+//	fcpconv validate [--json] [flags] [file]
+//
+// Documents are read from a file, or from standard input when the file is - or missing, and written to standard
+// output unless --output is set. Documents are read as XEML unless --from is set or the file name ends in .edl,
+// .fcpxml or .otio.
+//
+// fcpconv exits with 0 on success, 1 when validation finds warnings, 2 when it finds errors, 3 when a document cannot
+// be read, converted or written, and 4 on usage errors.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	converter "github.com/codygibbs/fcp-converter"
+)
+
+const (
+	exitOK      = 0
+	exitWarning = 1
+	exitError   = 2
+	exitFailure = 3
+	exitUsage   = 4
+)
+
+const usage = `usage: fcpconv <command> [flags] [file]
+
+commands:
+  convert   convert a document to EDL, FCPXML, OTIO or XEML
+  inspect   summarise the sequences, tracks and clips of a document
+  validate  check a document against the validation rules
+
+Run fcpconv <command> -h for the flags of a command.
+`
+
+// command runs a subcommand with its arguments, returning the exit code.
+type command func(args []string, env *env) int
+
+var commands = map[string]command{
+	"convert":  runConvert,
+	"inspect":  runInspect,
+	"validate": runValidate,
+}
+
+// env describes the streams a command reads and writes.
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], &env{os.Stdin, os.Stdout, os.Stderr}))
+}
+
+func run(args []string, e *env) int {
+	if len(args) == 0 {
+		fmt.Fprint(e.stderr, usage)
+		return exitUsage
+	}
+
+	if args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(e.stdout, usage)
+		return exitOK
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(e.stderr, "fcpconv: unknown command %q\n\n%s", args[0], usage)
+		return exitUsage
+	}
+
+	return cmd(args[1:], e)
+}
+
+// fail reports an error of a command, returning the exit code.
+func (e *env) fail(code int, format string, a ...interface{}) int {
+	fmt.Fprintf(e.stderr, "fcpconv: "+format+"\n", a...)
+	return code
+}
+
+// newFlagSet creates the flags of a command, reporting usage errors to the error stream.
+func (e *env) newFlagSet(name string, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(e.stderr, "usage: fcpconv %s %s\n\nflags:\n", name, args)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+// parseFlags parses the flags of a command, returning its single optional file argument, or an exit code when
+// the arguments are not valid.
+func parseFlags(fs *flag.FlagSet, args []string) (string, int, bool) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return "", exitOK, false
+		}
+		return "", exitUsage, false
+	}
+
+	switch fs.NArg() {
+	case 0:
+		return "-", 0, true
+	case 1:
+		return fs.Arg(0), 0, true
+	}
+
+	fs.Usage()
+
+	return "", exitUsage, false
+}
+
+// input describes how a document is read.
+type input struct {
+	from string
+	rate string
+}
+
+func (in *input) flags(fs *flag.FlagSet) {
+	fs.StringVar(&in.from, "from", "", "format of the document: xmeml, edl, fcpxml or otio, guessed from the file name by default")
+	fs.StringVar(&in.rate, "rate", "", "frame rate of an EDL, e.g. 25, 23.976 or 29.97")
+}
+
+// read reads a document from a file, or from standard input when the path is -.
+func (in *input) read(path string, e *env) (converter.RawXEML, error) {
+	from := in.from
+	if from == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".edl":
+			from = "edl"
+		case ".fcpxml":
+			from = "fcpxml"
+		case ".otio":
+			from = "otio"
+		default:
+			from = "xmeml"
+		}
+	}
+
+	r := e.stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return converter.RawXEML{}, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	switch from {
+	case "xmeml", "xml":
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return converter.RawXEML{}, err
+		}
+		return converter.ParseRawXEML(b)
+	case "edl":
+		if in.rate == "" {
+			return converter.RawXEML{}, errors.New("reading an EDL needs its frame rate, see --rate")
+		}
+		rate, err := parseRate(in.rate)
+		if err != nil {
+			return converter.RawXEML{}, err
+		}
+		return converter.ParseEDL(r, rate)
+	case "fcpxml":
+		return converter.ParseFCPXML(r)
+	case "otio":
+		return converter.ParseOTIO(r)
+	}
+
+	return converter.RawXEML{}, fmt.Errorf("unknown input format %q", from)
+}
+
+// parseRate parses a frame rate such as 25, 23.976 or 29.97, taking rates that are not whole numbers as NTSC rates.
+func parseRate(s string) (converter.Rate, error) {
+	fps, err := strconv.ParseFloat(s, 64)
+	if err != nil || fps <= 0 {
+		return converter.Rate{}, fmt.Errorf("frame rate %q is not valid", s)
+	}
+
+	timeBase := math.Round(fps)
+	r := converter.Rate{TimeBase: int(timeBase)}
+	if fps != timeBase {
+		r.NTSC = true
+	}
+
+	return r, nil
+}
+
+// formatRate formats a frame rate such as 25 or 23.976.
+func formatRate(r *converter.Rate) string {
+	if r == nil || r.TimeBase <= 0 {
+		return ""
+	}
+
+	return strconv.FormatFloat(math.Round(r.FPS()*1000)/1000, 'f', -1, 64)
+}
+
+// findSequence finds a sequence of a document by name, or its top level or first sequence when the name is empty.
+func findSequence(x converter.RawXEML, name string) (*converter.Sequence, error) {
+	if name == "" && x.Sequence != nil {
+		return x.Sequence, nil
+	}
+
+	for _, item := range x.Sequences() {
+		if name == "" || string(item.Sequence.Name) == name {
+			return item.Sequence, nil
+		}
+	}
+
+	if name == "" {
+		return nil, errors.New("document has no sequence")
+	}
+
+	return nil, fmt.Errorf("document has no sequence named %q", name)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const premiereExport = "../../export-examples/premier-export.xml"
+
+// runCLI runs fcpconv with arguments and standard input, returning its exit code and output.
+func runCLI(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, &env{strings.NewReader(stdin), &stdout, &stderr})
+
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRunningCommands(t *testing.T) {
+	if code, _, stderr := runCLI(""); code != exitUsage || !strings.Contains(stderr, "usage") {
+		t.Error("missing command not reported")
+	}
+
+	if code, _, stderr := runCLI("", "frobnicate"); code != exitUsage || !strings.Contains(stderr, "frobnicate") {
+		t.Error("unknown command not reported")
+	}
+
+	if code, stdout, _ := runCLI("", "help"); code != exitOK || !strings.Contains(stdout, "convert") {
+		t.Error("help not written")
+	}
+
+	if code, _, _ := runCLI("", "inspect", "-h"); code != exitOK {
+		t.Error("help of a command not successful")
+	}
+
+	if code, _, _ := runCLI("", "inspect", "a.xml", "b.xml"); code != exitUsage {
+		t.Error("more than one file not reported")
+	}
+
+	if code, _, stderr := runCLI("", "inspect", "missing.xml"); code != exitFailure || stderr == "" {
+		t.Error("missing file not reported")
+	}
+
+	if code, _, _ := runCLI("<xmeml><sequence>", "inspect"); code != exitFailure {
+		t.Error("malformed document not reported")
+	}
+
+	if code, _, stderr := runCLI("", "inspect", "--from", "edl"); code != exitFailure || !strings.Contains(stderr, "--rate") {
+		t.Error("EDL without a rate not reported")
+	}
+}
+
+func TestParsingRates(t *testing.T) {
+	for s, want := range map[string]string{"25": "25", "23.976": "23.976", "29.97": "29.97", "60": "60"} {
+		r, err := parseRate(s)
+		if err != nil {
+			t.Errorf("rate %s not parsed: %v", s, err)
+			continue
+		}
+		if got := formatRate(&r); got != want {
+			t.Errorf("rate %s formatted as %s", s, got)
+		}
+	}
+
+	if _, err := parseRate("fast"); err == nil {
+		t.Error("invalid rate not reported")
+	}
+}
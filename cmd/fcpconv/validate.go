@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	converter "github.com/codygibbs/fcp-converter"
+)
+
+// finding describes a validation finding as JSON.
+type finding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Path     string `json:"path"`
+	Message  string `json:"message"`
+}
+
+// severities maps severity names to severities.
+var severities = map[string]converter.Severity{
+	converter.SeverityInfo.String():    converter.SeverityInfo,
+	converter.SeverityWarning.String(): converter.SeverityWarning,
+	converter.SeverityError.String():   converter.SeverityError,
+}
+
+// ruleSeverities collects severity overrides such as track-overlap=warning.
+type ruleSeverities map[string]converter.Severity
+
+func (rs ruleSeverities) String() string {
+	var s []string
+	for name, sev := range rs {
+		s = append(s, name+"="+sev.String())
+	}
+
+	return strings.Join(s, ",")
+}
+
+func (rs ruleSeverities) Set(v string) error {
+	for _, o := range strings.Split(v, ",") {
+		parts := strings.SplitN(o, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("%q is not a rule=severity pair", o)
+		}
+
+		sev, ok := severities[parts[1]]
+		if !ok {
+			return fmt.Errorf("unknown severity %q", parts[1])
+		}
+		rs[parts[0]] = sev
+	}
+
+	return nil
+}
+
+func runValidate(args []string, e *env) int {
+	fs := e.newFlagSet("validate", "[--json] [flags] [file]")
+	var in input
+	in.flags(fs)
+	asJSON := fs.Bool("json", false, "write findings as JSON")
+	disable := fs.String("disable", "", "comma separated rules not to run")
+	overrides := ruleSeverities{}
+	fs.Var(overrides, "severity", "comma separated rule=severity overrides, e.g. track-overlap=warning")
+	rules := fs.Bool("rules", false, "list the validation rules and exit")
+
+	path, code, ok := parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+
+	if *rules {
+		if err := writeOutput("-", e, writeRules); err != nil {
+			return e.fail(exitFailure, "%v", err)
+		}
+		return exitOK
+	}
+
+	o := converter.ValidationOptions{Severities: overrides}
+	if *disable != "" {
+		o.Disabled = strings.Split(*disable, ",")
+	}
+
+	x, err := in.read(path, e)
+	if err != nil {
+		return e.fail(exitFailure, "%s: %v", path, err)
+	}
+
+	findings := converter.Validate(x, o)
+	write := func(w io.Writer) error {
+		ew := &errWriter{w: w}
+		for _, f := range findings {
+			ew.printf("%s\n", f)
+		}
+		return ew.err
+	}
+	if *asJSON {
+		write = func(w io.Writer) error {
+			out := []*finding{}
+			for _, f := range findings {
+				out = append(out, &finding{f.Rule, f.Severity.String(), f.Path, f.Message})
+			}
+			return writeJSON(w, out)
+		}
+	}
+
+	if err := writeOutput("-", e, write); err != nil {
+		return e.fail(exitFailure, "%v", err)
+	}
+
+	switch findings.Max() {
+	case converter.SeverityError:
+		return exitError
+	case converter.SeverityWarning:
+		return exitWarning
+	}
+
+	return exitOK
+}
+
+func writeRules(w io.Writer) error {
+	ew := &errWriter{w: w}
+	for _, r := range converter.ValidationRules() {
+		ew.printf("%-22s %-8s %s\n", r.Name, r.Severity, r.Description)
+	}
+
+	return ew.err
+}
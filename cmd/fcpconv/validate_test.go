@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const overlappingXML = `<xmeml version="5"><sequence><name>Cut</name><duration>50</duration><rate><timebase>25</timebase></rate>
+	<media><video><track>
+		<clipitem id="a"><name>A</name><start>0</start><end>50</end><in>0</in><out>50</out></clipitem>
+		<clipitem id="b"><name>B</name><start>25</start><end>50</end><in>0</in><out>25</out></clipitem>
+	</track></video></media>
+</sequence></xmeml>`
+
+func TestValidatingDocuments(t *testing.T) {
+	if code, stdout, _ := runCLI("", "validate", premiereExport); code != exitOK || stdout != "" {
+		t.Error("valid document reported")
+	}
+
+	code, stdout, _ := runCLI(overlappingXML, "validate")
+	if code != exitError || !strings.Contains(stdout, "track-overlap") {
+		t.Errorf("overlapping clip items not reported: %d %s", code, stdout)
+	}
+
+	if code, _, _ := runCLI(overlappingXML, "validate", "--severity", "track-overlap=warning"); code != exitWarning {
+		t.Error("severity override not applied")
+	}
+
+	if code, _, _ := runCLI(overlappingXML, "validate", "--disable", "track-overlap,marker-range"); code != exitOK {
+		t.Error("rule not disabled")
+	}
+
+	if code, _, _ := runCLI(overlappingXML, "validate", "--severity", "track-overlap=fatal"); code != exitUsage {
+		t.Error("unknown severity not reported")
+	}
+
+	code, stdout, _ = runCLI(overlappingXML, "validate", "--json")
+	var findings []*finding
+	if err := json.Unmarshal([]byte(stdout), &findings); err != nil {
+		t.Fatal("findings are not JSON: " + err.Error())
+	}
+	if code != exitError || len(findings) != 1 || findings[0].Rule != "track-overlap" || findings[0].Severity != "error" {
+		t.Error("findings do not match expectations")
+	}
+
+	if code, stdout, _ := runCLI("", "validate", "--rules"); code != exitOK || !strings.Contains(stdout, "track-overlap") {
+		t.Error("rules not listed")
+	}
+}
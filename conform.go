@@ -0,0 +1,275 @@
+package converter
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// ConformMode selects how Conform moves a sequence to another rate.
+type ConformMode int
+
+const (
+	// ConformFrameForFrame keeps every frame count and plays the frames at the new rate, changing the speed and
+	// running time of the sequence, e.g. the PAL speed up of a 23.976 timeline played at 25 fps.
+	ConformFrameForFrame ConformMode = iota
+	// ConformRealTime keeps the running time, converting every record frame count to the nearest frame at the new
+	// rate. Media keeps its native rate, so source ranges are left as they are.
+	ConformRealTime
+)
+
+func (m ConformMode) String() string {
+	switch m {
+	case ConformFrameForFrame:
+		return "frame for frame"
+	case ConformRealTime:
+		return "real time"
+	}
+
+	return "unknown"
+}
+
+// ConformChange describes a clip item whose source range no longer matches its record range once conformed, and
+// was moved to match.
+type ConformChange struct {
+	// Path is the element path of the clip item, e.g. xmeml/sequence/media/video/track[2]/clipitem[14]
+	Path string
+	Item *ClipItem
+	// Frames is the number of source frames the clip item gained, or lost when it is negative, at its out point.
+	Frames int
+}
+
+// Conform returns a copy of a sequence moved to another rate, along with the clip items that gain or lose frames.
+//
+// Frame for frame, every rate of the sequence that is the same as its rate, including the rates of clip items, files,
+// timecodes and nested sequences, is replaced by the new rate and every frame count is kept. Items at other rates
+// keep their rate.
+//
+// In real time, only the record side of the sequence moves to the new rate: its rate and format, timecode, duration,
+// markers, and the record ranges of its items and the keyframes of its transitions, which are converted. Clip items
+// and generator items keep their rate, source ranges, durations, markers, filters, timecodes, files and nested
+// sequences, and the rate they inherited from the sequence is written on them.
+//
+// Timecodes that can no longer be drop frame become non drop frame. Rounding, and clip items at other rates, can
+// leave the source range of a clip item longer or shorter than its record range. Its out point is then moved to
+// match, and the frames it gains or loses are reported. Clip items with a speed change are left as they are.
+func (s *Sequence) Conform(to Rate, mode ConformMode) (*Sequence, []*ConformChange, error) {
+	if s.Rate == nil || s.Rate.TimeBase <= 0 {
+		return nil, nil, errors.New("sequence has no rate")
+	}
+	if to.TimeBase <= 0 {
+		return nil, nil, errors.New("rate has no time base")
+	}
+
+	conformed := &Sequence{}
+	if err := copyElement(conformed, s); err != nil {
+		return nil, nil, err
+	}
+
+	c := &conformer{from: *s.Rate, to: to, mode: mode}
+	c.sequence(conformed, c.from, "sequence")
+	c.rates(conformed)
+	c.match(conformed, to, "sequence")
+
+	return conformed, c.changes, nil
+}
+
+type conformer struct {
+	from    Rate
+	to      Rate
+	mode    ConformMode
+	changes []*ConformChange
+}
+
+// frames converts a frame count at a rate, leaving counts at other rates and the -1 of unset values as they are.
+func (c *conformer) frames(n int, r Rate) int {
+	if c.mode != ConformRealTime || n < 0 || !r.Equal(c.from) {
+		return n
+	}
+
+	return ConvertFrames(n, c.from, c.to)
+}
+
+// rateOf returns a rate, or the rate it inherits when it is unset.
+func rateOf(r *Rate, inherited Rate) Rate {
+	if r != nil && r.TimeBase > 0 {
+		return *r
+	}
+
+	return inherited
+}
+
+func (c *conformer) sequence(s *Sequence, inherited Rate, path string) {
+	r := rateOf(s.Rate, inherited)
+
+	s.Duration = duration(c.frames(int(s.Duration), r))
+	s.In = in(c.frames(int(s.In), r))
+	s.Out = out(c.frames(int(s.Out), r))
+	c.markers(s.Markers, r)
+	c.timeCode(s.TimeCode, r)
+
+	if s.Media == nil {
+		return
+	}
+
+	mediaPath := childPath(path, "media", 0)
+	if v := s.Media.Video; v != nil {
+		v.Duration = duration(c.frames(int(v.Duration), r))
+		v.In, v.Out = in(c.frames(int(v.In), r)), out(c.frames(int(v.Out), r))
+		c.tracks(v.Tracks, r, childPath(mediaPath, "video", 0))
+	}
+	if a := s.Media.Audio; a != nil {
+		c.tracks(a.Tracks, r, childPath(mediaPath, "audio", 0))
+	}
+}
+
+func (c *conformer) tracks(ts []*Track, r Rate, path string) {
+	for i, t := range ts {
+		trackPath := childPath(path, "track", i)
+		for j, ci := range t.ClipItems {
+			c.clipItem(ci, r, childPath(trackPath, "clipitem", j))
+		}
+
+		for _, g := range t.GeneratorItems {
+			g.Start, g.End = start(c.frames(int(g.Start), r)), end(c.frames(int(g.End), r))
+			g.Rate = c.sourceRate(g.Rate, r)
+		}
+
+		for _, ti := range t.TransitionItems {
+			ti.Start, ti.End = start(c.frames(int(ti.Start), r)), end(c.frames(int(ti.End), r))
+			c.effect(ti.Effect, r)
+		}
+	}
+}
+
+// clipItem converts the record range of a clip item. Frame for frame, the timecodes of its source are written again
+// at the new rate, its frame counts being kept.
+func (c *conformer) clipItem(ci *ClipItem, r Rate, path string) {
+	ci.Start, ci.End = start(c.frames(int(ci.Start), r)), end(c.frames(int(ci.End), r))
+	if c.mode == ConformRealTime {
+		ci.Rate = c.sourceRate(ci.Rate, r)
+		return
+	}
+
+	cr := rateOf(ci.Rate, r)
+	c.timeCode(ci.TimeCode, cr)
+	if f := ci.File; f != nil {
+		fr := rateOf(f.Rate, cr)
+		f.Duration = duration(c.frames(int(f.Duration), fr))
+		c.timeCode(f.TimeCode, fr)
+	}
+
+	if ci.Sequence != nil {
+		c.sequence(ci.Sequence, cr, childPath(path, "sequence", 0))
+	}
+}
+
+func (c *conformer) markers(ms []*Marker, r Rate) {
+	for _, m := range ms {
+		m.In, m.Out = in(c.frames(int(m.In), r)), out(c.frames(int(m.Out), r))
+	}
+}
+
+func (c *conformer) effect(e *Effect, r Rate) {
+	if e == nil {
+		return
+	}
+
+	for _, p := range e.Parameters {
+		for _, k := range p.KeyFrames {
+			k.When = when(c.frames(int(k.When), r))
+		}
+	}
+}
+
+// sourceRate returns the rate of the source of an item, which is the rate it inherits when it is unset and media keeps
+// its native rate.
+func (c *conformer) sourceRate(r *Rate, inherited Rate) *Rate {
+	if c.mode != ConformRealTime || (r != nil && r.TimeBase > 0) {
+		return r
+	}
+
+	return &Rate{TimeBase: inherited.TimeBase, NTSC: inherited.NTSC}
+}
+
+// timeCode converts the frames of a timecode, which are written again at the new rate once rates are replaced.
+func (c *conformer) timeCode(tc *TimeCode, inherited Rate) {
+	if tc == nil {
+		return
+	}
+
+	r := rateOf(tc.Rate, inherited)
+	if !r.Equal(c.from) {
+		return
+	}
+
+	n, err := tc.Frames()
+	if err != nil {
+		n = int(tc.Frame)
+	}
+	n = c.frames(n, r)
+	if tc.DropFrame() && !c.to.SupportsDropFrame() {
+		tc.DisplayFormat = timeCodeNonDropFrame
+	}
+	if tc.Rate == nil {
+		tc.Rate = &Rate{}
+	}
+	*tc.Rate = c.to
+	tc.SetFrames(n)
+}
+
+// rates replaces every rate that is the same as the rate of the sequence. In real time, the rates of the items of
+// its tracks and what they hold are the rates of media, and are kept.
+func (c *conformer) rates(s *Sequence) {
+	walkElements(reflect.ValueOf(s).Elem(), "sequence", func(e reflect.Value, path string) {
+		if e.Kind() != reflect.Struct || !e.CanAddr() {
+			return
+		}
+		if c.mode == ConformRealTime && strings.Contains(path, "/track") {
+			return
+		}
+
+		if r, ok := e.Addr().Interface().(*Rate); ok && r.Equal(c.from) {
+			r.TimeBase, r.NTSC = c.to.TimeBase, c.to.NTSC
+		}
+	})
+}
+
+// match moves the out point of clip items whose source range no longer plays for as long as their record range.
+func (c *conformer) match(s *Sequence, inherited Rate, path string) {
+	r := rateOf(s.Rate, inherited)
+
+	v := &validator{}
+	v.tracks(s, path, func(t *Track, media string, trackPath string) {
+		for i, ci := range t.ClipItems {
+			if ci.Sequence != nil && c.mode != ConformRealTime {
+				c.match(ci.Sequence, rateOf(ci.Rate, r), childPath(childPath(trackPath, "clipitem", i), "sequence", 0))
+			}
+		}
+
+		edits, _ := editTrack(t)
+		for _, e := range edits {
+			ci := e.item
+			if e.start < 0 || e.end < 0 || int(ci.Out) <= int(ci.In) || hasTimeRemap(ci.Filters) {
+				continue
+			}
+
+			want := ConvertFrames(e.end-e.start, r, rateOf(ci.Rate, r))
+			if gained := want - (int(ci.Out) - int(ci.In)); gained != 0 {
+				ci.Out = out(int(ci.In) + want)
+				c.changes = append(c.changes, &ConformChange{Path: itemPath(t, trackPath, ci), Item: ci, Frames: gained})
+			}
+		}
+	})
+}
+
+// itemPath returns the path of a clip item of a track.
+func itemPath(t *Track, trackPath string, ci *ClipItem) string {
+	for i, item := range t.ClipItems {
+		if item == ci {
+			return childPath(trackPath, "clipitem", i)
+		}
+	}
+
+	return trackPath
+}
@@ -0,0 +1,148 @@
+package converter
+
+import (
+	"testing"
+)
+
+func conformSequence() *Sequence {
+	film := &Rate{TimeBase: 24, NTSC: true}
+
+	return &Sequence{
+		Rate: film,
+		TimeCode: &TimeCode{
+			Rate:           &Rate{TimeBase: 24, NTSC: true},
+			TimeCodeString: "01:00:00:00",
+			Frame:          86400,
+			DisplayFormat:  timeCodeNonDropFrame,
+		},
+		Markers: []*Marker{{Name: "M", In: 48, Out: -1}},
+		Media: &Media{Video: &Video{Tracks: []*Track{{Enabled: true, ClipItems: []*ClipItem{
+			{ID: "a", Enabled: true, Start: 0, End: 48, In: 0, Out: 48, Rate: &Rate{TimeBase: 24, NTSC: true}},
+			{
+				ID: "b", Enabled: true, Start: 48, End: 100, In: 10, Out: 62,
+				Markers: []*Marker{{Name: "N", In: 12, Out: -1}},
+				Filters: []*Filter{{Effect: &Effect{Parameters: []*Parameter{{KeyFrames: []*KeyFrame{{When: 24}}}}}}},
+				File:    &File{ID: "file-1", Rate: &Rate{TimeBase: 24, NTSC: true}, Duration: 240},
+			},
+			{ID: "c", Enabled: true, Start: 100, End: 124, In: 0, Out: 30, Rate: &Rate{TimeBase: 30, NTSC: true}},
+			{ID: "d", Enabled: true, Start: 124, End: 134, In: 0, Out: 10},
+		}}}}},
+	}
+}
+
+func TestConformingFrameForFrame(t *testing.T) {
+	s := conformSequence()
+	pal := Rate{TimeBase: 25}
+
+	conformed, changes, err := s.Conform(pal, ConformFrameForFrame)
+	if err != nil {
+		t.Fatal("sequence could not be conformed: " + err.Error())
+	}
+
+	if !s.Rate.Equal(Rate{TimeBase: 24, NTSC: true}) {
+		t.Error("conforming changed the sequence")
+	}
+
+	items := conformed.Media.Video.Tracks[0].ClipItems
+	if !conformed.Rate.Equal(pal) || !items[0].Rate.Equal(pal) || !items[1].File.Rate.Equal(pal) {
+		t.Error("rates not replaced")
+	}
+
+	if c := items[2]; !c.Rate.Equal(Rate{TimeBase: 30, NTSC: true}) {
+		t.Error("rate of a clip item at another rate replaced")
+	}
+
+	if b := items[1]; b.Start != 48 || b.End != 100 || b.In != 10 || b.Out != 62 || b.Markers[0].In != 12 {
+		t.Errorf("frames not kept: %d %d %d %d", b.Start, b.End, b.In, b.Out)
+	}
+
+	if tc := conformed.TimeCode; tc.Frame != 86400 || tc.TimeCodeString != "00:57:36:00" || !tc.Rate.Equal(pal) {
+		t.Errorf("timecode not kept frame for frame: %d %s", tc.Frame, tc.TimeCodeString)
+	}
+
+	if len(changes) != 1 || changes[0].Item != items[2] || changes[0].Frames != -1 {
+		t.Fatal("clip item at another rate not reported")
+	}
+
+	if changes[0].Path != "sequence/media/video/track/clipitem[3]" || items[2].Out != 29 {
+		t.Errorf("clip item at another rate not matched: %s %d", changes[0].Path, items[2].Out)
+	}
+}
+
+func TestConformingInRealTime(t *testing.T) {
+	s := conformSequence()
+	pal := Rate{TimeBase: 25}
+
+	conformed, changes, err := s.Conform(pal, ConformRealTime)
+	if err != nil {
+		t.Fatal("sequence could not be conformed: " + err.Error())
+	}
+
+	items := conformed.Media.Video.Tracks[0].ClipItems
+	if a := items[0]; a.Start != 0 || a.End != 50 || a.In != 0 || a.Out != 48 {
+		t.Errorf("record range not converted or source range not kept: %d %d %d %d", a.Start, a.End, a.In, a.Out)
+	}
+
+	film := Rate{TimeBase: 24, NTSC: true}
+	b := items[1]
+	if b.Start != 50 || b.End != 104 || b.In != 10 || b.Out != 62 || !b.Rate.Equal(film) {
+		t.Errorf("record range not converted or source range not kept: %d %d %d %d", b.Start, b.End, b.In, b.Out)
+	}
+
+	if b.Markers[0].In != 12 || b.Markers[0].Out != -1 || conformed.Markers[0].In != 50 {
+		t.Error("sequence markers not converted or clip item markers not kept")
+	}
+
+	if k := b.Filters[0].Effect.Parameters[0].KeyFrames[0]; k.When != 24 {
+		t.Errorf("filter keyframes not kept: %d", k.When)
+	}
+
+	if b.File.Duration != 240 || !b.File.Rate.Equal(film) {
+		t.Errorf("file not kept at its native rate: %d", b.File.Duration)
+	}
+
+	if c := items[2]; c.Start != 104 || c.End != 129 || c.In != 0 || c.Out != 30 {
+		t.Errorf("clip item at another rate not kept: %d %d %d %d", c.Start, c.End, c.In, c.Out)
+	}
+
+	if tc := conformed.TimeCode; tc.Frame != 90090 || tc.TimeCodeString != "01:00:03:15" || !tc.Rate.Equal(pal) {
+		t.Errorf("timecode not converted: %d %s", tc.Frame, tc.TimeCodeString)
+	}
+
+	if !conformed.Rate.Equal(pal) {
+		t.Error("sequence rate not replaced")
+	}
+
+	d := items[3]
+	if len(changes) != 1 || changes[0].Item != d || changes[0].Frames != 1 {
+		t.Fatal("clip item whose record range no longer matches its source range not reported")
+	}
+
+	if d.Start != 129 || d.End != 140 || d.In != 0 || d.Out != 11 || !d.Rate.Equal(film) {
+		t.Errorf("clip item not matched: %d %d %d %d", d.Start, d.End, d.In, d.Out)
+	}
+}
+
+func TestConformingDropFrameTimeCodes(t *testing.T) {
+	s := &Sequence{
+		Rate: &Rate{TimeBase: 30, NTSC: true},
+		TimeCode: &TimeCode{
+			Rate:           &Rate{TimeBase: 30, NTSC: true},
+			TimeCodeString: "01:00:00;00",
+			DisplayFormat:  timeCodeDropFrame,
+		},
+	}
+
+	conformed, _, err := s.Conform(Rate{TimeBase: 25}, ConformRealTime)
+	if err != nil {
+		t.Fatal("sequence could not be conformed: " + err.Error())
+	}
+
+	if tc := conformed.TimeCode; tc.DropFrame() || tc.TimeCodeString != "01:00:00:00" {
+		t.Errorf("drop frame timecode not made non drop frame: %s", tc.TimeCodeString)
+	}
+
+	if _, _, err := (&Sequence{}).Conform(Rate{TimeBase: 25}, ConformRealTime); err == nil {
+		t.Error("sequence without a rate not reported")
+	}
+}
@@ -0,0 +1,551 @@
+package converter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ImportErrorKind classifies a problem found while importing XML.
+type ImportErrorKind int
+
+const (
+	// ImportSyntax describes malformed or truncated XML.
+	ImportSyntax ImportErrorKind = iota
+	// ImportTypeMismatch describes a value that cannot be converted to the type of its element.
+	ImportTypeMismatch
+	// ImportUnknownEnum describes a value that is not part of the enumeration of its element.
+	ImportUnknownEnum
+)
+
+func (k ImportErrorKind) String() string {
+	switch k {
+	case ImportSyntax:
+		return "syntax"
+	case ImportTypeMismatch:
+		return "type mismatch"
+	case ImportUnknownEnum:
+		return "unknown enum"
+	}
+
+	return "unknown"
+}
+
+// ImportError describes a problem found while importing XML, along with where it was found.
+type ImportError struct {
+	Kind   ImportErrorKind
+	Line   int
+	Column int
+	// Path is the element path of the problem, e.g. xmeml/sequence/media/video/track[2]/clipitem[14]
+	Path  string
+	Value string
+	Err   error
+}
+
+func (e *ImportError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("%d:%d: %s: %v", e.Line, e.Column, e.Kind, e.Err)
+	}
+
+	return fmt.Sprintf("%d:%d: %s: %s: %v", e.Line, e.Column, e.Path, e.Kind, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ImportError) Unwrap() error {
+	return e.Err
+}
+
+// ParseRawXEML imports XML into a raw XEML data tree, returning the first problem found as an *ImportError.
+func ParseRawXEML(s []byte) (RawXEML, error) {
+	x, _, err := decodeRawXEML(bytes.NewReader(s), false)
+
+	return x, err
+}
+
+// ParseRawXEMLLenient imports XML into a raw XEML data tree, collecting recoverable problems as warnings
+// rather than aborting. Values that cannot be converted are left unset.
+func ParseRawXEMLLenient(s []byte) (RawXEML, []*ImportError, error) {
+	return decodeRawXEML(bytes.NewReader(s), true)
+}
+
+func decodeRawXEML(r io.Reader, lenient bool) (RawXEML, []*ImportError, error) {
+	var x RawXEML
+
+	t := newImportTracker(r, reflect.TypeOf(x), lenient)
+	err := xml.NewTokenDecoder(t).Decode(&x)
+	if err != nil {
+		return x, t.warnings, t.wrap(err)
+	}
+
+	return x, t.warnings, nil
+}
+
+// positionReader counts lines and columns of the bytes read by an XML decoder. It implements io.ByteReader so
+// that the decoder does not buffer ahead of the position being reported.
+type positionReader struct {
+	r      io.ByteReader
+	line   int
+	column int
+}
+
+func newPositionReader(r io.Reader) *positionReader {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+
+	return &positionReader{r: br, line: 1}
+}
+
+func (p *positionReader) ReadByte() (byte, error) {
+	b, err := p.r.ReadByte()
+	if err != nil {
+		return b, err
+	}
+
+	if b == '\n' {
+		p.line++
+		p.column = 0
+	} else {
+		p.column++
+	}
+
+	return b, nil
+}
+
+func (p *positionReader) Read(b []byte) (int, error) {
+	for i := range b {
+		c, err := p.ReadByte()
+		if err != nil {
+			return i, err
+		}
+		b[i] = c
+	}
+
+	return len(b), nil
+}
+
+// importFrame describes an open element while importing.
+type importFrame struct {
+	name     string
+	path     string
+	t        reflect.Type // nil when the element is not part of the model
+	line     int
+	column   int
+	children map[string]int
+	leaf     bool
+	data     []xml.Token
+}
+
+// importTracker sits between the XML lexer and the unmarshaller. It follows the element path and the model type
+// of every element so that problems can be reported with their location, and validates leaf values before they
+// are unmarshalled so that lenient imports can carry on past them.
+type importTracker struct {
+	p        *positionReader
+	d        *xml.Decoder
+	stack    []*importFrame
+	root     reflect.Type
+	lenient  bool
+	warnings []*ImportError
+	queue    []xml.Token
+	last     string
+	children map[string]int
+	// line and column of the token being handled
+	line   int
+	column int
+	// set after character data, which the lexer ends by reading ahead a byte
+	readAhead bool
+}
+
+func newImportTracker(r io.Reader, root reflect.Type, lenient bool) *importTracker {
+	p := newPositionReader(r)
+
+	return &importTracker{
+		p:        p,
+		d:        xml.NewDecoder(p),
+		root:     root,
+		lenient:  lenient,
+		children: map[string]int{},
+	}
+}
+
+// Token implements xml.TokenReader.
+func (t *importTracker) Token() (xml.Token, error) {
+	for len(t.queue) == 0 {
+		t.line, t.column = t.p.line, t.p.column+1
+		if t.readAhead {
+			t.column--
+		}
+
+		tok, err := t.d.RawToken()
+		if err != nil {
+			if err == io.EOF && len(t.stack) > 0 {
+				err = errors.New("unexpected EOF")
+			}
+			if err == io.EOF {
+				return nil, err
+			}
+
+			return nil, t.syntaxError(err)
+		}
+
+		_, t.readAhead = tok.(xml.CharData)
+		if err := t.handle(xml.CopyToken(tok)); err != nil {
+			return nil, err
+		}
+	}
+
+	tok := t.queue[0]
+	t.queue = t.queue[1:]
+
+	return tok, nil
+}
+
+func (t *importTracker) handle(tok xml.Token) error {
+	top := t.top()
+
+	switch tt := tok.(type) {
+	case xml.StartElement:
+		if top != nil && top.leaf {
+			// a simple value should not have children, stop checking it
+			t.queue = append(t.queue, top.data...)
+			top.data = nil
+			top.leaf = false
+		}
+
+		f := t.push(tt)
+		if err := t.checkAttrs(f, &tt); err != nil {
+			return err
+		}
+		t.queue = append(t.queue, tt)
+
+	case xml.EndElement:
+		if top == nil || top.name != tt.Name.Local {
+			return t.syntaxError(fmt.Errorf("unexpected end element </%s>", tt.Name.Local))
+		}
+
+		if top.leaf {
+			data, err := t.checkValue(top)
+			if err != nil {
+				return err
+			}
+			t.queue = append(t.queue, data...)
+		}
+
+		t.last = top.path
+		t.stack = t.stack[:len(t.stack)-1]
+		t.queue = append(t.queue, tt)
+
+	default:
+		if top != nil && top.leaf {
+			top.data = append(top.data, tok)
+			return nil
+		}
+		t.queue = append(t.queue, tok)
+	}
+
+	return nil
+}
+
+func (t *importTracker) top() *importFrame {
+	if len(t.stack) == 0 {
+		return nil
+	}
+
+	return t.stack[len(t.stack)-1]
+}
+
+func (t *importTracker) push(e xml.StartElement) *importFrame {
+	name := e.Name.Local
+	parent := t.top()
+
+	siblings := t.children
+	prefix := ""
+	var pt reflect.Type
+	if parent == nil {
+		pt = t.root
+	} else {
+		siblings = parent.children
+		prefix = parent.path + "/"
+		pt = parent.t
+	}
+
+	siblings[name]++
+	path := prefix + name
+	if n := siblings[name]; n > 1 {
+		path = fmt.Sprintf("%s[%d]", path, n)
+	}
+
+	var ft reflect.Type
+	if parent == nil {
+		ft = t.root
+	} else if pt != nil {
+		ft = fieldType(pt, name)
+	}
+
+	f := &importFrame{
+		name:     name,
+		path:     path,
+		t:        ft,
+		line:     t.line,
+		column:   t.column,
+		children: map[string]int{},
+		leaf:     ft != nil && isLeafType(ft),
+	}
+	t.stack = append(t.stack, f)
+
+	return f
+}
+
+func (t *importTracker) checkAttrs(f *importFrame, e *xml.StartElement) error {
+	if f.t == nil || f.t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i, a := range e.Attr {
+		at := attrType(f.t, a.Name.Local)
+		if at == nil {
+			continue
+		}
+
+		kind, err := checkLeafValue(at, a.Value)
+		if err == nil {
+			continue
+		}
+
+		ie := &ImportError{
+			Kind:   kind,
+			Line:   f.line,
+			Column: f.column,
+			Path:   f.path + "@" + a.Name.Local,
+			Value:  a.Value,
+			Err:    err,
+		}
+		if !t.lenient {
+			return ie
+		}
+
+		t.warnings = append(t.warnings, ie)
+		e.Attr[i].Value = ""
+	}
+
+	return nil
+}
+
+func (t *importTracker) checkValue(f *importFrame) ([]xml.Token, error) {
+	var value strings.Builder
+	for _, tok := range f.data {
+		if cd, ok := tok.(xml.CharData); ok {
+			value.Write(cd)
+		}
+	}
+
+	kind, err := checkLeafValue(f.t, value.String())
+	if err == nil {
+		return f.data, nil
+	}
+
+	ie := &ImportError{
+		Kind:   kind,
+		Line:   f.line,
+		Column: f.column,
+		Path:   f.path,
+		Value:  value.String(),
+		Err:    err,
+	}
+	if !t.lenient {
+		return nil, ie
+	}
+
+	t.warnings = append(t.warnings, ie)
+
+	return nil, nil
+}
+
+func (t *importTracker) syntaxError(err error) *ImportError {
+	if se, ok := err.(*xml.SyntaxError); ok {
+		err = errors.New(se.Msg)
+	}
+
+	path := ""
+	if f := t.top(); f != nil {
+		path = f.path
+	}
+
+	return &ImportError{
+		Kind:   ImportSyntax,
+		Line:   t.p.line,
+		Column: t.p.column,
+		Path:   path,
+		Err:    err,
+	}
+}
+
+// wrap converts errors returned by the unmarshaller into an *ImportError.
+func (t *importTracker) wrap(err error) error {
+	var ie *ImportError
+	if errors.As(err, &ie) {
+		return ie
+	}
+
+	if err == io.EOF {
+		return t.syntaxError(errors.New("empty document"))
+	}
+
+	if _, ok := err.(*xml.SyntaxError); ok {
+		return t.syntaxError(err)
+	}
+
+	path := t.last
+	if f := t.top(); f != nil {
+		path = f.path
+	}
+
+	return &ImportError{
+		Kind:   ImportTypeMismatch,
+		Line:   t.p.line,
+		Column: t.p.column,
+		Path:   path,
+		Err:    err,
+	}
+}
+
+// enum is implemented by string types that are restricted to a set of values.
+type enum interface {
+	enumValues() []string
+}
+
+// openEnum is implemented by enumerations that applications extend with values of their own, e.g. the composite
+// modes of Premiere Pro. Values that are not listed are kept as they are read, and reported by validation.
+type openEnum interface {
+	enum
+	openEnum()
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	xmlUnmarshalerType  = reflect.TypeOf((*xml.Unmarshaler)(nil)).Elem()
+)
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr || (t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8) {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+func isLeafType(t reflect.Type) bool {
+	if reflect.PtrTo(t).Implements(xmlUnmarshalerType) {
+		return false
+	}
+
+	return t.Kind() != reflect.Struct || reflect.PtrTo(t).Implements(textUnmarshalerType)
+}
+
+// fieldType finds the model type of a child element of a struct.
+func fieldType(t reflect.Type, name string) reflect.Type {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("xml")
+		if tag == "-" {
+			continue
+		}
+
+		tagName, opts := tag, ""
+		if i := strings.Index(tag, ","); i >= 0 {
+			tagName, opts = tag[:i], tag[i:]
+		}
+		if strings.Contains(opts, ",attr") || strings.Contains(opts, ",chardata") ||
+			strings.Contains(opts, ",innerxml") || strings.Contains(opts, ",any") || strings.Contains(opts, ",comment") {
+			continue
+		}
+
+		if tagName == "" && f.Anonymous && indirectType(f.Type).Kind() == reflect.Struct {
+			if ft := fieldType(indirectType(f.Type), name); ft != nil {
+				return ft
+			}
+			continue
+		}
+
+		if tagName == "" {
+			tagName = f.Name
+		}
+		if f.Name == "XMLName" {
+			continue
+		}
+
+		if tagName == name {
+			return indirectType(f.Type)
+		}
+	}
+
+	return nil
+}
+
+// attrType finds the model type of an attribute of a struct.
+func attrType(t reflect.Type, name string) reflect.Type {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("xml")
+		if !strings.Contains(tag, ",attr") || strings.Contains(tag, ",any") {
+			continue
+		}
+
+		tagName := tag[:strings.Index(tag, ",")]
+		if tagName == "" {
+			tagName = f.Name
+		}
+		if tagName == name {
+			return indirectType(f.Type)
+		}
+	}
+
+	return nil
+}
+
+// checkLeafValue checks that s can be unmarshalled into a value of type t the same way encoding/xml would.
+func checkLeafValue(t reflect.Type, s string) (ImportErrorKind, error) {
+	v := reflect.New(t)
+	if e, ok := v.Elem().Interface().(enum); ok {
+		if _, open := e.(openEnum); open {
+			return ImportUnknownEnum, nil
+		}
+		_, err := parseEnum(s, e.enumValues())
+		return ImportUnknownEnum, err
+	}
+
+	if u, ok := v.Interface().(encoding.TextUnmarshaler); ok {
+		return ImportTypeMismatch, u.UnmarshalText([]byte(s))
+	}
+
+	s = strings.TrimSpace(s)
+	if s == "" && t.Kind() != reflect.String {
+		return ImportTypeMismatch, nil
+	}
+
+	var err error
+	switch t.Kind() {
+	case reflect.Bool:
+		_, err = strconv.ParseBool(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, err = strconv.ParseInt(s, 10, t.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		_, err = strconv.ParseUint(s, 10, t.Bits())
+	case reflect.Float32, reflect.Float64:
+		_, err = strconv.ParseFloat(s, t.Bits())
+	}
+
+	return ImportTypeMismatch, err
+}
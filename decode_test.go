@@ -0,0 +1,138 @@
+package converter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParsingTruncatedXML(t *testing.T) {
+	xc := `<xmeml version="1">
+	<sequence>
+		<name>Truncated</name>`
+
+	_, err := ParseRawXEML([]byte(xc))
+
+	var ie *ImportError
+	if !errors.As(err, &ie) {
+		t.Fatal("truncated XML did not return an import error")
+	}
+
+	if ie.Kind != ImportSyntax {
+		t.Error("truncated XML not classified as a syntax error: " + ie.Kind.String())
+	}
+
+	if ie.Line != 3 {
+		t.Errorf("syntax error line does not match expectations: %d", ie.Line)
+	}
+}
+
+func TestParsingTypeMismatch(t *testing.T) {
+	xc := `<xmeml version="1">
+	<sequence>
+		<media>
+			<video>
+				<track></track>
+				<track>
+					<clipitem>
+						<duration>abc</duration>
+					</clipitem>
+				</track>
+			</video>
+		</media>
+	</sequence>
+</xmeml>`
+
+	_, err := ParseRawXEML([]byte(xc))
+
+	var ie *ImportError
+	if !errors.As(err, &ie) {
+		t.Fatal("type mismatch did not return an import error")
+	}
+
+	if ie.Kind != ImportTypeMismatch {
+		t.Error("bad duration not classified as a type mismatch: " + ie.Kind.String())
+	}
+
+	if ie.Path != "xmeml/sequence/media/video/track[2]/clipitem/duration" {
+		t.Error("type mismatch path does not match expectations: " + ie.Path)
+	}
+
+	if ie.Line != 8 || ie.Column != 7 {
+		t.Errorf("type mismatch position does not match expectations: %d:%d", ie.Line, ie.Column)
+	}
+
+	if ie.Value != "abc" {
+		t.Error("type mismatch value not recorded: " + ie.Value)
+	}
+}
+
+func TestParsingUnknownEnum(t *testing.T) {
+	xc := `<xmeml version="1"><clip><alphatype>sparkle</alphatype></clip></xmeml>`
+
+	_, err := ParseRawXEML([]byte(xc))
+
+	var ie *ImportError
+	if !errors.As(err, &ie) {
+		t.Fatal("unknown enum did not return an import error")
+	}
+
+	if ie.Kind != ImportUnknownEnum {
+		t.Error("bad alpha type not classified as an unknown enum: " + ie.Kind.String())
+	}
+
+	if ie.Path != "xmeml/clip/alphatype" {
+		t.Error("unknown enum path does not match expectations: " + ie.Path)
+	}
+
+	// composite modes are extended by other applications, and kept for validation to report
+	xc = `<xmeml version="1"><clip><compositemode>sparkle</compositemode></clip></xmeml>`
+	x, err := ParseRawXEML([]byte(xc))
+	if err != nil {
+		t.Fatal("vendor enum value not imported: " + err.Error())
+	}
+
+	if x.Clip.CompositeMode != "sparkle" {
+		t.Error("vendor composite mode not kept: " + string(x.Clip.CompositeMode))
+	}
+}
+
+func TestParsingLeniently(t *testing.T) {
+	xc := `<xmeml version="x">
+	<clip>
+		<name>Lenient</name>
+		<duration>1.5</duration>
+		<enabled>maybe</enabled>
+		<compositemode>sparkle</compositemode>
+		<in>4</in>
+	</clip>
+</xmeml>`
+
+	x, warnings, err := ParseRawXEMLLenient([]byte(xc))
+	if err != nil {
+		t.Fatal("lenient import failed: " + err.Error())
+	}
+
+	if len(warnings) != 3 {
+		t.Fatalf("lenient import warnings do not match expectations: %v", warnings)
+	}
+
+	if warnings[0].Path != "xmeml@version" {
+		t.Error("attribute warning path does not match expectations: " + warnings[0].Path)
+	}
+
+	if x.Clip.Name != "Lenient" || x.Clip.In != 4 {
+		t.Error("valid values not imported alongside warnings")
+	}
+
+	if x.Clip.Duration != 0 {
+		t.Error("invalid duration not left unset")
+	}
+
+	if x.Clip.Enabled {
+		t.Error("invalid enabled value not left unset")
+	}
+
+	if x.Clip.CompositeMode != "sparkle" {
+		t.Error("unknown composite mode not kept")
+	}
+}
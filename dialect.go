@@ -0,0 +1,339 @@
+package converter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// Application selects the application a document is written for. Applications differ in the version they declare,
+// how they write ids, booleans and file references, and which vendor extensions they keep.
+type Application int
+
+const (
+	// AnyApplication writes a document as it is.
+	AnyApplication Application = iota
+	// FinalCutPro7 writes version 5 documents with TRUE and FALSE, ids like clipitem-1 and file-1, files defined at
+	// their first use and referred to by id afterwards, and no vendor extensions.
+	FinalCutPro7
+	// PremierePro writes version 4 documents with TRUE and FALSE, ids like clipitem-1 and file-1, files defined at
+	// their first use and referred to by id afterwards, and all vendor extensions.
+	PremierePro
+	// DaVinciResolve writes version 5 documents with true and false, ids made of a clip name and a number like
+	// "A001.mov 3", files and nested sequences defined at every use, and no vendor attributes or Premiere Pro
+	// elements.
+	DaVinciResolve
+)
+
+func (a Application) String() string {
+	switch a {
+	case AnyApplication:
+		return "any"
+	case FinalCutPro7:
+		return "Final Cut Pro 7"
+	case PremierePro:
+		return "Premiere Pro"
+	case DaVinciResolve:
+		return "DaVinci Resolve"
+	}
+
+	return "unknown"
+}
+
+// retarget returns a copy of a document rewritten for an application.
+func retarget(x RawXEML, app Application) (RawXEML, error) {
+	// copied through XML so that the document passed in is left as it is
+	b, err := xml.Marshal(x)
+	if err != nil {
+		return x, err
+	}
+	y, err := ParseRawXEML(b)
+	if err != nil {
+		return x, err
+	}
+
+	y.Version = 5
+	if app == PremierePro {
+		y.Version = 4
+	}
+
+	r := &retargeter{app: app, counts: map[string]int{}}
+	if err := r.sequences(&y); err != nil {
+		return x, err
+	}
+	r.files(&y)
+	r.ids(&y)
+	r.extensions(&y)
+
+	return y, nil
+}
+
+type retargeter struct {
+	app    Application
+	counts map[string]int
+}
+
+// id makes a new id for an element of a kind, e.g. clipitem-3, or "A001.mov 3" for DaVinci Resolve.
+func (r *retargeter) id(kind string, name string) string {
+	if r.app == DaVinciResolve {
+		// one count is shared by every kind, so that ids stay unique when names are shared
+		n := r.counts[""]
+		r.counts[""]++
+		return fmt.Sprintf("%s %d", name, n)
+	}
+
+	r.counts[kind]++
+
+	return fmt.Sprintf("%s-%d", kind, r.counts[kind])
+}
+
+// sequences defines nested sequences at every use for DaVinci Resolve, which does not read references to sequences by
+// id, by replacing the references with copies of their definitions.
+func (r *retargeter) sequences(x *RawXEML) error {
+	if r.app != DaVinciResolve {
+		return nil
+	}
+
+	defs := map[string]*Sequence{}
+	x.walkSequences(func(s *Sequence) {
+		if _, ok := defs[s.ID]; s.ID != "" && s.Media != nil && !ok {
+			defs[s.ID] = s
+		}
+	})
+
+	active := map[string]bool{}
+	var expand func(s *Sequence) error
+	expand = func(s *Sequence) error {
+		if s.Media == nil {
+			return nil
+		}
+
+		for _, t := range s.Media.tracks() {
+			for _, ci := range t.ClipItems {
+				ns := ci.Sequence
+				if ns == nil {
+					continue
+				}
+				if active[ns.ID] && ns.ID != "" {
+					return fmt.Errorf("nested sequence %q of clip item %q nests itself", ns.ID, ci.ID)
+				}
+				if def, ok := defs[ns.ID]; ok && ns.Media == nil {
+					if err := copyElement(ns, def); err != nil {
+						return err
+					}
+				}
+
+				active[ns.ID] = true
+				err := expand(ns)
+				delete(active, ns.ID)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+
+	return x.Walk(func(item BinItem) error {
+		if s := item.Sequence; s != nil {
+			active[s.ID] = true
+			defer delete(active, s.ID)
+			return expand(s)
+		}
+
+		return nil
+	})
+}
+
+// files rewrites file ids, and writes files either at their first use with references afterwards or at every use.
+func (r *retargeter) files(x *RawXEML) {
+	reg := x.MediaRegistry()
+
+	type use struct {
+		f   *File
+		def File
+	}
+	var uses []use
+	x.walkFiles(func(f *File, path string) {
+		def := reg.Canonical(f)
+		if def == nil {
+			def = f
+		}
+		uses = append(uses, use{f, *def})
+	})
+
+	ids := map[string]string{}
+	for _, u := range uses {
+		oldID := u.def.ID
+		id, seen := ids[oldID]
+		if !seen || oldID == "" {
+			id = r.id("file", fileName(&u.def))
+			ids[oldID] = id
+		}
+
+		if seen && oldID != "" && r.app != DaVinciResolve {
+			*u.f = File{ID: id}
+			continue
+		}
+
+		*u.f = u.def
+		u.f.ID = id
+	}
+}
+
+// fileName names a file by its name, or by the last element of its URL.
+func fileName(f *File) string {
+	if f.Name != "" {
+		return string(f.Name)
+	}
+
+	if f.PathURL != "" {
+		return path.Base(string(f.PathURL))
+	}
+
+	return "file"
+}
+
+// ids rewrites the ids of sequences, master clips and clip items, and the references to them. A nested sequence and
+// the references to it by id share the new id of the sequence.
+func (r *retargeter) ids(x *RawXEML) {
+	clips := map[string]string{}
+	clipID := func(old string) string {
+		if old == "" || r.app == DaVinciResolve {
+			return old
+		}
+		if _, ok := clips[old]; !ok {
+			clips[old] = r.id("masterclip", "")
+		}
+
+		return clips[old]
+	}
+
+	_ = x.Walk(func(item BinItem) error {
+		if c := item.Clip; c != nil {
+			c.ID = clipID(c.ID)
+			c.MasterClipID = masterClipID(clipID(string(c.MasterClipID)))
+		}
+
+		return nil
+	})
+
+	sequences := map[string]string{}
+	sequenceID := func(old string) string {
+		if r.app == DaVinciResolve {
+			return ""
+		}
+		if old == "" {
+			return r.id("sequence", "")
+		}
+		if _, ok := sequences[old]; !ok {
+			sequences[old] = r.id("sequence", "")
+		}
+
+		return sequences[old]
+	}
+
+	x.walkSequences(func(s *Sequence) {
+		s.ID = sequenceID(s.ID)
+		if s.Media == nil {
+			return
+		}
+
+		// links point at the clip items of their own sequence, which for DaVinci Resolve may be one of several copies
+		items := map[string]string{}
+		var links []*Link
+		for _, t := range s.Media.tracks() {
+			for _, ci := range t.ClipItems {
+				id := r.id("clipitem", string(ci.Name))
+				if _, ok := items[ci.ID]; !ok && ci.ID != "" {
+					items[ci.ID] = id
+				}
+				ci.ID = id
+				ci.MasterClipID = masterClipID(clipID(string(ci.MasterClipID)))
+				links = append(links, ci.Links...)
+			}
+		}
+
+		for _, l := range links {
+			if id, ok := items[string(l.LinkClipRef)]; ok {
+				l.LinkClipRef = linkClipRef(id)
+			}
+		}
+	})
+}
+
+// extensions removes the vendor extensions an application does not read. Final Cut Pro 7 keeps the unknown elements
+// and attributes that are not Premiere Pro extensions, as some of them, e.g. samplecharacteristics/depth, are part of
+// its format that the raw model does not know.
+func (r *retargeter) extensions(x *RawXEML) {
+	if r.app == PremierePro {
+		return
+	}
+
+	walkElements(reflect.ValueOf(x).Elem(), "xmeml", func(e reflect.Value, path string) {
+		if e.Kind() != reflect.Struct || !e.CanAddr() {
+			return
+		}
+
+		ext, ok := e.Addr().Interface().(interface{ extensions() *Extensions })
+		if !ok {
+			return
+		}
+
+		ex := ext.extensions()
+		if r.app == FinalCutPro7 {
+			var attrs []xml.Attr
+			for _, a := range ex.UnknownAttrs {
+				if !isPremiereAttr(a.Name.Local) {
+					attrs = append(attrs, a)
+				}
+			}
+			ex.UnknownAttrs = attrs
+		} else {
+			ex.UnknownAttrs = nil
+		}
+
+		var kept []*UnknownElement
+		for _, u := range ex.UnknownElements {
+			if !isPremiereElement(u.XMLName.Local, r.app) {
+				kept = append(kept, u)
+			}
+		}
+		ex.UnknownElements = kept
+	})
+}
+
+// premiereAttrPrefixes lists the prefixes of the attributes Premiere Pro writes for its own use, e.g.
+// MZ.Sequence.EditingModeGUID or TL.SQTrackShy.
+var premiereAttrPrefixes = []string{"MZ.", "TL.", "AM.", "Monitor.", "Panner", "premiere"}
+
+// premiereAttrs lists the other attributes Premiere Pro writes for its own use.
+var premiereAttrs = map[string]bool{
+	"explodedTracks":            true,
+	"currentExplodedTrackIndex": true,
+	"totalExplodedTrackCount":   true,
+}
+
+// isPremiereAttr reports whether an attribute is a Premiere Pro extension.
+func isPremiereAttr(local string) bool {
+	for _, p := range premiereAttrPrefixes {
+		if strings.HasPrefix(local, p) {
+			return true
+		}
+	}
+
+	return premiereAttrs[local]
+}
+
+// isPremiereElement reports whether an element is a Premiere Pro extension an application does not read: the ppro
+// elements, e.g. pproTicksIn, and for Final Cut Pro 7 also the colour information of clip items.
+func isPremiereElement(local string, app Application) bool {
+	if strings.HasPrefix(local, "ppro") {
+		return true
+	}
+
+	return app == FinalCutPro7 && local == "colorinfo"
+}
@@ -0,0 +1,150 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeForTarget(t *testing.T, path string, app Application) (RawXEML, string) {
+	s, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal("example export could not be read: " + err.Error())
+	}
+
+	x := ImportRawXEML(s)
+	before, _ := xml.Marshal(x)
+
+	var b bytes.Buffer
+	if err := WriteXEML(&b, x, XEMLOptions{Indent: "\t", Target: app}); err != nil {
+		t.Fatalf("raw XEML could not be written for %s: %s", app, err.Error())
+	}
+
+	if after, _ := xml.Marshal(x); !bytes.Equal(before, after) {
+		t.Errorf("raw XEML changed when written for %s", app)
+	}
+
+	y, err := ParseRawXEML(b.Bytes())
+	if err != nil {
+		t.Fatalf("raw XEML written for %s could not be imported: %s", app, err.Error())
+	}
+
+	if findings := Validate(y, ValidationOptions{}); findings.Max() >= SeverityWarning {
+		t.Errorf("raw XEML written for %s is not valid: %s", app, findings[0])
+	}
+
+	return y, b.String()
+}
+
+func TestWritingForPremierePro(t *testing.T) {
+	y, s := writeForTarget(t, "export-examples/resolve-export.xml", PremierePro)
+
+	if y.Version != 4 || !strings.Contains(s, "<ntsc>TRUE</ntsc>") {
+		t.Error("version and booleans not written for Premiere Pro")
+	}
+
+	video := y.Sequence.Media.Video.Tracks[0].ClipItems[0]
+	audio := y.Sequence.Media.Audio.Tracks[0].ClipItems[0]
+	if video.ID != "clipitem-1" || audio.ID != "clipitem-2" || y.Sequence.ID != "sequence-1" {
+		t.Errorf("ids not written for Premiere Pro: %s %s %s", video.ID, audio.ID, y.Sequence.ID)
+	}
+
+	if video.Links[1].LinkClipRef != "clipitem-2" || y.Sequence.LinkGroup(video).Audio[0] != audio {
+		t.Error("links not pointed at the ids written for Premiere Pro")
+	}
+
+	if video.File.ID != "file-1" || !video.File.isDefinition() || audio.File.ID != "file-1" || audio.File.isDefinition() {
+		t.Error("file not defined at its first use and referred to afterwards")
+	}
+}
+
+func TestWritingForResolve(t *testing.T) {
+	y, s := writeForTarget(t, "export-examples/premier-export.xml", DaVinciResolve)
+
+	if y.Version != 5 || !strings.Contains(s, "<ntsc>true</ntsc>") || strings.Contains(s, "TRUE") {
+		t.Error("version and booleans not written for DaVinci Resolve")
+	}
+
+	if strings.Contains(s, "MZ.") || strings.Contains(s, "TL.SQ") || strings.Contains(s, "pproTicksIn") {
+		t.Error("Premiere Pro extensions written for DaVinci Resolve")
+	}
+
+	if !strings.Contains(s, "<numOutputChannels>") {
+		t.Error("unknown elements not kept for DaVinci Resolve")
+	}
+
+	video := y.Sequence.Media.Video.Tracks[0].ClipItems[0]
+	if video.ID != "NAMI.mp4 1" || y.Sequence.ID != "" {
+		t.Errorf("ids not written for DaVinci Resolve: %s", video.ID)
+	}
+
+	for _, ci := range []*ClipItem{video, y.Sequence.Media.Audio.Tracks[1].ClipItems[0]} {
+		if ci.File.ID != "NAMI.mp4 0" || !ci.File.isDefinition() {
+			t.Error("file not defined at every use")
+		}
+	}
+}
+
+func TestWritingForFinalCutPro7(t *testing.T) {
+	y, s := writeForTarget(t, "export-examples/premier-export.xml", FinalCutPro7)
+
+	if y.Version != 5 || !strings.Contains(s, "<enabled>TRUE</enabled>") {
+		t.Error("version and booleans not written for Final Cut Pro 7")
+	}
+
+	if strings.Contains(s, "MZ.") || strings.Contains(s, "TL.SQ") || strings.Contains(s, "pproTicksIn") ||
+		strings.Contains(s, "<colorinfo>") {
+		t.Error("vendor extensions written for Final Cut Pro 7")
+	}
+
+	for _, el := range []string{"<depth>", "<samplerate>", "<pixelaspectratio>", "<outputchannelindex>", "<numOutputChannels>"} {
+		if !strings.Contains(s, el) {
+			t.Error("Final Cut Pro 7 element not written for Final Cut Pro 7: " + el)
+		}
+	}
+
+	x := ImportRawXEML([]byte(s))
+	if !reflect.DeepEqual(x.Sequence.Media.Video.Tracks[0].ClipItems[0].File, y.Sequence.Media.Video.Tracks[0].ClipItems[0].File) {
+		t.Error("file not kept for Final Cut Pro 7")
+	}
+}
+
+func TestWritingSequencesNestedTwice(t *testing.T) {
+	x := ImportRawXEML([]byte(nestedXML))
+	track := x.Sequence.Media.Video.Tracks[1]
+	track.ClipItems = append(track.ClipItems, &ClipItem{
+		ID: "clipitem-8", Name: "Again", Enabled: true, Start: 25, End: 75, In: 0, Out: 50,
+		Sequence: &Sequence{ID: "sequence-2"},
+	})
+
+	for _, app := range []Application{FinalCutPro7, PremierePro, DaVinciResolve} {
+		var b bytes.Buffer
+		if err := WriteXEML(&b, x, XEMLOptions{Target: app}); err != nil {
+			t.Fatalf("raw XEML could not be written for %s: %s", app, err.Error())
+		}
+		y, err := ParseRawXEML(b.Bytes())
+		if err != nil {
+			t.Fatalf("raw XEML written for %s could not be imported: %s", app, err.Error())
+		}
+
+		def := y.Sequence.Media.Video.Tracks[0].ClipItems[1].Sequence
+		again := y.Sequence.Media.Video.Tracks[1].ClipItems[1].Sequence
+		if app != DaVinciResolve {
+			if def.Media == nil || again.Media != nil || again.ID != def.ID || def.ID == y.Sequence.ID {
+				t.Errorf("reference to a nested sequence not given the id of its definition for %s: %s %s", app, def.ID, again.ID)
+			}
+			continue
+		}
+
+		if again.ID != "" || again.Media == nil || len(again.Media.Video.Tracks) != 2 {
+			t.Fatal("reference to a nested sequence not defined at its use for DaVinci Resolve")
+		}
+		first, copied := def.Media.Video.Tracks[0].ClipItems[0], again.Media.Video.Tracks[0].ClipItems[0]
+		if first.ID == copied.ID || copied.Links[1].LinkClipRef != linkClipRef(again.Media.Video.Tracks[1].ClipItems[0].ID) {
+			t.Error("clip items of a copied nested sequence not given their own ids and links")
+		}
+	}
+}
@@ -0,0 +1,670 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ChangeKind classifies a change between two versions of a sequence.
+type ChangeKind int
+
+const (
+	// ChangeInserted describes a clip item that is only part of the new version.
+	ChangeInserted ChangeKind = iota
+	// ChangeDeleted describes a clip item that is only part of the old version.
+	ChangeDeleted
+	// ChangeMoved describes a clip item that was moved to another track, or whose gap to the clip item before it
+	// changed, rather than following the edits before it.
+	ChangeMoved
+	// ChangeTrimmed describes a clip item whose source range changed.
+	ChangeTrimmed
+	// ChangeReordered describes a clip item that plays in a different order relative to the other clip items.
+	ChangeReordered
+	// ChangeFilters describes a clip item whose filters were added, removed or changed.
+	ChangeFilters
+	// ChangeMarkers describes a clip item whose markers were added, removed or changed.
+	ChangeMarkers
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeInserted:
+		return "inserted"
+	case ChangeDeleted:
+		return "deleted"
+	case ChangeMoved:
+		return "moved"
+	case ChangeTrimmed:
+		return "trimmed"
+	case ChangeReordered:
+		return "reordered"
+	case ChangeFilters:
+		return "filters"
+	case ChangeMarkers:
+		return "markers"
+	}
+
+	return "unknown"
+}
+
+// DiffEvent describes a clip item of one version of a sequence.
+type DiffEvent struct {
+	Item *ClipItem
+	// Media is video or audio.
+	Media string
+	// Track counts from 1.
+	Track int
+	// Start and End are the record range of the clip item, resolved when it starts or ends in a transition.
+	Start int
+	End   int
+	In    int
+	Out   int
+
+	// source identifies what the clip item plays, see sourceKey
+	source string
+	// rate is the rate of the source range
+	rate Rate
+}
+
+// DiffChange describes a change to a clip item between two versions of a sequence. A clip item that changed in
+// more than one way has a change of every kind.
+type DiffChange struct {
+	Kind ChangeKind
+	// Before is the clip item in the old version, nil when it was inserted.
+	Before *DiffEvent
+	// After is the clip item in the new version, nil when it was deleted.
+	After *DiffEvent
+	// Head and Tail are the source frames a trimmed clip item was extended by at its in and out points, negative
+	// when it was shortened.
+	Head int
+	Tail int
+	// Offset is the number of record frames a moved clip item moved by.
+	Offset int
+	// Details describes the change, e.g. "head +12, tail -3" or "added Gaussian Blur".
+	Details string
+}
+
+// SequenceDiff describes the changes between two versions of a sequence, in the record order of the new version.
+type SequenceDiff struct {
+	Before  *Sequence
+	After   *Sequence
+	Changes []*DiffChange
+
+	// pairs of clip items of both versions that play the same source
+	pairs []*diffPair
+	// clip items of the old version that were deleted, and of the new version that were inserted
+	deleted  []*DiffEvent
+	inserted []*DiffEvent
+}
+
+// diffPair describes a clip item of the old version matched with a clip item of the new version.
+type diffPair struct {
+	before    *DiffEvent
+	after     *DiffEvent
+	reordered bool
+}
+
+// DiffSequences compares two versions of a sequence. Clip items of both versions are matched when they play the
+// same source, identified by the path URL or name of their file, by their master clip id or by their name, with
+// overlapping source ranges on the same kind of track. The pairs of clip items with the largest overlap are matched
+// first, preferring pairs on the same track and close to each other. Clip items that are not matched are inserted
+// or deleted.
+//
+// Matched clip items that no longer play in the same order are reordered. Other matched clip items are moved when
+// they changed track, or when the gap ahead of them changed once inserted, deleted and reordered clip items are
+// taken out, so that clip items following an edit are not reported. Both versions must have a rate.
+func DiffSequences(before *Sequence, after *Sequence) (*SequenceDiff, error) {
+	if before.Rate == nil || before.Rate.TimeBase <= 0 || after.Rate == nil || after.Rate.TimeBase <= 0 {
+		return nil, errors.New("sequence has no rate")
+	}
+
+	d := &SequenceDiff{Before: before, After: after}
+	b, a := diffEvents(before), diffEvents(after)
+	d.match(b, a)
+	d.reorder()
+
+	for _, p := range d.pairs {
+		d.compare(p)
+	}
+	for _, e := range d.deleted {
+		d.Changes = append(d.Changes, &DiffChange{Kind: ChangeDeleted, Before: e})
+	}
+	for _, e := range d.inserted {
+		d.Changes = append(d.Changes, &DiffChange{Kind: ChangeInserted, After: e})
+	}
+
+	positions := map[*DiffChange]int{}
+	for _, c := range d.Changes {
+		positions[c] = d.position(c)
+	}
+	sort.SliceStable(d.Changes, func(i, j int) bool {
+		ci, cj := d.Changes[i], d.Changes[j]
+		if pi, pj := positions[ci], positions[cj]; pi != pj {
+			return pi < pj
+		}
+		if ei, ej := ci.event(), cj.event(); ei.Media != ej.Media {
+			return ei.Media == "video"
+		} else if ei.Track != ej.Track {
+			return ei.Track < ej.Track
+		}
+
+		return ci.Kind < cj.Kind
+	})
+
+	return d, nil
+}
+
+// event returns the clip item a change is about, in the new version unless it was deleted.
+func (c *DiffChange) event() *DiffEvent {
+	if c.After != nil {
+		return c.After
+	}
+
+	return c.Before
+}
+
+// diffEvents lists the clip items of a sequence, video tracks first.
+func diffEvents(s *Sequence) []*DiffEvent {
+	reg := (&RawXEML{Sequence: s}).MediaRegistry()
+
+	var events []*DiffEvent
+	v := &validator{}
+	track := map[string]int{}
+	v.tracks(s, "sequence", func(t *Track, media string, path string) {
+		track[media]++
+		edits, _ := editTrack(t)
+		for _, e := range edits {
+			ci := e.item
+			r := *s.Rate
+			if ci.Rate != nil && ci.Rate.TimeBase > 0 {
+				r = *ci.Rate
+			}
+
+			events = append(events, &DiffEvent{
+				Item:   ci,
+				Media:  media,
+				Track:  track[media],
+				Start:  e.start,
+				End:    e.end,
+				In:     int(ci.In),
+				Out:    int(ci.Out),
+				source: sourceKey(ci, reg),
+				rate:   r,
+			})
+		}
+	})
+
+	return events
+}
+
+// sourceKey identifies the source of a clip item across versions of a document, which may number ids differently.
+func sourceKey(ci *ClipItem, reg *MediaRegistry) string {
+	if ci.Sequence != nil {
+		return "sequence:" + string(ci.Sequence.Name)
+	}
+
+	if f := reg.Canonical(ci.File); f != nil {
+		// paths are compared rather than URLs, which applications write differently
+		if p, err := DecodeFileURL(string(f.PathURL)); err == nil && p != "" {
+			return "file:" + p
+		}
+		if f.Name != "" {
+			return "name:" + string(f.Name)
+		}
+	}
+
+	if ci.MasterClipID != "" {
+		return "clip:" + string(ci.MasterClipID)
+	}
+
+	return "item:" + string(ci.Name)
+}
+
+// match pairs the clip items of both versions that play the same source.
+func (d *SequenceDiff) match(before []*DiffEvent, after []*DiffEvent) {
+	type candidate struct {
+		b, a    *DiffEvent
+		overlap int
+	}
+
+	var candidates []candidate
+	for _, b := range before {
+		for _, a := range after {
+			if a.source != b.source || a.Media != b.Media {
+				continue
+			}
+
+			overlap := minInt(a.Out, b.Out) - maxInt(a.In, b.In)
+			if overlap > 0 || (a.In == b.In && a.Out == b.Out) {
+				candidates = append(candidates, candidate{b, a, overlap})
+			}
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		ci, cj := candidates[i], candidates[j]
+		if ci.overlap != cj.overlap {
+			return ci.overlap > cj.overlap
+		}
+		if si, sj := ci.a.Track == ci.b.Track, cj.a.Track == cj.b.Track; si != sj {
+			return si
+		}
+
+		return absInt(ci.a.Start-ci.b.Start) < absInt(cj.a.Start-cj.b.Start)
+	})
+
+	matched := map[*DiffEvent]bool{}
+	for _, c := range candidates {
+		if matched[c.b] || matched[c.a] {
+			continue
+		}
+
+		matched[c.b], matched[c.a] = true, true
+		d.pairs = append(d.pairs, &diffPair{before: c.b, after: c.a})
+	}
+
+	for _, b := range before {
+		if !matched[b] {
+			d.deleted = append(d.deleted, b)
+		}
+	}
+	for _, a := range after {
+		if !matched[a] {
+			d.inserted = append(d.inserted, a)
+		}
+	}
+
+	sort.SliceStable(d.pairs, func(i, j int) bool {
+		return lessEvent(d.pairs[i].after, d.pairs[j].after)
+	})
+}
+
+// reorder marks the pairs of each kind of track that do not play in the same order in both versions, keeping the
+// longest run of pairs that does.
+func (d *SequenceDiff) reorder() {
+	for _, media := range []string{"video", "audio"} {
+		var pairs []*diffPair
+		for _, p := range d.pairs {
+			if p.after.Media == media {
+				pairs = append(pairs, p)
+			}
+		}
+
+		sort.SliceStable(pairs, func(i, j int) bool {
+			return lessEvent(pairs[i].before, pairs[j].before)
+		})
+
+		// longest increasing subsequence of record starts in the new version
+		n := len(pairs)
+		length, prev := make([]int, n), make([]int, n)
+		best := -1
+		for i := range pairs {
+			length[i], prev[i] = 1, -1
+			for j := 0; j < i; j++ {
+				if pairs[j].after.Start <= pairs[i].after.Start && length[j]+1 > length[i] {
+					length[i], prev[i] = length[j]+1, j
+				}
+			}
+			if best < 0 || length[i] > length[best] {
+				best = i
+			}
+		}
+
+		kept := map[int]bool{}
+		for i := best; i >= 0; i = prev[i] {
+			kept[i] = true
+		}
+		for i, p := range pairs {
+			p.reordered = !kept[i]
+		}
+	}
+}
+
+// compare adds the changes of a pair of clip items.
+func (d *SequenceDiff) compare(p *diffPair) {
+	b, a := p.before, p.after
+
+	if p.reordered {
+		d.Changes = append(d.Changes, &DiffChange{Kind: ChangeReordered, Before: b, After: a, Offset: a.Start - b.Start,
+			Details: fmt.Sprintf("%+d frames", a.Start-b.Start)})
+	} else if a.Track != b.Track {
+		d.Changes = append(d.Changes, &DiffChange{Kind: ChangeMoved, Before: b, After: a, Offset: a.Start - b.Start,
+			Details: fmt.Sprintf("from track %d, %+d frames", b.Track, a.Start-b.Start)})
+	} else if d.slid(p) {
+		d.Changes = append(d.Changes, &DiffChange{Kind: ChangeMoved, Before: b, After: a, Offset: a.Start - b.Start,
+			Details: fmt.Sprintf("%+d frames", a.Start-b.Start)})
+	}
+
+	if head, tail := b.In-a.In, a.Out-b.Out; head != 0 || tail != 0 {
+		var details []string
+		if head != 0 {
+			details = append(details, fmt.Sprintf("head %+d", head))
+		}
+		if tail != 0 {
+			details = append(details, fmt.Sprintf("tail %+d", tail))
+		}
+		d.Changes = append(d.Changes, &DiffChange{Kind: ChangeTrimmed, Before: b, After: a, Head: head, Tail: tail,
+			Details: strings.Join(details, ", ")})
+	}
+
+	if details := diffFilters(b.Item.Filters, a.Item.Filters); details != "" {
+		d.Changes = append(d.Changes, &DiffChange{Kind: ChangeFilters, Before: b, After: a, Details: details})
+	}
+
+	if details := diffMarkers(b.Item.Markers, a.Item.Markers); details != "" {
+		d.Changes = append(d.Changes, &DiffChange{Kind: ChangeMarkers, Before: b, After: a, Details: details})
+	}
+}
+
+// slid reports whether the gap ahead of a clip item changed, once the clip items that were inserted, deleted,
+// reordered or moved to another track are taken out of both versions. A clip item trimmed at its head is not
+// moved whether its start or the start of its source stayed in place.
+func (d *SequenceDiff) slid(p *diffPair) bool {
+	b, a := p.before, p.after
+
+	// the closest clip item ahead of it on the same track that stayed in place in both versions
+	var prev *diffPair
+	for _, q := range d.pairs {
+		if q != p && d.inPlace(q) && q.after.Media == a.Media && q.after.Track == a.Track && q.after.Start <= a.Start &&
+			lessEvent(q.after, a) && (prev == nil || lessEvent(prev.after, q.after)) {
+			prev = q
+		}
+	}
+
+	prevBefore, prevAfter := 0, 0
+	if prev != nil {
+		prevBefore, prevAfter = prev.before.End, prev.after.End
+	}
+
+	gone, added := 0, 0
+	for _, e := range d.deleted {
+		gone += between(e, b, prevBefore)
+	}
+	for _, e := range d.inserted {
+		added += between(e, a, prevAfter)
+	}
+	for _, q := range d.pairs {
+		if q != p && !d.inPlace(q) {
+			gone += between(q.before, b, prevBefore)
+			added += between(q.after, a, prevAfter)
+		}
+	}
+
+	beforeGap := b.Start - prevBefore - gone
+	afterGap := a.Start - prevAfter - added
+	head := ConvertFrames(b.In-a.In, a.rate, *d.After.Rate)
+
+	return afterGap != beforeGap && afterGap+head != beforeGap
+}
+
+// inPlace reports whether a pair of clip items is on the same track in the same order in both versions.
+func (d *SequenceDiff) inPlace(p *diffPair) bool {
+	return !p.reordered && p.before.Track == p.after.Track
+}
+
+// between returns the length of a clip item that plays between a frame and a clip item on the same track.
+func between(e *DiffEvent, next *DiffEvent, from int) int {
+	if e.Media != next.Media || e.Track != next.Track || e.Start < from || e.End > next.Start {
+		return 0
+	}
+
+	return e.End - e.Start
+}
+
+// position returns the record frame of a change in the new version. Deleted clip items are placed after the clip
+// item ahead of them that is part of both versions.
+func (d *SequenceDiff) position(c *DiffChange) int {
+	if c.After != nil {
+		return c.After.Start
+	}
+
+	b := c.Before
+	offset := 0
+	end := -1
+	for _, p := range d.pairs {
+		if p.before.Media == b.Media && p.before.Track == b.Track && p.before.End <= b.Start && p.before.End > end {
+			end, offset = p.before.End, p.after.End-p.before.End
+		}
+	}
+
+	return b.Start + offset
+}
+
+// lessEvent orders clip items by record start, video tracks first.
+func lessEvent(a *DiffEvent, b *DiffEvent) bool {
+	if a.Start != b.Start {
+		return a.Start < b.Start
+	}
+	if a.Media != b.Media {
+		return a.Media == "video"
+	}
+
+	return a.Track < b.Track
+}
+
+// diffFilters describes the filters added, removed and changed between two versions of a clip item.
+func diffFilters(before []*Filter, after []*Filter) string {
+	describe := func(fs []*Filter) ([]string, map[string]string) {
+		var names []string
+		values := map[string]string{}
+		seen := map[string]int{}
+		for _, f := range fs {
+			name := "filter"
+			if f.Effect != nil && f.Effect.Name != "" {
+				name = string(f.Effect.Name)
+			} else if f.Effect != nil && f.Effect.EffectID != "" {
+				name = string(f.Effect.EffectID)
+			}
+			// repeated filters are told apart by the order they are applied in
+			if seen[name]++; seen[name] > 1 {
+				name = fmt.Sprintf("%s %d", name, seen[name])
+			}
+
+			b, _ := xml.Marshal(f)
+			names = append(names, name)
+			values[name] = string(b)
+		}
+		return names, values
+	}
+
+	beforeNames, beforeValues := describe(before)
+	afterNames, afterValues := describe(after)
+
+	var details []string
+	for _, name := range afterNames {
+		if v, ok := beforeValues[name]; !ok {
+			details = append(details, "added "+name)
+		} else if v != afterValues[name] {
+			details = append(details, "changed "+name)
+		}
+	}
+	for _, name := range beforeNames {
+		if _, ok := afterValues[name]; !ok {
+			details = append(details, "removed "+name)
+		}
+	}
+
+	return strings.Join(details, ", ")
+}
+
+// diffMarkers describes the markers added and removed between two versions of a clip item.
+func diffMarkers(before []*Marker, after []*Marker) string {
+	count := func(ms []*Marker) map[string]int {
+		counts := map[string]int{}
+		for _, m := range ms {
+			b, _ := xml.Marshal(m)
+			counts[string(b)]++
+		}
+		return counts
+	}
+
+	b, a := count(before), count(after)
+	added, removed := 0, 0
+	for m, n := range a {
+		if n > b[m] {
+			added += n - b[m]
+		}
+	}
+	for m, n := range b {
+		if n > a[m] {
+			removed += n - a[m]
+		}
+	}
+
+	var details []string
+	if added > 0 {
+		details = append(details, fmt.Sprintf("%d added", added))
+	}
+	if removed > 0 {
+		details = append(details, fmt.Sprintf("%d removed", removed))
+	}
+
+	return strings.Join(details, ", ")
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+func absInt(a int) int {
+	if a < 0 {
+		return -a
+	}
+
+	return a
+}
+
+// Section: Diff Output
+
+// diffRow describes a change as it is written.
+type diffRow struct {
+	Kind      string `json:"kind"`
+	Track     string `json:"track"`
+	Name      string `json:"name"`
+	RecordIn  string `json:"recordIn"`
+	RecordOut string `json:"recordOut"`
+	Details   string `json:"details,omitempty"`
+
+	Before *diffRowEvent `json:"before,omitempty"`
+	After  *diffRowEvent `json:"after,omitempty"`
+}
+
+type diffRowEvent struct {
+	ID    string `json:"id,omitempty"`
+	Media string `json:"media"`
+	Track int    `json:"track"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	In    int    `json:"in"`
+	Out   int    `json:"out"`
+}
+
+// trackLabel names a track like an EDL channel, e.g. V1 or A2.
+func trackLabel(e *DiffEvent) string {
+	if e.Media == "video" {
+		return fmt.Sprintf("V%d", e.Track)
+	}
+
+	return fmt.Sprintf("A%d", e.Track)
+}
+
+// rows describes the changes with record timecodes of the version each clip item belongs to.
+func (d *SequenceDiff) rows() []*diffRow {
+	recordBefore := timeCoder(d.Before.TimeCode, *d.Before.Rate)
+	recordAfter := timeCoder(d.After.TimeCode, *d.After.Rate)
+
+	rowEvent := func(e *DiffEvent) *diffRowEvent {
+		if e == nil {
+			return nil
+		}
+		return &diffRowEvent{e.Item.ID, e.Media, e.Track, e.Start, e.End, e.In, e.Out}
+	}
+
+	var rows []*diffRow
+	for _, c := range d.Changes {
+		e, record := c.After, recordAfter
+		if e == nil {
+			e, record = c.Before, recordBefore
+		}
+
+		rows = append(rows, &diffRow{
+			Kind:      c.Kind.String(),
+			Track:     trackLabel(e),
+			Name:      string(e.Item.Name),
+			RecordIn:  record(e.Start),
+			RecordOut: record(e.End),
+			Details:   c.Details,
+			Before:    rowEvent(c.Before),
+			After:     rowEvent(c.After),
+		})
+	}
+
+	return rows
+}
+
+// WriteText writes the changes as text, one line per change with its track, kind, clip name, record timecodes and
+// details. Deleted clip items are written with record timecodes of the old version.
+func (d *SequenceDiff) WriteText(w io.Writer) error {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s -> %s: %d changes\n", d.Before.Name, d.After.Name, len(d.Changes))
+	for _, r := range d.rows() {
+		fmt.Fprintf(&b, "%-4s %-9s %s %s  %s", r.Track, r.Kind, r.RecordIn, r.RecordOut, r.Name)
+		if r.Details != "" {
+			fmt.Fprintf(&b, " (%s)", r.Details)
+		}
+		b.WriteString("\n")
+	}
+
+	_, err := b.WriteTo(w)
+
+	return err
+}
+
+// WriteJSON writes the changes as a JSON array of objects with the fields written by WriteText, along with the
+// record and source ranges of each version of the clip item in frames.
+func (d *SequenceDiff) WriteJSON(w io.Writer) error {
+	rows := d.rows()
+	if rows == nil {
+		rows = []*diffRow{}
+	}
+
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+
+	return e.Encode(rows)
+}
+
+var diffHTML = template.Must(template.New("diff").Parse(`<table class="sequence-diff">
+<thead><tr><th>Track</th><th>Change</th><th>Record In</th><th>Record Out</th><th>Clip</th><th>Details</th></tr></thead>
+<tbody>
+{{- range .}}
+<tr class="change-{{.Kind}}"><td>{{.Track}}</td><td>{{.Kind}}</td><td>{{.RecordIn}}</td><td>{{.RecordOut}}</td><td>{{.Name}}</td><td>{{.Details}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+`))
+
+// WriteHTML writes the changes as an HTML table to embed in a page, with a change-<kind> class on every row, e.g.
+// change-trimmed, for styling.
+func (d *SequenceDiff) WriteHTML(w io.Writer) error {
+	return diffHTML.Execute(w, d.rows())
+}
@@ -0,0 +1,188 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// diffItem makes a clip item playing a file by name for diff tests.
+func diffItem(clip string, from int, to int, srcIn int, srcOut int) *ClipItem {
+	return &ClipItem{
+		ID: clip, Name: name(clip), Enabled: true,
+		Start: start(from), End: end(to), In: in(srcIn), Out: out(srcOut),
+		File: &File{ID: "file-" + clip, Name: name(clip + ".mov"), Duration: 1000},
+	}
+}
+
+func diffSequence(title string, tracks ...[]*ClipItem) *Sequence {
+	s := &Sequence{Name: name(title), Rate: &Rate{TimeBase: 25}, Media: &Media{Video: &Video{}}}
+	for _, items := range tracks {
+		s.Media.Video.Tracks = append(s.Media.Video.Tracks, &Track{Enabled: true, ClipItems: items})
+	}
+
+	return s
+}
+
+func TestDiffingSequences(t *testing.T) {
+	before := diffSequence("Cut 1", []*ClipItem{
+		diffItem("A", 0, 50, 0, 50),
+		diffItem("B", 50, 100, 0, 50),
+		diffItem("C", 100, 150, 0, 50),
+		diffItem("D", 150, 200, 0, 50),
+		diffItem("E", 200, 250, 0, 50),
+		diffItem("F", 250, 300, 0, 50),
+	})
+
+	e := diffItem("E", 210, 260, 0, 50)
+	e.Filters = []*Filter{{Effect: &Effect{Name: "Gaussian Blur"}}}
+	e.Markers = []*Marker{{Name: "fix", In: 10, Out: -1}}
+	after := diffSequence("Cut 2", []*ClipItem{
+		diffItem("A", 0, 50, 0, 50),
+		diffItem("X", 50, 60, 0, 10),
+		diffItem("B", 60, 100, 0, 40),
+		diffItem("D", 100, 150, 0, 50),
+		diffItem("C", 150, 200, 0, 50),
+		e,
+	}, []*ClipItem{
+		diffItem("F", 300, 350, 0, 50),
+	})
+
+	d, err := DiffSequences(before, after)
+	if err != nil {
+		t.Fatal("sequences could not be compared: " + err.Error())
+	}
+
+	var got []string
+	for _, c := range d.Changes {
+		got = append(got, c.event().Item.ID+" "+c.Kind.String()+" "+c.Details)
+	}
+
+	want := []string{
+		"X inserted ",
+		"B trimmed tail -10",
+		"D reordered -50 frames",
+		"E moved +10 frames",
+		"E filters added Gaussian Blur",
+		"E markers 1 added",
+		"F moved from track 1, +50 frames",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("changes do not match expectations:\n%s", strings.Join(got, "\n"))
+	}
+
+	if c := d.Changes[1]; c.Head != 0 || c.Tail != -10 || c.Before.Item.ID != "B" {
+		t.Error("trim not described")
+	}
+
+	if c := d.Changes[3]; c.Offset != 10 || c.Before.Start != 200 || c.After.Start != 210 {
+		t.Error("move not described")
+	}
+
+	deleted := diffSequence("Cut 3", []*ClipItem{
+		diffItem("A", 0, 50, 0, 50),
+		diffItem("C", 50, 100, 0, 50),
+	})
+	d, _ = DiffSequences(before, deleted)
+	if len(d.Changes) != 4 || d.Changes[0].Kind != ChangeDeleted || d.Changes[0].Before.Item.ID != "B" {
+		t.Fatal("deleted clip items not reported in record order")
+	}
+
+	for _, c := range d.Changes {
+		if c.Kind != ChangeDeleted {
+			t.Errorf("clip item following a deletion reported: %s %s", c.event().Item.ID, c.Kind)
+		}
+	}
+}
+
+func TestDiffingTrimsAndSlips(t *testing.T) {
+	before := diffSequence("Cut 1", []*ClipItem{
+		diffItem("A", 0, 50, 100, 150),
+		diffItem("B", 50, 100, 0, 50),
+	})
+
+	// A is extended at its head by a ripple trim, B follows it
+	after := diffSequence("Cut 2", []*ClipItem{
+		diffItem("A", 0, 60, 90, 150),
+		diffItem("B", 60, 110, 0, 50),
+	})
+
+	d, _ := DiffSequences(before, after)
+	if len(d.Changes) != 1 || d.Changes[0].Kind != ChangeTrimmed || d.Changes[0].Head != 10 {
+		t.Error("ripple trim not reported as a trim")
+	}
+
+	// A is extended at its head by a roll into a gap
+	before.Media.Video.Tracks[0].ClipItems[0] = diffItem("A", 10, 50, 110, 150)
+	after.Media.Video.Tracks[0].ClipItems = []*ClipItem{diffItem("A", 0, 50, 100, 150), diffItem("B", 50, 100, 0, 50)}
+
+	d, _ = DiffSequences(before, after)
+	if len(d.Changes) != 1 || d.Changes[0].Kind != ChangeTrimmed || d.Changes[0].Head != 10 {
+		t.Error("roll trim not reported as a trim")
+	}
+
+	x := ImportRawXEML(mustRead(t, "export-examples/premier-export.xml"))
+	if d, err := DiffSequences(x.Sequence, ImportRawXEML(mustRead(t, "export-examples/premier-export.xml")).Sequence); err != nil || len(d.Changes) != 0 {
+		t.Error("the same cut differs")
+	}
+
+	// the Resolve export plays 9 frames of the same file on a single audio track
+	y := ImportRawXEML(mustRead(t, "export-examples/resolve-export.xml"))
+	d, err := DiffSequences(x.Sequence, y.Sequence)
+	if err != nil || len(d.Changes) != 5 {
+		t.Fatal("exports not compared")
+	}
+
+	if c := d.Changes[0]; c.Kind != ChangeTrimmed || c.Tail != -201 || c.After.Media != "video" {
+		t.Error("file URLs of the exports not matched")
+	}
+
+	if c := d.Changes[4]; c.Kind != ChangeDeleted || c.Before.Media != "audio" || c.Before.Track != 2 {
+		t.Error("deleted audio track not reported")
+	}
+
+	if _, err := DiffSequences(&Sequence{}, after); err == nil {
+		t.Error("sequence without a rate not reported")
+	}
+}
+
+func TestWritingDiffs(t *testing.T) {
+	before := diffSequence("Cut 1", []*ClipItem{diffItem("A", 0, 50, 0, 50)})
+	after := diffSequence("Cut 2", []*ClipItem{diffItem("A", 0, 40, 0, 40), diffItem("<B>", 40, 50, 0, 10)})
+	after.TimeCode = &TimeCode{Rate: &Rate{TimeBase: 25}, TimeCodeString: "01:00:00:00", DisplayFormat: timeCodeNonDropFrame}
+
+	d, err := DiffSequences(before, after)
+	if err != nil {
+		t.Fatal("sequences could not be compared: " + err.Error())
+	}
+
+	var text bytes.Buffer
+	if err := d.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	want := `Cut 1 -> Cut 2: 2 changes
+V1   trimmed   01:00:00:00 01:00:01:15  A (tail -10)
+V1   inserted  01:00:01:15 01:00:02:00  <B>
+`
+	if text.String() != want {
+		t.Errorf("text does not match expectations:\n%s", text.String())
+	}
+
+	var rows []map[string]interface{}
+	var j bytes.Buffer
+	if err := d.WriteJSON(&j); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(j.Bytes(), &rows); err != nil || len(rows) != 2 || rows[1]["kind"] != "inserted" || rows[1]["before"] != nil {
+		t.Errorf("JSON does not match expectations: %s", j.String())
+	}
+
+	var h bytes.Buffer
+	if err := d.WriteHTML(&h); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(h.String(), `<tr class="change-inserted">`) || !strings.Contains(h.String(), "&lt;B&gt;") {
+		t.Errorf("HTML does not match expectations: %s", h.String())
+	}
+}
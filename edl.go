@@ -0,0 +1,365 @@
+package converter
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	edlBlackReel  = "BL"
+	edlCut        = "C"
+	edlDissolve   = "D"
+	edlWipe       = "W"
+	edlSpeed      = "M2"
+	edlReelShort  = 8
+	edlReelLong   = 32
+	edlMarkerName = "RED"
+)
+
+// EDLOptions describes how a sequence is written as a CMX3600 EDL.
+type EDLOptions struct {
+	// Title is written in the header of the EDL, defaulting to the name of the sequence.
+	Title string
+	// ReelLength limits reel names to 8 or 32 characters, defaulting to 8 when it is 0. Other lengths are refused.
+	ReelLength int
+	// VideoTrack is the video track written as the V channel, counting from 1 and defaulting to 1. A negative
+	// track writes no video.
+	VideoTrack int
+	// AudioTracks are the audio tracks written as the A, A2, A3 and A4 channels, counting from 1 and defaulting
+	// to the first four tracks.
+	AudioTracks []int
+}
+
+// WriteEDL writes a sequence as a CMX3600 EDL. Every clip item of the chosen tracks becomes an event, and
+// transition items become dissolves or wipes.
+func WriteEDL(w io.Writer, s *Sequence, o EDLOptions) error {
+	if s.Rate == nil || s.Rate.TimeBase <= 0 {
+		return errors.New("sequence has no rate")
+	}
+
+	if o.Title == "" {
+		o.Title = string(s.Name)
+	}
+	if o.ReelLength == 0 {
+		o.ReelLength = edlReelShort
+	}
+	if o.ReelLength != edlReelShort && o.ReelLength != edlReelLong {
+		return fmt.Errorf("reel names are limited to %d or %d characters, not %d", edlReelShort, edlReelLong, o.ReelLength)
+	}
+	if o.VideoTrack == 0 {
+		o.VideoTrack = 1
+	}
+	if o.AudioTracks == nil {
+		o.AudioTracks = []int{1, 2, 3, 4}
+	}
+	if len(o.AudioTracks) > 4 {
+		return errors.New("a CMX3600 EDL has at most four audio channels")
+	}
+
+	e := &edlWriter{
+		o:     o,
+		rate:  *s.Rate,
+		media: (&RawXEML{Sequence: s}).MediaRegistry(),
+	}
+	e.record = timeCoder(s.TimeCode, *s.Rate)
+
+	var events []*edlEvent
+	if s.Media != nil && s.Media.Video != nil && o.VideoTrack > 0 && o.VideoTrack <= len(s.Media.Video.Tracks) {
+		events = append(events, e.trackEvents(s.Media.Video.Tracks[o.VideoTrack-1], "V")...)
+	}
+	for i, n := range o.AudioTracks {
+		if s.Media == nil || s.Media.Audio == nil || n < 1 || n > len(s.Media.Audio.Tracks) {
+			continue
+		}
+
+		channel := "A"
+		if i > 0 {
+			channel = fmt.Sprintf("A%d", i+1)
+		}
+		events = append(events, e.trackEvents(s.Media.Audio.Tracks[n-1], channel)...)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].recIn < events[j].recIn
+	})
+
+	e.addMarkers(events, s.Markers)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "TITLE: %s\n", o.Title)
+	if s.TimeCode != nil && s.TimeCode.DropFrame() && s.TimeCode.Rate != nil && s.TimeCode.Rate.SupportsDropFrame() {
+		fmt.Fprint(bw, "FCM: DROP FRAME\n")
+	} else {
+		fmt.Fprint(bw, "FCM: NON-DROP FRAME\n")
+	}
+
+	for i, ev := range events {
+		fmt.Fprint(bw, "\n")
+		for _, l := range ev.lines {
+			dur := "   "
+			if l.edit != edlCut {
+				dur = fmt.Sprintf("%03d", l.duration)
+			}
+
+			fmt.Fprintf(bw, "%03d  %-*s %-5s %-4s %s %s %s %s %s\n",
+				i+1, o.ReelLength, l.reel, ev.channel, l.edit, dur,
+				l.src(l.srcIn), l.src(l.srcIn+e.sourceFrames(l.recOut-l.recIn, l.rate)), e.record(l.recIn), e.record(l.recOut))
+		}
+		for _, c := range ev.comments {
+			fmt.Fprintf(bw, "* %s\n", c)
+		}
+	}
+
+	return bw.Flush()
+}
+
+type edlWriter struct {
+	o      EDLOptions
+	rate   Rate
+	media  *MediaRegistry
+	record func(frame int) string
+}
+
+// sourceFrames converts a number of record frames to the number of source frames at a rate that play as long.
+func (e *edlWriter) sourceFrames(frames int, r Rate) int {
+	if r.Equal(e.rate) {
+		return frames
+	}
+
+	return ConvertFrames(frames, e.rate, r)
+}
+
+// edlEvent describes a numbered event; dissolves take two lines.
+type edlEvent struct {
+	channel  string
+	recIn    int
+	recOut   int
+	lines    []edlLine
+	comments []string
+}
+
+type edlLine struct {
+	reel     string
+	edit     string
+	duration int
+	srcIn    int
+	recIn    int
+	recOut   int
+	// rate is the rate of the source frames
+	rate Rate
+	src  func(frame int) string
+}
+
+// edlClip describes the source of a clip item on a track.
+type edlClip struct {
+	*trackEdit
+	reel string
+	rate Rate
+	src  func(frame int) string
+}
+
+// timeCoder formats frames at a rate as timecode starting from tc.
+func timeCoder(tc *TimeCode, r Rate) func(frame int) string {
+	if tc == nil || tc.Rate == nil || tc.Rate.TimeBase <= 0 {
+		return func(frame int) string {
+			return FormatTimeCode(frame, r, false)
+		}
+	}
+
+	start, err := tc.Frames()
+	if err != nil {
+		start = int(tc.Frame)
+	}
+	tcRate, df := *tc.Rate, tc.DropFrame()
+
+	return func(frame int) string {
+		return FormatTimeCode(start+ConvertFrames(frame, r, tcRate), tcRate, df)
+	}
+}
+
+func (e *edlWriter) trackEvents(t *Track, channel string) []*edlEvent {
+	edits, transitions := editTrack(t)
+
+	var events []*edlEvent
+	for _, te := range edits {
+		c := e.clip(te)
+		ev := &edlEvent{channel: channel, recIn: c.start, recOut: c.end}
+		if c.out != nil {
+			ev.recOut = c.out.start
+		}
+
+		srcIn := int(c.item.In)
+		line := edlLine{reel: c.reel, edit: edlCut, srcIn: srcIn, recIn: c.start, recOut: ev.recOut, rate: c.rate, src: c.src}
+		if c.in != nil {
+			ev.recIn = c.in.start
+			line.edit = edlTransitionEdit(c.in.item)
+			line.duration = c.in.end - c.in.start
+			line.srcIn = srcIn - e.sourceFrames(c.in.cut-c.in.start, c.rate)
+			line.recIn = c.in.start
+
+			ev.lines = append(ev.lines, e.fromLine(c.in))
+			if c.in.from != nil {
+				ev.comments = append(ev.comments, "FROM CLIP NAME: "+string(c.in.from.item.Name))
+				ev.comments = append(ev.comments, "TO CLIP NAME: "+string(c.item.Name))
+			} else {
+				ev.comments = append(ev.comments, "TO CLIP NAME: "+string(c.item.Name))
+			}
+		} else {
+			ev.comments = append(ev.comments, "FROM CLIP NAME: "+string(c.item.Name))
+		}
+
+		ev.lines = append(ev.lines, line)
+		events = append(events, ev)
+	}
+
+	// transitions to black follow the clip they fade out
+	for _, tr := range transitions {
+		if tr.to != nil || tr.from == nil {
+			continue
+		}
+
+		events = append(events, &edlEvent{
+			channel: channel,
+			recIn:   tr.start,
+			recOut:  tr.end,
+			lines: []edlLine{
+				e.fromLine(tr),
+				{
+					reel:     edlBlackReel,
+					edit:     edlTransitionEdit(tr.item),
+					duration: tr.end - tr.start,
+					recIn:    tr.start,
+					recOut:   tr.end,
+					rate:     e.rate,
+					src:      timeCoder(nil, e.rate),
+				},
+			},
+			comments: []string{"FROM CLIP NAME: " + string(tr.from.item.Name)},
+		})
+	}
+
+	return events
+}
+
+// edlTransitionEdit is the edit type of a transition, a dissolve unless it is a wipe.
+func edlTransitionEdit(ti *TransitionItem) string {
+	if ti.Effect != nil && ti.Effect.WipeCode > 0 {
+		return fmt.Sprintf("%s%03d", edlWipe, ti.Effect.WipeCode)
+	}
+
+	return edlDissolve
+}
+
+// fromLine is the zero length cut that begins a dissolve, holding the outgoing source at the start of the
+// transition.
+func (e *edlWriter) fromLine(tr *trackTransition) edlLine {
+	if tr.from == nil {
+		return edlLine{reel: edlBlackReel, edit: edlCut, recIn: tr.start, recOut: tr.start, rate: e.rate,
+			src: timeCoder(nil, e.rate)}
+	}
+
+	c := e.clip(tr.from)
+
+	return edlLine{
+		reel:   c.reel,
+		edit:   edlCut,
+		srcIn:  int(c.item.In) + e.sourceFrames(tr.start-c.start, c.rate),
+		recIn:  tr.start,
+		recOut: tr.start,
+		rate:   c.rate,
+		src:    c.src,
+	}
+}
+
+// clip names the reel of an edit and formats its source timecode.
+func (e *edlWriter) clip(te *trackEdit) *edlClip {
+	ci := te.item
+	r := e.rate
+	if ci.Rate != nil && ci.Rate.TimeBase > 0 {
+		r = *ci.Rate
+	}
+
+	f := e.media.Canonical(ci.File)
+	if f == nil {
+		f = ci.File
+	}
+
+	tc := ci.TimeCode
+	if tc == nil && f != nil {
+		tc = f.TimeCode
+	}
+
+	return &edlClip{trackEdit: te, reel: e.reel(ci, f, tc), rate: r, src: timeCoder(tc, r)}
+}
+
+// reel names the source of a clip item for the reel column of an EDL.
+func (e *edlWriter) reel(ci *ClipItem, f *File, tc *TimeCode) string {
+	return edlReelName(reelName(ci, f, tc), e.o.ReelLength)
+}
+
+// reelName names the source of a clip item from the reel of its timecode, or else from its file name.
+func reelName(ci *ClipItem, f *File, tc *TimeCode) string {
+	switch {
+	case tc != nil && tc.Reel != nil && tc.Reel.Name != "":
+		return string(tc.Reel.Name)
+	case f != nil && f.Name != "":
+		return strings.TrimSuffix(string(f.Name), path.Ext(string(f.Name)))
+	case ci != nil:
+		return string(ci.Name)
+	}
+
+	return ""
+}
+
+// edlReelName limits a reel name to the characters and length an EDL reel column can hold.
+func edlReelName(name string, length int) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '-':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+
+	reel := b.String()
+	if len(reel) > length {
+		reel = reel[:length]
+	}
+	if reel == "" {
+		reel = "AX"
+	}
+
+	return reel
+}
+
+// addMarkers adds a locator comment to the first event in which each marker falls.
+func (e *edlWriter) addMarkers(events []*edlEvent, markers []*Marker) {
+	for _, m := range markers {
+		var target *edlEvent
+		for _, ev := range events {
+			if ev.recIn <= int(m.In) && int(m.In) < ev.recOut {
+				target = ev
+				break
+			}
+			if ev.recIn <= int(m.In) {
+				target = ev
+			}
+		}
+		if target == nil {
+			continue
+		}
+
+		text := string(m.Name)
+		if m.Comment != "" {
+			text += " " + string(m.Comment)
+		}
+		target.comments = append(target.comments, fmt.Sprintf("LOC: %s %-7s %s", e.record(int(m.In)), edlMarkerName, text))
+	}
+}
@@ -0,0 +1,648 @@
+package converter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const timeRemapEffectID = "timeremap"
+
+// EDLError describes a malformed line of an EDL.
+type EDLError struct {
+	Line int
+	Text string
+	Err  error
+}
+
+func (e *EDLError) Error() string {
+	return fmt.Sprintf("edl line %d: %v: %q", e.Line, e.Err, e.Text)
+}
+
+// Unwrap returns the underlying error.
+func (e *EDLError) Unwrap() error {
+	return e.Err
+}
+
+// ParseEDL imports a CMX3600 EDL into a raw XEML data tree holding one sequence at a rate. Events become clip
+// items with files keyed by reel and clip name, dissolves and wipes become transition items, M2 speed changes
+// become time remap filters and locators become markers.
+func ParseEDL(r io.Reader, rate Rate) (RawXEML, error) {
+	if rate.TimeBase <= 0 {
+		return RawXEML{}, fmt.Errorf("edl rate has no time base")
+	}
+
+	p := &edlParser{rate: rate}
+	if err := p.parse(r); err != nil {
+		return RawXEML{}, err
+	}
+
+	return RawXEML{Version: 5, Sequence: p.sequence()}, nil
+}
+
+// edlChannels describes the tracks an event is recorded to.
+type edlChannels struct {
+	video bool
+	audio []int
+}
+
+type edlParsedLine struct {
+	line     int
+	reel     string
+	channels edlChannels
+	edit     string
+	wipe     int
+	duration int
+	srcIn    int
+	srcOut   int
+	recIn    int
+	recOut   int
+}
+
+type edlParsedEvent struct {
+	number   int
+	lines    []*edlParsedLine
+	fromName string
+	toName   string
+	comments []string
+	speed    float64
+	hasSpeed bool
+}
+
+type edlParsedMarker struct {
+	frame int
+	name  string
+}
+
+type edlFileKey struct {
+	reel string
+	name string
+}
+
+type edlFile struct {
+	file    *File
+	defined bool
+	srcIn   int
+	srcOut  int
+}
+
+type edlParser struct {
+	rate      Rate
+	title     string
+	dropFrame bool
+	events    []*edlParsedEvent
+	markers   []edlParsedMarker
+	files     map[edlFileKey]*edlFile
+	fileOrder []edlFileKey
+	items     int
+}
+
+func (p *edlParser) parse(r io.Reader) error {
+	s := bufio.NewScanner(r)
+	n := 0
+	var event *edlParsedEvent
+
+	for s.Scan() {
+		n++
+		text := strings.TrimRight(s.Text(), "\r")
+		line := strings.TrimSpace(text)
+		fail := func(format string, a ...interface{}) error {
+			return &EDLError{Line: n, Text: text, Err: fmt.Errorf(format, a...)}
+		}
+
+		switch {
+		case line == "":
+			continue
+
+		case strings.HasPrefix(line, "TITLE:"):
+			p.title = strings.TrimSpace(strings.TrimPrefix(line, "TITLE:"))
+
+		case strings.HasPrefix(line, "FCM:"):
+			fcm := strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(line, "FCM:")))
+			p.dropFrame = fcm == "DROP FRAME"
+			if p.dropFrame && !p.rate.SupportsDropFrame() {
+				return fail("drop frame timecode is not supported at %d fps", p.rate.TimeBase)
+			}
+
+		case strings.HasPrefix(line, "*"):
+			if err := p.comment(event, strings.TrimSpace(strings.TrimPrefix(line, "*"))); err != nil {
+				return fail("%v", err)
+			}
+
+		case strings.HasPrefix(line, edlSpeed):
+			if event == nil {
+				return fail("speed change before the first event")
+			}
+			if err := p.speed(event, strings.Fields(line)); err != nil {
+				return fail("%v", err)
+			}
+
+		default:
+			fields := strings.Fields(line)
+			number, err := strconv.Atoi(fields[0])
+			if err != nil {
+				// other statements such as SPLIT and SWM are not supported, and do not affect the events
+				continue
+			}
+
+			l, err := p.eventLine(fields)
+			if err != nil {
+				return fail("%v", err)
+			}
+			l.line = n
+
+			if event == nil || event.number != number {
+				event = &edlParsedEvent{number: number}
+				p.events = append(p.events, event)
+			}
+			if len(event.lines) == 2 {
+				return fail("event %d has more than two lines", number)
+			}
+			if len(event.lines) == 1 && l.edit == edlCut {
+				return fail("second line of event %d is not a transition", number)
+			}
+			event.lines = append(event.lines, l)
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return err
+	}
+
+	for _, e := range p.events {
+		if e.lines[0].edit != edlCut && len(e.lines) == 1 {
+			return &EDLError{Line: e.lines[0].line, Err: fmt.Errorf("transition of event %d has no outgoing source", e.number)}
+		}
+	}
+
+	return nil
+}
+
+func (p *edlParser) timeCode(s string) (int, error) {
+	return parseTimeCode(s, p.rate, p.dropFrame || strings.ContainsAny(s, ";,."))
+}
+
+// eventLine parses an event line: number, reel, channels, edit, an optional transition duration and the source
+// and record timecodes.
+func (p *edlParser) eventLine(fields []string) (*edlParsedLine, error) {
+	if len(fields) != 8 && len(fields) != 9 {
+		return nil, fmt.Errorf("event has %d fields, expected 8 or 9", len(fields))
+	}
+
+	l := &edlParsedLine{reel: fields[1], edit: strings.ToUpper(fields[3])}
+
+	channels, err := parseEDLChannels(fields[2])
+	if err != nil {
+		return nil, err
+	}
+	l.channels = channels
+
+	switch {
+	case l.edit == edlCut:
+		if len(fields) != 8 {
+			return nil, fmt.Errorf("cut has a transition duration")
+		}
+	case l.edit == edlDissolve || strings.HasPrefix(l.edit, edlWipe):
+		if len(fields) != 9 {
+			return nil, fmt.Errorf("transition has no duration")
+		}
+		if l.edit != edlDissolve {
+			if l.wipe, err = strconv.Atoi(l.edit[1:]); err != nil {
+				return nil, fmt.Errorf("wipe code %q is not a number", l.edit[1:])
+			}
+		}
+		if l.duration, err = strconv.Atoi(fields[4]); err != nil || l.duration < 0 {
+			return nil, fmt.Errorf("transition duration %q is not a number of frames", fields[4])
+		}
+	default:
+		return nil, fmt.Errorf("edit type %q is not supported", l.edit)
+	}
+
+	tcs := fields[len(fields)-4:]
+	for i, dst := range []*int{&l.srcIn, &l.srcOut, &l.recIn, &l.recOut} {
+		if *dst, err = p.timeCode(tcs[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	if l.srcOut < l.srcIn || l.recOut < l.recIn {
+		return nil, fmt.Errorf("event ends before it starts")
+	}
+
+	return l, nil
+}
+
+// parseEDLChannels parses a channel field such as V, A2, AA/V or B.
+func parseEDLChannels(s string) (edlChannels, error) {
+	var c edlChannels
+	for _, part := range strings.Split(strings.ToUpper(s), "/") {
+		switch part {
+		case "V":
+			c.video = true
+		case "A", "A1":
+			c.audio = append(c.audio, 1)
+		case "A2", "A3", "A4":
+			c.audio = append(c.audio, int(part[1]-'0'))
+		case "AA":
+			c.audio = append(c.audio, 1, 2)
+		case "B":
+			c.video = true
+			c.audio = append(c.audio, 1)
+		case "NONE":
+		default:
+			return c, fmt.Errorf("channel %q is not supported", s)
+		}
+	}
+
+	return c, nil
+}
+
+func (p *edlParser) comment(e *edlParsedEvent, c string) error {
+	upper := strings.ToUpper(c)
+
+	switch {
+	case strings.HasPrefix(upper, "LOC:"):
+		fields := strings.Fields(c[len("LOC:"):])
+		if len(fields) == 0 {
+			return fmt.Errorf("locator has no timecode")
+		}
+
+		frame, err := p.timeCode(fields[0])
+		if err != nil {
+			return err
+		}
+
+		text := ""
+		if len(fields) > 2 {
+			text = strings.Join(fields[2:], " ")
+		}
+		p.markers = append(p.markers, edlParsedMarker{frame: frame, name: text})
+
+	case e == nil:
+		// comments before the first event describe the EDL rather than an event
+
+	case strings.HasPrefix(upper, "FROM CLIP NAME:"):
+		e.fromName = strings.TrimSpace(c[len("FROM CLIP NAME:"):])
+
+	case strings.HasPrefix(upper, "TO CLIP NAME:"):
+		e.toName = strings.TrimSpace(c[len("TO CLIP NAME:"):])
+
+	default:
+		e.comments = append(e.comments, c)
+	}
+
+	return nil
+}
+
+// speed parses an M2 line: reel, speed in frames per second and the source timecode the speed change starts at.
+func (p *edlParser) speed(e *edlParsedEvent, fields []string) error {
+	if len(fields) != 4 {
+		return fmt.Errorf("speed change has %d fields, expected 4", len(fields))
+	}
+
+	speed, err := strconv.ParseFloat(fields[2], 64)
+	if err != nil {
+		return fmt.Errorf("speed %q is not a number", fields[2])
+	}
+	if _, err := p.timeCode(fields[3]); err != nil {
+		return err
+	}
+
+	e.speed = speed
+	e.hasSpeed = true
+
+	return nil
+}
+
+// sequence builds the sequence once every event has been parsed.
+func (p *edlParser) sequence() *Sequence {
+	p.collectFiles()
+
+	origin := 0
+	if len(p.events) > 0 {
+		origin = math.MaxInt32
+		for _, e := range p.events {
+			for _, l := range e.lines {
+				if l.recIn < origin {
+					origin = l.recIn
+				}
+			}
+		}
+		// sequences conventionally start on the hour
+		hour := strings.SplitN(FormatTimeCode(origin, p.rate, p.dropFrame), ":", 2)[0]
+		origin, _ = parseTimeCode(hour+":00:00:00", p.rate, p.dropFrame)
+	}
+
+	s := &Sequence{
+		Name: name(p.title),
+		Rate: p.rateElement(),
+		In:   -1,
+		Out:  -1,
+		Media: &Media{
+			Video: &Video{},
+			Audio: &Audio{},
+		},
+	}
+	s.TimeCode = p.timeCodeElement(origin, "")
+
+	for _, e := range p.events {
+		p.addEvent(s, e, origin)
+	}
+
+	for _, t := range s.Media.tracks() {
+		for _, ci := range t.ClipItems {
+			if int(ci.End) > int(s.Duration) {
+				s.Duration = duration(ci.End)
+			}
+		}
+		for _, ti := range t.TransitionItems {
+			if int(ti.End) > int(s.Duration) {
+				s.Duration = duration(ti.End)
+			}
+		}
+	}
+
+	for _, m := range p.markers {
+		s.Markers = append(s.Markers, &Marker{Name: name(m.name), In: in(m.frame - origin), Out: -1})
+	}
+
+	if len(s.Media.Video.Tracks) == 0 {
+		s.Media.Video = nil
+	}
+	if len(s.Media.Audio.Tracks) == 0 {
+		s.Media.Audio = nil
+	}
+
+	return s
+}
+
+func (p *edlParser) rateElement() *Rate {
+	r := p.rate
+
+	return &r
+}
+
+func (p *edlParser) timeCodeElement(frames int, reel string) *TimeCode {
+	tc := &TimeCode{
+		DisplayFormat: timeCodeNonDropFrame,
+		Rate:          p.rateElement(),
+	}
+	if p.dropFrame {
+		tc.DisplayFormat = timeCodeDropFrame
+	}
+	if reel != "" {
+		tc.Reel = &Reel{Name: name(reel)}
+	}
+	tc.SetFrames(frames)
+
+	return tc
+}
+
+// collectFiles finds the source range used of every file, so that files can start at the first frame used.
+func (p *edlParser) collectFiles() {
+	p.files = map[edlFileKey]*edlFile{}
+
+	use := func(l *edlParsedLine, clipName string, length int) {
+		if l.reel == edlBlackReel || length == 0 {
+			return
+		}
+
+		key := edlFileKey{reel: l.reel, name: clipName}
+		f, ok := p.files[key]
+		if !ok {
+			f = &edlFile{srcIn: l.srcIn, srcOut: l.srcOut}
+			p.files[key] = f
+			p.fileOrder = append(p.fileOrder, key)
+		}
+
+		if l.srcIn < f.srcIn {
+			f.srcIn = l.srcIn
+		}
+		if l.srcIn+length > f.srcOut {
+			f.srcOut = l.srcIn + length
+		}
+	}
+
+	for _, e := range p.events {
+		for i, l := range e.lines {
+			length := p.sourceLength(e, l)
+			if i == 0 && len(e.lines) == 2 && e.lines[1].reel == edlBlackReel {
+				// a fade to black holds the outgoing clip through the transition
+				length += e.lines[1].duration
+			}
+			use(l, p.clipName(e, i), length)
+		}
+	}
+
+	for i, key := range p.fileOrder {
+		f := p.files[key]
+		fileName := key.name
+		if fileName == "" {
+			fileName = key.reel
+		}
+
+		f.file = &File{
+			ID:       fmt.Sprintf("file-%d", i+1),
+			Name:     name(fileName),
+			Duration: duration(f.srcOut - f.srcIn),
+			Rate:     p.rateElement(),
+			TimeCode: p.timeCodeElement(f.srcIn, key.reel),
+		}
+	}
+}
+
+// sourceLength is the number of source frames used by a line, taking speed changes into account.
+func (p *edlParser) sourceLength(e *edlParsedEvent, l *edlParsedLine) int {
+	length := l.recOut - l.recIn
+	if e.hasSpeed {
+		length = int(math.Round(float64(length) * math.Abs(e.speed) / p.rate.FPS()))
+	}
+
+	return length
+}
+
+// clipName names the clip of a line of an event from its clip name comments.
+func (p *edlParser) clipName(e *edlParsedEvent, line int) string {
+	if len(e.lines) == 2 && line == 1 {
+		return e.toName
+	}
+
+	return e.fromName
+}
+
+// fileElement returns the definition of a file the first time it is used, and a reference to it afterwards.
+func (p *edlParser) fileElement(l *edlParsedLine, clipName string) (*File, *edlFile) {
+	f := p.files[edlFileKey{reel: l.reel, name: clipName}]
+	if f.defined {
+		return &File{ID: f.file.ID}, f
+	}
+	f.defined = true
+
+	return f.file, f
+}
+
+func (p *edlParser) addEvent(s *Sequence, e *edlParsedEvent, origin int) {
+	last := e.lines[len(e.lines)-1]
+
+	for _, track := range p.tracks(s, last.channels) {
+		if len(e.lines) == 1 {
+			p.addClipItem(track, e, e.lines[0], p.clipName(e, 0), origin)
+			continue
+		}
+
+		from, to := e.lines[0], e.lines[1]
+		if from.recOut > from.recIn {
+			p.addClipItem(track, e, from, p.clipName(e, 0), origin)
+		}
+
+		ti := &TransitionItem{
+			Rate:      p.rateElement(),
+			Start:     start(to.recIn - origin),
+			End:       end(to.recIn - origin + to.duration),
+			Alignment: alignmentStart,
+			Effect:    transitionEffect(track.mediaType, to.wipe),
+		}
+
+		switch {
+		case from.reel == edlBlackReel:
+			ti.Alignment = alignmentStartBlack
+		case to.reel == edlBlackReel:
+			// a fade to black holds the outgoing clip through the transition
+			ti.Alignment = alignmentEndBlack
+			for _, ci := range track.ClipItems {
+				if int(ci.End) == int(ti.Start) {
+					ci.End += end(to.duration)
+					ci.Out += out(to.duration)
+				}
+			}
+		}
+		track.TransitionItems = append(track.TransitionItems, ti)
+
+		if to.reel != edlBlackReel {
+			p.addClipItem(track, e, to, p.clipName(e, 1), origin)
+		}
+	}
+}
+
+// edlTrack is a track along with the type of media it holds.
+type edlTrack struct {
+	*Track
+	mediaType string
+}
+
+// tracks returns the tracks of channels, adding tracks to the sequence as needed.
+func (p *edlParser) tracks(s *Sequence, c edlChannels) []edlTrack {
+	var ts []edlTrack
+	if c.video {
+		if len(s.Media.Video.Tracks) == 0 {
+			s.Media.Video.Tracks = append(s.Media.Video.Tracks, &Track{Enabled: true})
+		}
+		ts = append(ts, edlTrack{s.Media.Video.Tracks[0], "video"})
+	}
+
+	sort.Ints(c.audio)
+	for _, a := range c.audio {
+		for len(s.Media.Audio.Tracks) < a {
+			s.Media.Audio.Tracks = append(s.Media.Audio.Tracks, &Track{Enabled: true})
+		}
+		ts = append(ts, edlTrack{s.Media.Audio.Tracks[a-1], "audio"})
+	}
+
+	return ts
+}
+
+func (p *edlParser) addClipItem(t edlTrack, e *edlParsedEvent, l *edlParsedLine, clipName string, origin int) {
+	if l.reel == edlBlackReel {
+		return
+	}
+
+	f, ef := p.fileElement(l, clipName)
+	p.items++
+
+	itemName := clipName
+	if itemName == "" {
+		itemName = l.reel
+	}
+
+	ci := &ClipItem{
+		ID:       fmt.Sprintf("clipitem-%d", p.items),
+		Name:     name(itemName),
+		Duration: ef.file.Duration,
+		Rate:     p.rateElement(),
+		Start:    start(l.recIn - origin),
+		End:      end(l.recOut - origin),
+		In:       in(l.srcIn - ef.srcIn),
+		Out:      out(l.srcIn - ef.srcIn + p.sourceLength(e, l)),
+		Enabled:  true,
+		File:     f,
+	}
+
+	if e.hasSpeed {
+		ci.Filters = append(ci.Filters, timeRemapFilter(e.speed/p.rate.FPS()*100, t.mediaType))
+	}
+
+	if len(e.comments) > 0 {
+		ci.Comments = &Comments{ClipCommentA: comment(strings.Join(e.comments, "\n"))}
+	}
+
+	t.ClipItems = append(t.ClipItems, ci)
+}
+
+// transitionEffect describes the effect of a dissolve, or of a wipe when a wipe code is set.
+func transitionEffect(media string, wipe int) *Effect {
+	if media == "audio" {
+		return &Effect{
+			Name:       "Cross Fade (+3dB)",
+			EffectID:   "KGAudioTransCrossFade3dB",
+			EffectType: "transition",
+			MediaType:  "audio",
+		}
+	}
+
+	if wipe > 0 {
+		return &Effect{
+			Name:           "Wipe",
+			EffectID:       "Wipe",
+			EffectType:     "transition",
+			MediaType:      "video",
+			EffectCategory: "Wipe",
+			WipeCode:       wipeCode(wipe),
+		}
+	}
+
+	return &Effect{
+		Name:           "Cross Dissolve",
+		EffectID:       "Cross Dissolve",
+		EffectType:     "transition",
+		MediaType:      "video",
+		EffectCategory: "Dissolve",
+	}
+}
+
+// timeRemapFilter describes a constant speed change as a percentage, reversing for negative speeds.
+func timeRemapFilter(percent float64, media string) *Filter {
+	reverse := "FALSE"
+	if percent < 0 {
+		reverse = "TRUE"
+	}
+
+	return &Filter{
+		Enabled: true,
+		Effect: &Effect{
+			Name:           "Time Remap",
+			EffectID:       timeRemapEffectID,
+			EffectType:     "motion",
+			MediaType:      mediaType(media),
+			EffectCategory: "motion",
+			Parameters: []*Parameter{
+				{ParameterID: "variablespeed", Name: "variablespeed", Value: &Value{Data: "0"}},
+				{ParameterID: "speed", Name: "speed", Value: &Value{Data: strconv.FormatFloat(math.Abs(percent), 'f', -1, 64)}},
+				{ParameterID: "reverse", Name: "reverse", Value: &Value{Data: reverse}},
+			},
+		},
+	}
+}
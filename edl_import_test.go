@@ -0,0 +1,125 @@
+package converter
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParsingAnEDL(t *testing.T) {
+	e := `TITLE: Cut 2
+FCM: DROP FRAME
+
+001  A001     V     C        02:00:01;00 02:00:02;20 01:00:00;00 01:00:01;20
+* FROM CLIP NAME: Shot 1
+* LOC: 01:00:01;15 RED     Fix colour
+
+002  A001     A     C        02:00:01;00 02:00:03;00 01:00:00;00 01:00:02;00
+* FROM CLIP NAME: Shot 1
+
+003  A001     V     C        02:00:02;20 02:00:02;20 01:00:01;20 01:00:01;20
+003  B002     V     W001 020 03:00:00;00 03:00:02;10 01:00:01;20 01:00:04;00
+* FROM CLIP NAME: Shot 1
+* TO CLIP NAME: Shot 2
+`
+
+	x, err := ParseEDL(strings.NewReader(e), Rate{TimeBase: 30, NTSC: true})
+	if err != nil {
+		t.Fatal("EDL could not be parsed: " + err.Error())
+	}
+
+	s := x.Sequence
+
+	if s.Name != "Cut 2" || s.TimeCode.TimeCodeString != "01:00:00;00" {
+		t.Error("EDL header not parsed")
+	}
+
+	if len(s.Media.Video.Tracks) != 1 || len(s.Media.Audio.Tracks) != 1 {
+		t.Fatal("EDL channels not parsed into tracks")
+	}
+
+	video := s.Media.Video.Tracks[0]
+
+	if len(video.ClipItems) != 2 || len(video.TransitionItems) != 1 {
+		t.Fatal("EDL events not parsed into clip items and transition items")
+	}
+
+	if video.ClipItems[1].Name != "Shot 2" || video.ClipItems[1].Start != 50 || video.ClipItems[1].End != 120 {
+		t.Error("incoming clip of transition not parsed")
+	}
+
+	if video.TransitionItems[0].Effect.WipeCode != 1 {
+		t.Error("wipe code not parsed")
+	}
+
+	if video.ClipItems[0].File.ID != "file-1" || s.Media.Audio.Tracks[0].ClipItems[0].File.ID != "file-1" {
+		t.Error("clip items of the same reel and clip name do not share a file")
+	}
+
+	if len(s.Markers) != 1 || s.Markers[0].In != 45 || s.Markers[0].Name != "Fix colour" {
+		t.Error("locator not parsed into a marker")
+	}
+
+	var b bytes.Buffer
+	if err := WriteEDL(&b, s, EDLOptions{}); err != nil {
+		t.Fatal("EDL could not be written: " + err.Error())
+	}
+
+	if b.String() != e {
+		t.Error("written EDL does not match parsed EDL")
+		t.Log("Expected: " + e)
+		t.Log("Got: " + b.String())
+	}
+}
+
+func TestParsingEDLSpeedChanges(t *testing.T) {
+	e := `TITLE: Speed
+FCM: NON-DROP FRAME
+
+001  TAPE1    AA/V  C        00:00:10:00 00:00:12:00 01:00:00:00 01:00:02:00
+M2   TAPE1       012.0                00:00:10:00
+* FROM CLIP NAME: Slow
+`
+
+	x, err := ParseEDL(strings.NewReader(e), Rate{TimeBase: 24})
+	if err != nil {
+		t.Fatal("EDL could not be parsed: " + err.Error())
+	}
+
+	if len(x.Sequence.Media.Audio.Tracks) != 2 {
+		t.Fatal("AA/V channels not parsed into two audio tracks")
+	}
+
+	ci := x.Sequence.Media.Video.Tracks[0].ClipItems[0]
+
+	if ci.Out-out(ci.In) != 24 {
+		t.Errorf("source range of speed change not parsed: %d-%d", ci.In, ci.Out)
+	}
+
+	if len(ci.Filters) != 1 || ci.Filters[0].Effect.Parameters[1].Value.Data != "50" {
+		t.Error("speed change not parsed into a time remap filter")
+	}
+}
+
+func TestParsingMalformedEDLs(t *testing.T) {
+	e := `TITLE: Broken
+
+001  TAPE1    V     C        00:00:10:00 00:00:12:00 01:00:00:00
+`
+
+	_, err := ParseEDL(strings.NewReader(e), Rate{TimeBase: 25})
+
+	var ee *EDLError
+	if !errors.As(err, &ee) || ee.Line != 3 {
+		t.Errorf("malformed event not reported with its line: %v", err)
+	}
+
+	e = `FCM: DROP FRAME
+001  TAPE1    V     C        00:00:10:00 00:00:12:00 01:00:00:00 01:00:02:00
+`
+
+	if _, err := ParseEDL(strings.NewReader(e), Rate{TimeBase: 25}); err == nil {
+		t.Error("drop frame EDL at a rate without drop frame not reported")
+	}
+}
@@ -0,0 +1,181 @@
+package converter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWritingAnEDL(t *testing.T) {
+	xc := `<xmeml version="5">
+		<sequence>
+			<name>Cut 1</name>
+			<rate>
+				<timebase>30</timebase>
+				<ntsc>TRUE</ntsc>
+			</rate>
+			<timecode>
+				<string>01:00:00;00</string>
+				<displayformat>DF</displayformat>
+				<rate>
+					<timebase>30</timebase>
+					<ntsc>TRUE</ntsc>
+				</rate>
+			</timecode>
+			<marker>
+				<name>Fix colour</name>
+				<in>45</in>
+				<out>-1</out>
+			</marker>
+			<media>
+				<video>
+					<track>
+						<clipitem id="clipitem-1">
+							<name>Shot 1</name>
+							<start>0</start>
+							<end>-1</end>
+							<in>30</in>
+							<out>90</out>
+							<file id="file-1">
+								<name>A001C003.mov</name>
+								<rate>
+									<timebase>30</timebase>
+									<ntsc>TRUE</ntsc>
+								</rate>
+								<timecode>
+									<string>02:00:00;00</string>
+									<displayformat>DF</displayformat>
+									<rate>
+										<timebase>30</timebase>
+										<ntsc>TRUE</ntsc>
+									</rate>
+									<reel>
+										<name>A001</name>
+									</reel>
+								</timecode>
+							</file>
+						</clipitem>
+						<transitionitem>
+							<start>50</start>
+							<end>70</end>
+							<alignment>center</alignment>
+						</transitionitem>
+						<clipitem id="clipitem-2">
+							<name>Shot 2</name>
+							<start>-1</start>
+							<end>120</end>
+							<in>100</in>
+							<out>160</out>
+							<file id="file-2">
+								<name>Interview Take 2.mov</name>
+							</file>
+						</clipitem>
+					</track>
+				</video>
+				<audio>
+					<track>
+						<clipitem id="clipitem-3">
+							<name>Shot 1</name>
+							<start>0</start>
+							<end>60</end>
+							<in>30</in>
+							<out>90</out>
+							<file id="file-1"/>
+						</clipitem>
+					</track>
+				</audio>
+			</media>
+		</sequence>
+	</xmeml>`
+
+	x := ImportRawXEML([]byte(xc))
+
+	var b bytes.Buffer
+	if err := WriteEDL(&b, x.Sequence, EDLOptions{}); err != nil {
+		t.Fatal("EDL could not be written: " + err.Error())
+	}
+
+	e := `TITLE: Cut 1
+FCM: DROP FRAME
+
+001  A001     V     C        02:00:01;00 02:00:02;20 01:00:00;00 01:00:01;20
+* FROM CLIP NAME: Shot 1
+* LOC: 01:00:01;15 RED     Fix colour
+
+002  A001     A     C        02:00:01;00 02:00:03;00 01:00:00;00 01:00:02;00
+* FROM CLIP NAME: Shot 1
+
+003  A001     V     C        02:00:02;20 02:00:02;20 01:00:01;20 01:00:01;20
+003  Intervie V     D    020 00:00:03:00 00:00:05:10 01:00:01;20 01:00:04;00
+* FROM CLIP NAME: Shot 1
+* TO CLIP NAME: Shot 2
+`
+
+	if b.String() != e {
+		t.Error("EDL does not match expectations")
+		t.Log("Expected: " + e)
+		t.Log("Got: " + b.String())
+	}
+}
+
+func TestWritingAnEDLWithSourcesAtOtherRates(t *testing.T) {
+	fifty := Rate{TimeBase: 50}
+	s := &Sequence{
+		Name: "Mixed",
+		Rate: &Rate{TimeBase: 25},
+		Media: &Media{Video: &Video{Tracks: []*Track{{
+			ClipItems: []*ClipItem{
+				{Name: "Slow", Start: 0, End: 50, In: 100, Out: 200, Rate: &fifty, File: &File{
+					Name:     "B001C001.mov",
+					Rate:     &fifty,
+					TimeCode: &TimeCode{Rate: &fifty, TimeCodeString: "10:00:00:00", DisplayFormat: timeCodeNonDropFrame},
+				}},
+			},
+		}}}},
+	}
+
+	var b bytes.Buffer
+	if err := WriteEDL(&b, s, EDLOptions{}); err != nil {
+		t.Fatal("EDL could not be written: " + err.Error())
+	}
+
+	if !strings.Contains(b.String(), "001  B001C001 V     C        10:00:02:00 10:00:04:00 00:00:00:00 00:00:02:00\n") {
+		t.Errorf("source out not converted to the rate of the source:\n%s", b.String())
+	}
+}
+
+func TestWritingAnEDLWithLongReels(t *testing.T) {
+	s := &Sequence{
+		Name: "Long",
+		Rate: &Rate{TimeBase: 25},
+		Media: &Media{
+			Video: &Video{
+				Tracks: []*Track{
+					{
+						ClipItems: []*ClipItem{
+							{Name: "Clip", Start: 0, End: 25, In: 0, Out: 25, File: &File{Name: "Interview Take 2.mov"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var b bytes.Buffer
+	if err := WriteEDL(&b, s, EDLOptions{ReelLength: 32}); err != nil {
+		t.Fatal("EDL could not be written: " + err.Error())
+	}
+
+	if !strings.Contains(b.String(), "001  Interview_Take_2                 V     C        00:00:00:00 00:00:01:00") {
+		t.Error("long reel name not written: " + b.String())
+	}
+
+	if !strings.HasPrefix(b.String(), "TITLE: Long\nFCM: NON-DROP FRAME\n") {
+		t.Error("EDL header does not match expectations")
+	}
+	for _, n := range []int{16, -1} {
+		if err := WriteEDL(&b, s, EDLOptions{ReelLength: n}); err == nil {
+			t.Errorf("reel length of %d not refused", n)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// BoolCase selects how boolean values are written.
+type BoolCase int
+
+const (
+	// BoolUpper writes TRUE and FALSE, as Final Cut Pro 7 and Premiere Pro do.
+	BoolUpper BoolCase = iota
+	// BoolLower writes true and false, as DaVinci Resolve does.
+	BoolLower
+)
+
+// XEMLOptions describes how a raw XEML data tree is written.
+type XEMLOptions struct {
+	// Indent is written once per level of nesting ahead of every element. Elements are not indented when it is
+	// empty.
+	Indent string
+	// BoolCase selects the casing of boolean values. It is ignored when a target application is set.
+	BoolCase BoolCase
+	// Target selects the application the document is written for, see Application.
+	Target Application
+}
+
+var fcpBoolType = reflect.TypeOf(fcpBool(false))
+
+// WriteXEML writes a raw XEML data tree as an XML document, with the XML declaration and doctype the applications
+// write ahead of it.
+func WriteXEML(w io.Writer, x RawXEML, o XEMLOptions) error {
+	if o.Target != AnyApplication {
+		var err error
+		if x, err = retarget(x, o.Target); err != nil {
+			return err
+		}
+
+		o.BoolCase = BoolUpper
+		if o.Target == DaVinciResolve {
+			o.BoolCase = BoolLower
+		}
+	}
+
+	b, err := xml.Marshal(x)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, xml.Header+"<!DOCTYPE xmeml>\n"); err != nil {
+		return err
+	}
+
+	e := xml.NewEncoder(w)
+	e.Indent("", o.Indent)
+
+	// the model type of every open element, nil when it is not part of the model
+	var stack []reflect.Type
+	d := xml.NewDecoder(bytes.NewReader(b))
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch tt := tok.(type) {
+		case xml.StartElement:
+			var t reflect.Type
+			if len(stack) == 0 {
+				t = reflect.TypeOf(x)
+			} else if parent := stack[len(stack)-1]; parent != nil {
+				t = fieldType(parent, tt.Name.Local)
+			}
+			stack = append(stack, t)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 && stack[len(stack)-1] == fcpBoolType {
+				tok = xml.CharData(formatBool(string(tt), o.BoolCase))
+			}
+		}
+
+		if err := e.EncodeToken(tok); err != nil {
+			return err
+		}
+	}
+
+	if err := e.Flush(); err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+
+	return err
+}
+
+// formatBool recases a boolean value written by fcpBool.
+func formatBool(s string, c BoolCase) string {
+	if c == BoolLower {
+		return strings.ToLower(s)
+	}
+
+	return strings.ToUpper(s)
+}
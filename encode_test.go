@@ -0,0 +1,116 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsingValuesRegardlessOfCase(t *testing.T) {
+	xc := `<xmeml version="5">
+		<clip>
+			<name>Case</name>
+			<enabled>True</enabled>
+			<ismasterclip>false</ismasterclip>
+			<anamorphic>TRUE</anamorphic>
+			<alphatype>Straight</alphatype>
+			<compositemode>LUMAMASK</compositemode>
+			<rate>
+				<timebase>30</timebase>
+				<ntsc>tRuE</ntsc>
+			</rate>
+		</clip>
+	</xmeml>`
+
+	x, err := ParseRawXEML([]byte(xc))
+	if err != nil {
+		t.Fatal("mixed case values could not be imported: " + err.Error())
+	}
+
+	c := x.Clip
+	if !c.Enabled || c.IsMasterClip || !c.Anamorphic || !c.Rate.NTSC {
+		t.Error("booleans not imported regardless of case")
+	}
+
+	if c.AlphaType != "straight" || c.CompositeMode != compositeLumaMask {
+		t.Errorf("enumerations not imported as listed: %s %s", c.AlphaType, c.CompositeMode)
+	}
+	var tr Track
+	if err := xml.Unmarshal([]byte(`<track><enabled>1</enabled><locked>0</locked></track>`), &tr); err != nil {
+		t.Fatal("booleans written as 1 and 0 could not be unmarshalled: " + err.Error())
+	}
+
+	if !tr.Enabled || tr.Locked {
+		t.Error("booleans written as 1 and 0 not imported")
+	}
+}
+
+func TestRejectingInvalidValues(t *testing.T) {
+	xc := `<xmeml version="5"><clip><enabled>yes</enabled></clip></xmeml>`
+
+	_, err := ParseRawXEML([]byte(xc))
+
+	var ie *ImportError
+	if !errors.As(err, &ie) || ie.Kind != ImportTypeMismatch || ie.Path != "xmeml/clip/enabled" {
+		t.Errorf("invalid boolean not rejected: %v", err)
+	}
+
+	var c Clip
+	if err := xml.Unmarshal([]byte(`<clip><alphatype>opaque</alphatype></clip>`), &c); err == nil {
+		t.Error("invalid enumeration not rejected when unmarshalled")
+	}
+
+	var tr Track
+	if err := xml.Unmarshal([]byte(`<track><locked>no</locked></track>`), &tr); err == nil {
+		t.Error("invalid boolean not rejected when unmarshalled")
+	}
+}
+
+func TestWritingBoolCase(t *testing.T) {
+	s, err := ioutil.ReadFile("export-examples/resolve-export.xml")
+	if err != nil {
+		t.Fatal("example export could not be read: " + err.Error())
+	}
+
+	x := ImportRawXEML(s)
+	x.Sequence.Name = "TRUE"
+
+	var upper, lower bytes.Buffer
+	if err := WriteXEML(&upper, x, XEMLOptions{Indent: "\t"}); err != nil {
+		t.Fatal("raw XEML could not be written: " + err.Error())
+	}
+	if err := WriteXEML(&lower, x, XEMLOptions{Indent: "\t", BoolCase: BoolLower}); err != nil {
+		t.Fatal("raw XEML could not be written: " + err.Error())
+	}
+
+	if !strings.HasPrefix(upper.String(), xml.Header+"<!DOCTYPE xmeml>\n<xmeml version=\"5\">\n\t<sequence") {
+		t.Error("document header not written")
+	}
+
+	if !strings.Contains(upper.String(), "<enabled>TRUE</enabled>") || strings.Contains(upper.String(), ">true<") {
+		t.Error("booleans not written in upper case")
+	}
+
+	if !strings.Contains(lower.String(), "<enabled>true</enabled>") || strings.Contains(lower.String(), ">FALSE<") {
+		t.Error("booleans not written in lower case")
+	}
+
+	if !strings.Contains(lower.String(), "<name>TRUE</name>") {
+		t.Error("text that is not a boolean recased")
+	}
+
+	for _, b := range []*bytes.Buffer{&upper, &lower} {
+		y, err := ParseRawXEML(b.Bytes())
+		if err != nil {
+			t.Fatal("written raw XEML could not be imported: " + err.Error())
+		}
+
+		if !reflect.DeepEqual(x, y) {
+			t.Error("raw XEML not preserved when written")
+		}
+	}
+}
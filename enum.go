@@ -0,0 +1,169 @@
+package converter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Section: Enumerations
+
+var alphaTypes = []string{"none", "straight", "white", "black"}
+
+func (alphaType) enumValues() []string { return alphaTypes }
+
+func (a *alphaType) UnmarshalText(b []byte) error { return unmarshalEnum(b, (*string)(a), alphaTypes) }
+
+var compositeModes = []string{
+	compositeNormal,
+	compositeAdd,
+	compositeSubtract,
+	compositeDifference,
+	compositeMultiply,
+	compositeScreen,
+	compositeTexturize,
+	compositeHardLight,
+	compositeSoftLight,
+	compositeDarken,
+	compositeLighten,
+	compositeMask,
+	compositeLumaMask,
+}
+
+func (compositeMode) enumValues() []string { return compositeModes }
+
+func (compositeMode) openEnum() {}
+
+func (c *compositeMode) UnmarshalText(b []byte) error {
+	return unmarshalOpenEnum(b, (*string)(c), compositeModes)
+}
+
+var displayFormats = []string{timeCodeDropFrame, timeCodeNonDropFrame}
+
+func (displayFormat) enumValues() []string { return displayFormats }
+
+func (d *displayFormat) UnmarshalText(b []byte) error {
+	return unmarshalEnum(b, (*string)(d), displayFormats)
+}
+
+var fieldDominances = []string{"none", "upper", "lower"}
+
+func (fieldDominance) enumValues() []string { return fieldDominances }
+
+func (f *fieldDominance) UnmarshalText(b []byte) error {
+	return unmarshalEnum(b, (*string)(f), fieldDominances)
+}
+
+var mediaTypes = []string{"video", "audio"}
+
+func (mediaType) enumValues() []string { return mediaTypes }
+
+func (m *mediaType) UnmarshalText(b []byte) error { return unmarshalEnum(b, (*string)(m), mediaTypes) }
+
+var pixelAspectRatios = []string{
+	"square",
+	"NTSC-601",
+	"PAL-601",
+	"HD-(960x720)",
+	"HD-(1280x1080)",
+	"HD-(1440x1080)",
+}
+
+func (pixelAspectRatio) enumValues() []string { return pixelAspectRatios }
+
+func (pixelAspectRatio) openEnum() {}
+
+func (p *pixelAspectRatio) UnmarshalText(b []byte) error {
+	return unmarshalOpenEnum(b, (*string)(p), pixelAspectRatios)
+}
+
+const (
+	alignmentStart      = "start"
+	alignmentCenter     = "center"
+	alignmentEnd        = "end"
+	alignmentStartBlack = "start-black"
+	alignmentEndBlack   = "end-black"
+)
+
+var alignments = []string{alignmentStart, alignmentCenter, alignmentEnd, alignmentStartBlack, alignmentEndBlack}
+
+func (alignment) enumValues() []string { return alignments }
+
+func (a *alignment) UnmarshalText(b []byte) error { return unmarshalEnum(b, (*string)(a), alignments) }
+
+// parseEnum matches s against the values of an enumeration regardless of case, returning the value as it is
+// listed.
+func parseEnum(s string, values []string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", nil
+	}
+
+	for _, v := range values {
+		if strings.EqualFold(s, v) {
+			return v, nil
+		}
+	}
+
+	return "", fmt.Errorf("%q is not one of %s", s, strings.Join(values, ", "))
+}
+
+func unmarshalEnum(b []byte, dst *string, values []string) error {
+	v, err := parseEnum(string(b), values)
+	if err != nil {
+		return err
+	}
+
+	*dst = v
+
+	return nil
+}
+
+// unmarshalOpenEnum reads a value of an open enumeration, see openEnum, keeping values that are not listed as they
+// are.
+func unmarshalOpenEnum(b []byte, dst *string, values []string) error {
+	v, err := parseEnum(string(b), values)
+	if err != nil {
+		v = strings.TrimSpace(string(b))
+	}
+
+	*dst = v
+
+	return nil
+}
+
+// Section: Booleans
+
+// fcpBool is a boolean value. Final Cut Pro 7 and Premiere Pro write TRUE and FALSE while DaVinci Resolve writes
+// true and false, so either is read regardless of case, along with the other values strconv.ParseBool reads, e.g.
+// 1 and 0. Values are written as true and false like a bool, see BoolCase for writing them in another case.
+type fcpBool bool
+
+const (
+	fcpTrue  = "TRUE"
+	fcpFalse = "FALSE"
+)
+
+// MarshalText implements encoding.TextMarshaler.
+func (b fcpBool) MarshalText() ([]byte, error) {
+	return []byte(strconv.FormatBool(bool(b))), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. An empty value is false.
+func (b *fcpBool) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	switch {
+	case s == "" || strings.EqualFold(s, fcpFalse):
+		*b = false
+	case strings.EqualFold(s, fcpTrue):
+		*b = true
+	default:
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("%q is not %s or %s", s, fcpTrue, fcpFalse)
+		}
+		*b = fcpBool(v)
+	}
+
+	return nil
+}
@@ -0,0 +1,944 @@
+package converter
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	fcpxmlVersion       = "1.9"
+	fcpxmlOpacityID     = "opacity"
+	fcpxmlMotionID      = "basic"
+	fcpxmlDissolveName  = "Cross Dissolve"
+	fcpxmlDissolveUID   = "FxPlug:4731E73A-8DAC-4113-9A30-AE85B1761265"
+	fcpxmlDefaultWidth  = 1920
+	fcpxmlDefaultHeight = 1080
+	fcpxmlSourceAudio   = "audio"
+	fcpxmlSourceVideo   = "video"
+	fcpxmlOriginalMedia = "original-media"
+	fcpxmlDefaultLayout = "stereo"
+	fcpxmlDefaultRate   = "48k"
+)
+
+// Section: FCPXML Elements
+
+// fcpxDocument describes the root of an FCPXML document.
+type fcpxDocument struct {
+	XMLName   xml.Name       `xml:"fcpxml"`
+	Version   string         `xml:"version,attr"`
+	Resources *fcpxResources `xml:"resources"`
+	Library   *fcpxLibrary   `xml:"library,omitempty"`
+	Events    []*fcpxEvent   `xml:"event,omitempty"`
+	Projects  []*fcpxProject `xml:"project,omitempty"`
+}
+
+// fcpxResources describes the formats, media and effects referenced by a document.
+type fcpxResources struct {
+	Formats []*fcpxFormat `xml:"format,omitempty"`
+	Assets  []*fcpxAsset  `xml:"asset,omitempty"`
+	Media   []*fcpxMedia  `xml:"media,omitempty"`
+	Effects []*fcpxEffect `xml:"effect,omitempty"`
+}
+
+// fcpxFormat describes a video format.
+type fcpxFormat struct {
+	ID            string `xml:"id,attr"`
+	Name          string `xml:"name,attr,omitempty"`
+	FrameDuration string `xml:"frameDuration,attr,omitempty"`
+	Width         int    `xml:"width,attr,omitempty"`
+	Height        int    `xml:"height,attr,omitempty"`
+}
+
+// fcpxAsset describes a media file. Documents before version 1.9 name the file with a src attribute instead of
+// a media-rep.
+type fcpxAsset struct {
+	ID            string        `xml:"id,attr"`
+	Name          string        `xml:"name,attr,omitempty"`
+	Start         string        `xml:"start,attr,omitempty"`
+	Duration      string        `xml:"duration,attr,omitempty"`
+	HasVideo      string        `xml:"hasVideo,attr,omitempty"`
+	Format        string        `xml:"format,attr,omitempty"`
+	HasAudio      string        `xml:"hasAudio,attr,omitempty"`
+	AudioSources  string        `xml:"audioSources,attr,omitempty"`
+	AudioChannels string        `xml:"audioChannels,attr,omitempty"`
+	AudioRate     string        `xml:"audioRate,attr,omitempty"`
+	Src           string        `xml:"src,attr,omitempty"`
+	MediaRep      *fcpxMediaRep `xml:"media-rep,omitempty"`
+}
+
+// fcpxMediaRep describes the location of a media file.
+type fcpxMediaRep struct {
+	Kind string `xml:"kind,attr"`
+	Src  string `xml:"src,attr"`
+}
+
+// fcpxMedia describes a compound clip.
+type fcpxMedia struct {
+	ID       string        `xml:"id,attr"`
+	Name     string        `xml:"name,attr,omitempty"`
+	Sequence *fcpxSequence `xml:"sequence"`
+}
+
+// fcpxEffect describes a transition, title or filter effect.
+type fcpxEffect struct {
+	ID   string `xml:"id,attr"`
+	Name string `xml:"name,attr,omitempty"`
+	UID  string `xml:"uid,attr,omitempty"`
+}
+
+// fcpxLibrary describes a library of events.
+type fcpxLibrary struct {
+	Location string       `xml:"location,attr,omitempty"`
+	Events   []*fcpxEvent `xml:"event"`
+}
+
+// fcpxEvent describes an event of projects.
+type fcpxEvent struct {
+	Name     string         `xml:"name,attr"`
+	Projects []*fcpxProject `xml:"project"`
+}
+
+// fcpxProject describes a project and its sequence.
+type fcpxProject struct {
+	Name     string        `xml:"name,attr"`
+	Sequence *fcpxSequence `xml:"sequence"`
+}
+
+// fcpxSequence describes the timeline of a project or compound clip.
+type fcpxSequence struct {
+	Format      string    `xml:"format,attr"`
+	Duration    string    `xml:"duration,attr,omitempty"`
+	TCStart     string    `xml:"tcStart,attr,omitempty"`
+	TCFormat    string    `xml:"tcFormat,attr,omitempty"`
+	AudioLayout string    `xml:"audioLayout,attr,omitempty"`
+	AudioRate   string    `xml:"audioRate,attr,omitempty"`
+	Spine       *fcpxClip `xml:"spine"`
+}
+
+// fcpxClip describes any story element by its element name: clips, gaps, transitions, storylines and markers.
+// Anchored items and markers are its children.
+type fcpxClip struct {
+	XMLName   xml.Name
+	Ref       string         `xml:"ref,attr,omitempty"`
+	Lane      string         `xml:"lane,attr,omitempty"`
+	Offset    string         `xml:"offset,attr,omitempty"`
+	Name      string         `xml:"name,attr,omitempty"`
+	Start     string         `xml:"start,attr,omitempty"`
+	Duration  string         `xml:"duration,attr,omitempty"`
+	Enabled   string         `xml:"enabled,attr,omitempty"`
+	SrcEnable string         `xml:"srcEnable,attr,omitempty"`
+	Value     string         `xml:"value,attr,omitempty"`
+	Note      string         `xml:"note,attr,omitempty"`
+	Transform *fcpxTransform `xml:"adjust-transform,omitempty"`
+	Blend     *fcpxBlend     `xml:"adjust-blend,omitempty"`
+	Children  []*fcpxClip    `xml:",any"`
+}
+
+// fcpxTransform describes the position, scale and rotation of a clip. Positions are percentages of the frame
+// height from the center, with y pointing up.
+type fcpxTransform struct {
+	Position string `xml:"position,attr,omitempty"`
+	Scale    string `xml:"scale,attr,omitempty"`
+	Rotation string `xml:"rotation,attr,omitempty"`
+}
+
+// fcpxBlend describes the opacity of a clip.
+type fcpxBlend struct {
+	Amount string `xml:"amount,attr,omitempty"`
+}
+
+// Section: Rational Time
+
+// rational describes an FCPXML time, an exact fraction of a second.
+type rational struct {
+	num int64
+	den int64
+}
+
+// newRational reduces a fraction of a second.
+func newRational(num int64, den int64) rational {
+	if den < 0 {
+		num, den = -num, -den
+	}
+	if den == 0 {
+		return rational{0, 1}
+	}
+
+	a, b := num, den
+	if a < 0 {
+		a = -a
+	}
+	for b != 0 {
+		a, b = b, a%b
+	}
+	if a == 0 {
+		return rational{0, 1}
+	}
+
+	return rational{num / a, den / a}
+}
+
+// framesToRational converts a frame count at a rate to an exact time.
+func framesToRational(frames int, r Rate) rational {
+	num, den := r.FrameDuration()
+
+	return newRational(int64(frames)*num, den)
+}
+
+func (q rational) add(o rational) rational {
+	return newRational(q.num*o.den+o.num*q.den, q.den*o.den)
+}
+
+func (q rational) sub(o rational) rational {
+	return q.add(rational{-o.num, o.den})
+}
+
+// frames converts a time to the nearest frame count at a rate.
+func (q rational) frames(r Rate) int {
+	num, den := r.FrameRate()
+
+	return roundDiv(q.num*num, q.den*den)
+}
+
+// parseRational reads an FCPXML time such as "5s" or "1001/24000s". An empty time is zero.
+func parseRational(s string) (rational, error) {
+	if s == "" {
+		return rational{0, 1}, nil
+	}
+	if !strings.HasSuffix(s, "s") {
+		return rational{}, fmt.Errorf("time %q is not written in seconds", s)
+	}
+
+	parts := strings.SplitN(strings.TrimSuffix(s, "s"), "/", 2)
+	num, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return rational{}, fmt.Errorf("time %q is not a fraction of seconds", s)
+	}
+
+	den := int64(1)
+	if len(parts) == 2 {
+		den, err = strconv.ParseInt(parts[1], 10, 64)
+		if err != nil || den <= 0 {
+			return rational{}, fmt.Errorf("time %q is not a fraction of seconds", s)
+		}
+	}
+
+	return newRational(num, den), nil
+}
+
+// frameRate converts the duration of a frame to a rate, e.g. 1001/24000s to 24 fps NTSC.
+func (q rational) frameRate() (Rate, error) {
+	if q.num <= 0 {
+		return Rate{}, fmt.Errorf("frame duration %s is not positive", q)
+	}
+
+	return Rate{TimeBase: roundDiv(q.den, q.num), NTSC: q.den%q.num != 0}, nil
+}
+
+// String formats a time the way FCPXML writes it, e.g. "0s", "5s" or "1001/24000s".
+func (q rational) String() string {
+	if q.den == 1 {
+		return strconv.FormatInt(q.num, 10) + "s"
+	}
+
+	return fmt.Sprintf("%d/%ds", q.num, q.den)
+}
+
+// Section: FCPXML Export
+
+// FCPXMLOptions describes how a sequence is written as FCPXML.
+type FCPXMLOptions struct {
+	// Version is the FCPXML version written, 1.9 or later, defaulting to 1.9.
+	Version string
+	// EventName names the event holding the project, defaulting to the name of the sequence.
+	EventName string
+	// ProjectName names the project, defaulting to the name of the sequence.
+	ProjectName string
+}
+
+// WriteFCPXML writes a sequence as an FCPXML project. Files become assets, video track 1 becomes the spine with
+// its transitions, clip items of the other video tracks become connected clips in lanes above it, and audio clip
+// items that do not repeat a video clip item become connected clips in lanes below it. Transitions of connected
+// clips are not written. Nested sequences become compound clips. Opacity and basic motion filters become blend and
+// transform adjustments.
+func WriteFCPXML(w io.Writer, s *Sequence, o FCPXMLOptions) error {
+	if s.Rate == nil || s.Rate.TimeBase <= 0 {
+		return errors.New("sequence has no rate")
+	}
+
+	if o.Version == "" {
+		o.Version = fcpxmlVersion
+	}
+	var major, minor int
+	if _, err := fmt.Sscanf(o.Version, "%d.%d", &major, &minor); err != nil || major != 1 || minor < 9 {
+		return fmt.Errorf("FCPXML version %q is not supported, 1.9 or later is required", o.Version)
+	}
+	if o.EventName == "" {
+		o.EventName = string(s.Name)
+	}
+	if o.ProjectName == "" {
+		o.ProjectName = string(s.Name)
+	}
+
+	x := &RawXEML{Sequence: s}
+	e := &fcpxWriter{
+		media:     x.MediaRegistry(),
+		resources: &fcpxResources{},
+		formats:   map[string]*fcpxFormat{},
+		assets:    map[*File]*fcpxAssetRef{},
+		sequences: map[string]*Sequence{},
+		compounds: map[*Sequence]*fcpxCompound{},
+	}
+	e.indexSequences(s)
+
+	seq := e.sequence(s, *s.Rate)
+	doc := &fcpxDocument{
+		Version:   o.Version,
+		Resources: e.resources,
+		Library: &fcpxLibrary{
+			Events: []*fcpxEvent{{
+				Name:     o.EventName,
+				Projects: []*fcpxProject{{Name: o.ProjectName, Sequence: seq}},
+			}},
+		},
+	}
+
+	if _, err := io.WriteString(w, xml.Header+"<!DOCTYPE fcpxml>\n\n"); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "    ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
+
+type fcpxWriter struct {
+	media     *MediaRegistry
+	resources *fcpxResources
+	formats   map[string]*fcpxFormat
+	assets    map[*File]*fcpxAssetRef
+	sequences map[string]*Sequence
+	compounds map[*Sequence]*fcpxCompound
+	dissolve  *fcpxEffect
+	ids       int
+}
+
+// fcpxAssetRef describes the asset written for a file and the time its media starts at.
+type fcpxAssetRef struct {
+	asset *fcpxAsset
+	start rational
+}
+
+// fcpxCompound describes the media written for a nested sequence and the time its timeline starts at.
+type fcpxCompound struct {
+	media *fcpxMedia
+	start rational
+}
+
+// fcpxItem describes an element of the spine with its record range and the local time at its record start.
+type fcpxItem struct {
+	clip    *fcpxClip
+	start   int
+	end     int
+	local   rational
+	anchors []*fcpxClip
+	markers []*fcpxClip
+}
+
+// fcpxSource describes the file and source range of a clip item, used to tell audio that repeats video apart.
+type fcpxSource struct {
+	file  *File
+	start int
+	end   int
+	in    int
+}
+
+func (e *fcpxWriter) id() string {
+	e.ids++
+
+	return fmt.Sprintf("r%d", e.ids)
+}
+
+// indexSequences finds the definitions of nested sequences, which may be referenced by id before they are defined.
+func (e *fcpxWriter) indexSequences(s *Sequence) {
+	if s.Media == nil {
+		return
+	}
+
+	for _, t := range s.Media.tracks() {
+		for _, ci := range t.ClipItems {
+			ns := ci.Sequence
+			if ns == nil || ns.Media == nil {
+				continue
+			}
+			if _, ok := e.sequences[ns.ID]; ns.ID != "" && !ok {
+				e.sequences[ns.ID] = ns
+			}
+			e.indexSequences(ns)
+		}
+	}
+}
+
+// format finds or adds the format of a frame size at a rate.
+func (e *fcpxWriter) format(width int, height int, r Rate) string {
+	if width <= 0 || height <= 0 {
+		width, height = fcpxmlDefaultWidth, fcpxmlDefaultHeight
+	}
+
+	frameDuration := framesToRational(1, r).String()
+	key := fmt.Sprintf("%s %dx%d", frameDuration, width, height)
+	if f, ok := e.formats[key]; ok {
+		return f.ID
+	}
+
+	f := &fcpxFormat{
+		ID:            e.id(),
+		Name:          fcpxFormatName(width, height, r),
+		FrameDuration: frameDuration,
+		Width:         width,
+		Height:        height,
+	}
+	e.formats[key] = f
+	e.resources.Formats = append(e.resources.Formats, f)
+
+	return f.ID
+}
+
+// fcpxFormatName names the standard video formats Final Cut Pro knows, e.g. FFVideoFormat1080p2398.
+func fcpxFormatName(width int, height int, r Rate) string {
+	var size string
+	switch {
+	case width == 1920 && height == 1080, width == 1280 && height == 720:
+		size = strconv.Itoa(height)
+	case width == 3840 && height == 2160, width == 4096 && height == 2160:
+		size = fmt.Sprintf("%dx%d", width, height)
+	default:
+		return ""
+	}
+
+	rate := strconv.Itoa(r.TimeBase)
+	if r.NTSC {
+		rate = strings.Replace(fmt.Sprintf("%.2f", r.FPS()), ".", "", 1)
+	}
+
+	return fmt.Sprintf("FFVideoFormat%sp%s", size, rate)
+}
+
+// sampleSize is the frame size of video media, if it is described.
+func sampleSize(v *Video) (int, int) {
+	switch {
+	case v == nil:
+		return 0, 0
+	case v.Format != nil && v.Format.SampleCharacteristics != nil:
+		sc := v.Format.SampleCharacteristics
+		return int(sc.Width), int(sc.Height)
+	case v.SampleCharacteristics != nil:
+		sc := v.SampleCharacteristics
+		return int(sc.Width), int(sc.Height)
+	}
+
+	return 0, 0
+}
+
+// file is the definition of a file, or the file itself when it is never defined.
+func (e *fcpxWriter) file(f *File) *File {
+	if c := e.media.Canonical(f); c != nil {
+		return c
+	}
+
+	return f
+}
+
+// asset finds or adds the asset of a file.
+func (e *fcpxWriter) asset(f *File, rate Rate) *fcpxAssetRef {
+	f = e.file(f)
+	if f == nil {
+		return nil
+	}
+	if a, ok := e.assets[f]; ok {
+		return a
+	}
+
+	if f.Rate != nil && f.Rate.TimeBase > 0 {
+		rate = *f.Rate
+	}
+
+	ref := &fcpxAssetRef{start: rational{0, 1}}
+	if tc := f.TimeCode; tc != nil {
+		tcRate := rate
+		if tc.Rate != nil && tc.Rate.TimeBase > 0 {
+			tcRate = *tc.Rate
+		}
+		if frames, err := tc.Frames(); err == nil {
+			ref.start = framesToRational(frames, tcRate)
+		}
+	}
+
+	a := &fcpxAsset{
+		ID:       e.id(),
+		Name:     string(f.Name),
+		Start:    ref.start.String(),
+		Duration: framesToRational(int(f.Duration), rate).String(),
+		HasVideo: "1",
+		HasAudio: "1",
+	}
+	if f.Media != nil && f.Media.Video == nil {
+		a.HasVideo = ""
+	}
+	if f.Media != nil && f.Media.Audio == nil {
+		a.HasAudio = ""
+	}
+	if a.HasVideo != "" {
+		var width, height int
+		if f.Media != nil {
+			width, height = sampleSize(f.Media.Video)
+		}
+		a.Format = e.format(width, height, rate)
+	}
+	if a.HasAudio != "" {
+		a.AudioSources = "1"
+		if f.Media != nil && f.Media.Audio != nil && f.Media.Audio.ChannelCount > 0 {
+			a.AudioChannels = strconv.Itoa(int(f.Media.Audio.ChannelCount))
+		}
+	}
+	if f.PathURL != "" {
+		a.MediaRep = &fcpxMediaRep{Kind: fcpxmlOriginalMedia, Src: fcpxFileURL(string(f.PathURL))}
+	}
+
+	ref.asset = a
+	e.assets[f] = ref
+	e.resources.Assets = append(e.resources.Assets, a)
+
+	return ref
+}
+
+// fcpxFileURL drops the localhost authority some applications write in file URLs.
+func fcpxFileURL(u string) string {
+	if strings.HasPrefix(u, "file://localhost/") {
+		return "file:///" + strings.TrimPrefix(u, "file://localhost/")
+	}
+
+	return u
+}
+
+// compound finds or adds the media of a nested sequence, resolving references to sequences defined elsewhere.
+func (e *fcpxWriter) compound(s *Sequence, rate Rate) *fcpxCompound {
+	if s.Media == nil && s.ID != "" {
+		if d, ok := e.sequences[s.ID]; ok {
+			s = d
+		}
+	}
+	if s.Media == nil {
+		return nil
+	}
+	if c, ok := e.compounds[s]; ok {
+		return c
+	}
+
+	if s.Rate != nil && s.Rate.TimeBase > 0 {
+		rate = *s.Rate
+	}
+
+	c := &fcpxCompound{media: &fcpxMedia{ID: e.id(), Name: string(s.Name)}}
+	e.compounds[s] = c
+	c.media.Sequence = e.sequence(s, rate)
+	c.start = e.timeCodeStart(s.TimeCode, rate)
+	e.resources.Media = append(e.resources.Media, c.media)
+
+	return c
+}
+
+// dissolveEffect adds the cross dissolve effect transitions refer to.
+func (e *fcpxWriter) dissolveEffect() *fcpxEffect {
+	if e.dissolve == nil {
+		e.dissolve = &fcpxEffect{ID: e.id(), Name: fcpxmlDissolveName, UID: fcpxmlDissolveUID}
+		e.resources.Effects = append(e.resources.Effects, e.dissolve)
+	}
+
+	return e.dissolve
+}
+
+func (e *fcpxWriter) timeCodeStart(tc *TimeCode, rate Rate) rational {
+	if tc == nil {
+		return rational{0, 1}
+	}
+
+	frames, err := tc.Frames()
+	if err != nil {
+		frames = int(tc.Frame)
+	}
+	if tc.Rate != nil && tc.Rate.TimeBase > 0 {
+		rate = *tc.Rate
+	}
+
+	return framesToRational(frames, rate)
+}
+
+// sequence writes the timeline of a sequence.
+func (e *fcpxWriter) sequence(s *Sequence, rate Rate) *fcpxSequence {
+	origin := e.timeCodeStart(s.TimeCode, rate)
+
+	var width, height int
+	var video, audio []*Track
+	if s.Media != nil {
+		width, height = sampleSize(s.Media.Video)
+		if s.Media.Video != nil {
+			video = s.Media.Video.Tracks
+		}
+		if s.Media.Audio != nil {
+			audio = s.Media.Audio.Tracks
+		}
+	}
+
+	seq := &fcpxSequence{
+		Format:      e.format(width, height, rate),
+		TCStart:     origin.String(),
+		TCFormat:    timeCodeNonDropFrame,
+		AudioLayout: fcpxmlDefaultLayout,
+		AudioRate:   fcpxmlDefaultRate,
+	}
+	if s.TimeCode != nil && s.TimeCode.DropFrame() && rate.SupportsDropFrame() {
+		seq.TCFormat = timeCodeDropFrame
+	}
+
+	length := int(s.Duration)
+	for _, t := range append(append([]*Track{}, video...), audio...) {
+		edits, _ := editTrack(t)
+		for _, c := range edits {
+			if c.end > length {
+				length = c.end
+			}
+		}
+	}
+
+	var spine []*fcpxItem
+	var transitions []*fcpxItem
+	sources := map[fcpxSource]*fcpxClip{}
+	if len(video) > 0 {
+		edits, trs := editTrack(video[0])
+		for _, c := range edits {
+			clip, local := e.clip(c, rate, sources)
+			if clip == nil {
+				continue
+			}
+			spine = append(spine, &fcpxItem{clip: clip, start: c.start, end: c.end, local: local})
+		}
+		for _, tr := range trs {
+			transitions = append(transitions, &fcpxItem{clip: e.transition(tr, rate), start: tr.start, end: tr.end})
+		}
+	}
+	spine = fcpxFillGaps(spine, length, rate)
+
+	for i, t := range video {
+		if i == 0 {
+			continue
+		}
+
+		edits, _ := editTrack(t)
+		for _, c := range edits {
+			if clip, _ := e.clip(c, rate, sources); clip != nil {
+				clip.Lane = strconv.Itoa(i)
+				fcpxAnchor(spine, clip, c.start, rate)
+			}
+		}
+	}
+
+	heard := map[fcpxSource]bool{}
+	for i, t := range audio {
+		edits, _ := editTrack(t)
+		for _, c := range edits {
+			key := fcpxSource{e.file(c.item.File), c.start, c.end, int(c.item.In)}
+			if sources[key] != nil {
+				heard[key] = true
+				continue
+			}
+			if clip, _ := e.clip(c, rate, nil); clip != nil {
+				clip.Lane = strconv.Itoa(-i - 1)
+				if clip.XMLName.Local == "asset-clip" {
+					clip.SrcEnable = fcpxmlSourceAudio
+					clip.Transform, clip.Blend = nil, nil
+				}
+				fcpxAnchor(spine, clip, c.start, rate)
+			}
+		}
+	}
+
+	// video whose audio is not used in the sequence is muted
+	for key, clip := range sources {
+		if a := e.assets[key.file]; !heard[key] && a != nil && a.asset.HasAudio != "" {
+			clip.SrcEnable = fcpxmlSourceVideo
+		}
+	}
+
+	for _, m := range s.Markers {
+		if it := fcpxContaining(spine, int(m.In)); it != nil {
+			local := it.local.add(framesToRational(int(m.In)-it.start, rate))
+			it.markers = append(it.markers, fcpxMarker(m, local, rate))
+		}
+	}
+
+	items := append(spine, transitions...)
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].start != items[j].start {
+			return items[i].start < items[j].start
+		}
+
+		return items[i].clip.XMLName.Local == "transition" && items[j].clip.XMLName.Local != "transition"
+	})
+
+	seq.Spine = &fcpxClip{XMLName: xml.Name{Local: "spine"}}
+	for _, it := range items {
+		it.clip.Offset = origin.add(framesToRational(it.start, rate)).String()
+		// anchored items precede markers
+		children := append(it.anchors, it.clip.Children...)
+		it.clip.Children = append(children, it.markers...)
+		seq.Spine.Children = append(seq.Spine.Children, it.clip)
+	}
+	seq.Duration = framesToRational(length, rate).String()
+
+	return seq
+}
+
+// clip writes a clip item as an asset clip, or a compound clip when it nests a sequence, returning the local time at
+// its record start. Clip items without media are not written. Video sources are recorded in sources.
+func (e *fcpxWriter) clip(c *trackEdit, rate Rate, sources map[fcpxSource]*fcpxClip) (*fcpxClip, rational) {
+	ci := c.item
+	srcRate := rate
+	if ci.Rate != nil && ci.Rate.TimeBase > 0 {
+		srcRate = *ci.Rate
+	}
+
+	clip := &fcpxClip{
+		Name:     string(ci.Name),
+		Duration: framesToRational(c.end-c.start, rate).String(),
+	}
+
+	var origin rational
+	switch {
+	case ci.Sequence != nil:
+		cmp := e.compound(ci.Sequence, srcRate)
+		if cmp == nil {
+			return nil, rational{}
+		}
+		clip.XMLName.Local = "ref-clip"
+		clip.Ref = cmp.media.ID
+		origin = cmp.start
+	case ci.File != nil:
+		a := e.asset(ci.File, srcRate)
+		if a == nil {
+			return nil, rational{}
+		}
+		clip.XMLName.Local = "asset-clip"
+		clip.Ref = a.asset.ID
+		origin = a.start
+		if sources != nil {
+			sources[fcpxSource{e.file(ci.File), c.start, c.end, int(ci.In)}] = clip
+		}
+	default:
+		return nil, rational{}
+	}
+
+	local := origin.add(framesToRational(int(ci.In), srcRate))
+	clip.Start = local.String()
+
+	for _, f := range ci.Filters {
+		if f.Effect == nil {
+			continue
+		}
+
+		switch string(f.Effect.EffectID) {
+		case fcpxmlOpacityID:
+			clip.Blend = fcpxOpacity(f.Effect)
+		case fcpxmlMotionID:
+			width, height := e.frameSize(ci)
+			clip.Transform = fcpxMotion(f.Effect, width, height)
+		}
+	}
+
+	for _, m := range ci.Markers {
+		clip.Children = append(clip.Children, fcpxMarker(m, origin.add(framesToRational(int(m.In), srcRate)), srcRate))
+	}
+
+	return clip, local
+}
+
+// frameSize is the frame size of the media of a clip item, used to convert positions.
+func (e *fcpxWriter) frameSize(ci *ClipItem) (int, int) {
+	f := e.file(ci.File)
+	if f == nil || f.Media == nil {
+		return fcpxmlDefaultWidth, fcpxmlDefaultHeight
+	}
+
+	width, height := sampleSize(f.Media.Video)
+	if width <= 0 || height <= 0 {
+		return fcpxmlDefaultWidth, fcpxmlDefaultHeight
+	}
+
+	return width, height
+}
+
+// transition writes a transition as a cross dissolve, keeping its name.
+func (e *fcpxWriter) transition(tr *trackTransition, rate Rate) *fcpxClip {
+	name := fcpxmlDissolveName
+	if tr.item.Effect != nil && tr.item.Effect.Name != "" {
+		name = string(tr.item.Effect.Name)
+	}
+
+	effect := e.dissolveEffect()
+
+	return &fcpxClip{
+		XMLName:  xml.Name{Local: "transition"},
+		Name:     name,
+		Duration: framesToRational(tr.end-tr.start, rate).String(),
+		Children: []*fcpxClip{{
+			XMLName: xml.Name{Local: "filter-video"},
+			Ref:     effect.ID,
+			Name:    effect.Name,
+		}},
+	}
+}
+
+// fcpxFillGaps fills the spine with gaps between clips and up to the length of the sequence, so that every
+// connected clip and marker has an element to attach to.
+func fcpxFillGaps(items []*fcpxItem, length int, rate Rate) []*fcpxItem {
+	var spine []*fcpxItem
+	cursor := 0
+	gap := func(start, end int) {
+		local := framesToRational(start, rate)
+		spine = append(spine, &fcpxItem{
+			clip: &fcpxClip{
+				XMLName:  xml.Name{Local: "gap"},
+				Name:     "Gap",
+				Start:    local.String(),
+				Duration: framesToRational(end-start, rate).String(),
+			},
+			start: start,
+			end:   end,
+			local: local,
+		})
+	}
+
+	for _, it := range items {
+		if it.start > cursor {
+			gap(cursor, it.start)
+		}
+		spine = append(spine, it)
+		if it.end > cursor {
+			cursor = it.end
+		}
+	}
+	if length > cursor {
+		gap(cursor, length)
+	}
+
+	return spine
+}
+
+// fcpxContaining finds the spine element playing at a record frame, or the last element after the end.
+func fcpxContaining(spine []*fcpxItem, frame int) *fcpxItem {
+	for _, it := range spine {
+		if it.start <= frame && frame < it.end {
+			return it
+		}
+	}
+
+	if len(spine) > 0 {
+		return spine[len(spine)-1]
+	}
+
+	return nil
+}
+
+// fcpxAnchor connects a clip to the spine element playing at its record start, offset in the element's local time.
+func fcpxAnchor(spine []*fcpxItem, clip *fcpxClip, start int, rate Rate) {
+	it := fcpxContaining(spine, start)
+	if it == nil {
+		return
+	}
+
+	clip.Offset = it.local.add(framesToRational(start-it.start, rate)).String()
+	it.anchors = append(it.anchors, clip)
+}
+
+// fcpxMarker writes a marker at a local time, lasting one frame unless it marks a range.
+func fcpxMarker(m *Marker, local rational, rate Rate) *fcpxClip {
+	length := int(m.Out) - int(m.In)
+	if length <= 0 {
+		length = 1
+	}
+
+	return &fcpxClip{
+		XMLName:  xml.Name{Local: "marker"},
+		Start:    local.String(),
+		Duration: framesToRational(length, rate).String(),
+		Value:    string(m.Name),
+		Note:     string(m.Comment),
+	}
+}
+
+// parameter finds a parameter of an effect by its id.
+func (ef *Effect) parameter(id string) *Parameter {
+	for _, p := range ef.Parameters {
+		if p.ParameterID == id {
+			return p
+		}
+	}
+
+	return nil
+}
+
+// fcpxOpacity converts the 0 to 100 opacity of an opacity filter to a blend amount, writing nothing when opaque.
+func fcpxOpacity(ef *Effect) *fcpxBlend {
+	p := ef.parameter(fcpxmlOpacityID)
+	if p == nil || p.Value == nil || p.Value.GetFloat() == 100 {
+		return nil
+	}
+
+	return &fcpxBlend{Amount: fcpxNumber(p.Value.GetFloat() / 100)}
+}
+
+// fcpxMotion converts a basic motion filter to a transform. Its center is a fraction of the frame size with y
+// pointing down, its scale a percentage and its rotation clockwise degrees.
+func fcpxMotion(ef *Effect, width int, height int) *fcpxTransform {
+	t := &fcpxTransform{}
+	if p := ef.parameter("scale"); p != nil && p.Value != nil && p.Value.GetFloat() != 100 {
+		s := fcpxNumber(p.Value.GetFloat() / 100)
+		t.Scale = s + " " + s
+	}
+	if p := ef.parameter("center"); p != nil && p.Value != nil && (p.Value.Horiz != 0 || p.Value.Vert != 0) {
+		x := float64(p.Value.Horiz) * float64(width) / float64(height) * 100
+		y := -float64(p.Value.Vert) * 100
+		t.Position = fcpxNumber(x) + " " + fcpxNumber(y)
+	}
+	if p := ef.parameter("rotation"); p != nil && p.Value != nil && p.Value.GetFloat() != 0 {
+		t.Rotation = fcpxNumber(-p.Value.GetFloat())
+	}
+
+	if *t == (fcpxTransform{}) {
+		return nil
+	}
+
+	return t
+}
+
+// fcpxNumber formats a number without trailing zeros or negative zero.
+func fcpxNumber(f float64) string {
+	f = math.Round(f*1e6) / 1e6
+	if f == 0 {
+		return "0"
+	}
+
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
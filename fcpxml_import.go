@@ -0,0 +1,769 @@
+package converter
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FCPXMLError describes an FCPXML element that could not be imported.
+type FCPXMLError struct {
+	Path string
+	Err  error
+}
+
+func (e *FCPXMLError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *FCPXMLError) Unwrap() error {
+	return e.Err
+}
+
+// ParseFCPXML imports the first project of an FCPXML document into a raw XEML data tree holding its sequence. The
+// spine, secondary storylines and connected clips are flattened into tracks, one or more per lane: the lowest lane
+// becomes video track 1 and the spine's audio becomes audio track 1. Times are converted to frames at the rate of
+// the sequence format, assets become files and compound clips become nested sequences.
+func ParseFCPXML(r io.Reader) (RawXEML, error) {
+	var doc fcpxDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return RawXEML{}, err
+	}
+
+	p := &fcpxParser{
+		formats:    map[string]*fcpxFormat{},
+		assets:     map[string]*fcpxAsset{},
+		media:      map[string]*fcpxMedia{},
+		mediaPaths: map[string]string{},
+		files:      map[string]*File{},
+		sequences:  map[string]*Sequence{},
+	}
+	if doc.Resources != nil {
+		for _, f := range doc.Resources.Formats {
+			p.formats[f.ID] = f
+		}
+		for _, a := range doc.Resources.Assets {
+			p.assets[a.ID] = a
+		}
+		for i, m := range doc.Resources.Media {
+			p.media[m.ID] = m
+			p.mediaPaths[m.ID] = childPath("fcpxml/resources", "media", i)
+		}
+	}
+
+	project, path := doc.project()
+	if project == nil || project.Sequence == nil {
+		return RawXEML{}, errors.New("FCPXML document has no project")
+	}
+
+	s, err := p.sequence(project.Sequence, project.Name, childPath(path, "sequence", 0))
+	if err != nil {
+		return RawXEML{}, err
+	}
+
+	return RawXEML{Version: 5, Sequence: s}, nil
+}
+
+// project finds the first project of a document, in its library or at the top level, along with its path.
+func (d *fcpxDocument) project() (*fcpxProject, string) {
+	if d.Library != nil {
+		for i, e := range d.Library.Events {
+			if len(e.Projects) > 0 {
+				return e.Projects[0], childPath(childPath("fcpxml/library", "event", i), "project", 0)
+			}
+		}
+	}
+
+	for i, e := range d.Events {
+		if len(e.Projects) > 0 {
+			return e.Projects[0], childPath(childPath("fcpxml", "event", i), "project", 0)
+		}
+	}
+
+	if len(d.Projects) > 0 {
+		return d.Projects[0], "fcpxml/project"
+	}
+
+	return nil, ""
+}
+
+type fcpxParser struct {
+	formats    map[string]*fcpxFormat
+	assets     map[string]*fcpxAsset
+	media      map[string]*fcpxMedia
+	mediaPaths map[string]string
+	files      map[string]*File
+	sequences  map[string]*Sequence
+	fileIDs    int
+	seqIDs     int
+	items      int
+}
+
+// fcpxTimeline describes a sequence being flattened: its rate and the tracks of every lane.
+type fcpxTimeline struct {
+	rate        Rate
+	video       map[int][]*Track
+	audio       map[int][]*Track
+	transitions []fcpxLaneTransition
+	markers     []*Marker
+	length      int
+}
+
+type fcpxLaneTransition struct {
+	lane int
+	item *TransitionItem
+}
+
+// fcpxPlacement maps the local time of a container to record frames of the sequence. Clips in the container's
+// own lane are trimmed to its record range.
+type fcpxPlacement struct {
+	origin rational
+	record int
+	lane   int
+	name   string
+	trim   bool
+	start  int
+	end    int
+}
+
+func (pl fcpxPlacement) frames(t rational, r Rate) int {
+	return pl.record + t.sub(pl.origin).frames(r)
+}
+
+func fcpxFail(path string, err error) error {
+	return &FCPXMLError{Path: path, Err: err}
+}
+
+// isFCPXStoryElement reports whether an element takes up time in a storyline.
+func isFCPXStoryElement(n string) bool {
+	switch n {
+	case "asset-clip", "clip", "sync-clip", "ref-clip", "mc-clip", "gap", "transition", "spine", "video", "audio",
+		"title":
+		return true
+	}
+
+	return false
+}
+
+func isFCPXMarker(n string) bool {
+	return n == "marker" || n == "chapter-marker"
+}
+
+func (p *fcpxParser) rate(formatID string, path string) (Rate, *fcpxFormat, error) {
+	f, ok := p.formats[formatID]
+	if !ok {
+		return Rate{}, nil, fcpxFail(path+"@format", fmt.Errorf("format %q is never defined", formatID))
+	}
+
+	fd, err := parseRational(f.FrameDuration)
+	if err != nil {
+		return Rate{}, nil, fcpxFail(path+"@format", err)
+	}
+
+	r, err := fd.frameRate()
+	if err != nil {
+		return Rate{}, nil, fcpxFail(path+"@format", err)
+	}
+
+	return r, f, nil
+}
+
+// sequence flattens the timeline of a project or compound clip.
+func (p *fcpxParser) sequence(fs *fcpxSequence, seqName string, path string) (*Sequence, error) {
+	rate, format, err := p.rate(fs.Format, path)
+	if err != nil {
+		return nil, err
+	}
+
+	tcStart, err := parseRational(fs.TCStart)
+	if err != nil {
+		return nil, fcpxFail(path+"@tcStart", err)
+	}
+
+	tl := &fcpxTimeline{rate: rate, video: map[int][]*Track{}, audio: map[int][]*Track{}}
+	if fs.Spine != nil {
+		pl := fcpxPlacement{origin: tcStart}
+		if err := p.storyline(tl, fs.Spine.Children, pl, childPath(path, "spine", 0)); err != nil {
+			return nil, err
+		}
+	}
+
+	length, err := parseRational(fs.Duration)
+	if err != nil {
+		return nil, fcpxFail(path+"@duration", err)
+	}
+	if tl.length < length.frames(rate) {
+		tl.length = length.frames(rate)
+	}
+
+	r := rate
+	s := &Sequence{
+		Name:     name(seqName),
+		Duration: duration(tl.length),
+		Rate:     &r,
+		In:       -1,
+		Out:      -1,
+		Markers:  tl.markers,
+		Media: &Media{
+			Video: &Video{
+				Format: &Format{SampleCharacteristics: &SampleCharacteristics{
+					Width:  width(format.Width),
+					Height: height(format.Height),
+					Rate:   &r,
+				}},
+			},
+			Audio: &Audio{},
+		},
+	}
+
+	tc := &TimeCode{DisplayFormat: timeCodeNonDropFrame, Rate: &r}
+	if strings.EqualFold(fs.TCFormat, timeCodeDropFrame) && rate.SupportsDropFrame() {
+		tc.DisplayFormat = timeCodeDropFrame
+	}
+	tc.SetFrames(tcStart.frames(rate))
+	s.TimeCode = tc
+
+	for _, lt := range tl.transitions {
+		if ts := tl.video[lt.lane]; len(ts) > 0 {
+			ts[0].TransitionItems = append(ts[0].TransitionItems, lt.item)
+		}
+		if ts := tl.audio[lt.lane]; len(ts) > 0 {
+			ti := *lt.item
+			ti.Effect = transitionEffect("audio", 0)
+			ts[0].TransitionItems = append(ts[0].TransitionItems, &ti)
+		}
+	}
+
+	s.Media.Video.Tracks = fcpxTracks(tl.video, func(a, b int) bool { return a < b })
+	s.Media.Audio.Tracks = fcpxTracks(tl.audio, func(a, b int) bool {
+		// the spine first, then lanes below it and then lanes above it
+		if (a <= 0) != (b <= 0) {
+			return a <= 0
+		}
+		if a <= 0 {
+			return a > b
+		}
+
+		return a < b
+	})
+	if len(s.Media.Audio.Tracks) == 0 {
+		s.Media.Audio = nil
+	}
+
+	sort.SliceStable(s.Markers, func(i, j int) bool {
+		return s.Markers[i].In < s.Markers[j].In
+	})
+
+	return s, nil
+}
+
+// fcpxTracks orders the tracks of every lane, sorting their items by start.
+func fcpxTracks(lanes map[int][]*Track, less func(a, b int) bool) []*Track {
+	var keys []int
+	for k := range lanes {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return less(keys[i], keys[j])
+	})
+
+	var ts []*Track
+	for _, k := range keys {
+		for _, t := range lanes[k] {
+			sort.SliceStable(t.ClipItems, func(i, j int) bool {
+				return t.ClipItems[i].Start < t.ClipItems[j].Start
+			})
+			sort.SliceStable(t.TransitionItems, func(i, j int) bool {
+				return t.TransitionItems[i].Start < t.TransitionItems[j].Start
+			})
+			ts = append(ts, t)
+		}
+	}
+
+	return ts
+}
+
+// place adds a clip item to the first track of its lane it does not overlap, adding a track when needed.
+func (tl *fcpxTimeline) place(lanes map[int][]*Track, lane int, ci *ClipItem) {
+	if int(ci.End) > tl.length {
+		tl.length = int(ci.End)
+	}
+
+tracks:
+	for _, t := range lanes[lane] {
+		for _, o := range t.ClipItems {
+			if int(ci.Start) < int(o.End) && int(o.Start) < int(ci.End) {
+				continue tracks
+			}
+		}
+		t.ClipItems = append(t.ClipItems, ci)
+
+		return
+	}
+
+	lanes[lane] = append(lanes[lane], &Track{Enabled: true, ClipItems: []*ClipItem{ci}})
+}
+
+// storyline flattens the elements of a spine, which follow one another in the lane of the placement.
+func (p *fcpxParser) storyline(tl *fcpxTimeline, items []*fcpxClip, pl fcpxPlacement, path string) error {
+	counts := map[string]int{}
+	var prev *fcpxClip
+	for i, c := range items {
+		n := c.XMLName.Local
+		cp := childPath(path, n, counts[n])
+		counts[n]++
+
+		if !isFCPXStoryElement(n) {
+			continue
+		}
+
+		if n != "transition" {
+			if err := p.element(tl, c, pl, cp); err != nil {
+				return err
+			}
+			prev = c
+
+			continue
+		}
+
+		next := false
+		for _, o := range items[i+1:] {
+			if isFCPXStoryElement(o.XMLName.Local) && o.XMLName.Local != "transition" {
+				next = true
+			}
+		}
+
+		if err := p.transition(tl, c, prev, next, pl, cp); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// transition adds a transition to its lane, aligned to the end of the element before it.
+func (p *fcpxParser) transition(tl *fcpxTimeline, c *fcpxClip, prev *fcpxClip, next bool, pl fcpxPlacement, path string) error {
+	offset, length, err := fcpxTiming(c, path)
+	if err != nil {
+		return err
+	}
+
+	ti := &TransitionItem{
+		Rate:      &Rate{TimeBase: tl.rate.TimeBase, NTSC: tl.rate.NTSC},
+		Start:     start(pl.frames(offset, tl.rate)),
+		Alignment: alignmentCenter,
+		Effect:    transitionEffect("video", 0),
+	}
+	ti.End = end(int(ti.Start) + length.frames(tl.rate))
+	if c.Name != "" {
+		ti.Effect.Name = name(c.Name)
+	}
+
+	switch {
+	case prev == nil:
+		ti.Alignment = alignmentStartBlack
+	case !next:
+		ti.Alignment = alignmentEndBlack
+	default:
+		prevOffset, prevLength, err := fcpxTiming(prev, path)
+		if err != nil {
+			return err
+		}
+
+		cut := pl.frames(prevOffset, tl.rate) + prevLength.frames(tl.rate)
+		switch cut {
+		case int(ti.Start):
+			ti.Alignment = alignmentStart
+		case int(ti.End):
+			ti.Alignment = alignmentEnd
+		}
+	}
+
+	tl.transitions = append(tl.transitions, fcpxLaneTransition{lane: pl.lane, item: ti})
+
+	return nil
+}
+
+// fcpxTiming reads the offset and duration of an element.
+func fcpxTiming(c *fcpxClip, path string) (rational, rational, error) {
+	offset, err := parseRational(c.Offset)
+	if err != nil {
+		return rational{}, rational{}, fcpxFail(path+"@offset", err)
+	}
+
+	length, err := parseRational(c.Duration)
+	if err != nil {
+		return rational{}, rational{}, fcpxFail(path+"@duration", err)
+	}
+
+	return offset, length, nil
+}
+
+// element flattens a story element, its anchored items and its markers.
+func (p *fcpxParser) element(tl *fcpxTimeline, c *fcpxClip, pl fcpxPlacement, path string) error {
+	offset, length, err := fcpxTiming(c, path)
+	if err != nil {
+		return err
+	}
+
+	local, err := parseRational(c.Start)
+	if err != nil {
+		return fcpxFail(path+"@start", err)
+	}
+
+	lane := pl.lane
+	if c.Lane != "" {
+		n, err := strconv.Atoi(c.Lane)
+		if err != nil {
+			return fcpxFail(path+"@lane", fmt.Errorf("lane %q is not a number", c.Lane))
+		}
+		lane += n
+	}
+
+	recStart := pl.frames(offset, tl.rate)
+	recEnd := recStart + length.frames(tl.rate)
+	itemName := c.Name
+	if itemName == "" {
+		itemName = pl.name
+	}
+
+	// the local time the media of the element starts at, which clip item in points count from
+	origin := rational{0, 1}
+	var items []*ClipItem
+	var video, audio bool
+	var source func() (*File, *Sequence, int)
+
+	switch c.XMLName.Local {
+	case "asset-clip", "video", "audio":
+		a, ok := p.assets[c.Ref]
+		if !ok {
+			return fcpxFail(path+"@ref", fmt.Errorf("asset %q is never defined", c.Ref))
+		}
+
+		origin, err = parseRational(a.Start)
+		if err != nil {
+			return fcpxFail(path+"@ref", err)
+		}
+		if c.Start == "" {
+			local = origin
+		}
+		if itemName == "" {
+			itemName = a.Name
+		}
+
+		video = a.HasVideo == "1" && c.SrcEnable != fcpxmlSourceAudio && c.XMLName.Local != "audio"
+		audio = a.HasAudio == "1" && c.SrcEnable != fcpxmlSourceVideo && c.XMLName.Local != "video"
+		source = func() (*File, *Sequence, int) {
+			f, length := p.file(a, tl.rate)
+			return f, nil, length
+		}
+	case "ref-clip":
+		s, tcStart, err := p.compound(c.Ref, path)
+		if err != nil {
+			return err
+		}
+
+		origin = tcStart
+		if c.Start == "" {
+			local = origin
+		}
+		if itemName == "" {
+			itemName = string(s.Name)
+		}
+
+		video, audio = s.Media.Video != nil, s.Media.Audio != nil
+		source = func() (*File, *Sequence, int) {
+			return nil, s, ConvertFrames(int(s.Duration), *s.Rate, tl.rate)
+		}
+	case "spine":
+		first := rational{0, 1}
+		for _, o := range c.Children {
+			if isFCPXStoryElement(o.XMLName.Local) {
+				if first, err = parseRational(o.Offset); err != nil {
+					return fcpxFail(path+"@offset", err)
+				}
+
+				break
+			}
+		}
+
+		return p.storyline(tl, c.Children, fcpxPlacement{origin: first, record: recStart, lane: lane}, path)
+	}
+
+	// the record frame the local time of the element starts at, before trimming
+	record := recStart
+	srcIn := local.sub(origin).frames(tl.rate)
+	if pl.trim && c.Lane == "" {
+		// clips in a container's own lane only play within the container
+		if recStart < pl.start {
+			srcIn += pl.start - recStart
+			recStart = pl.start
+		}
+		if recEnd > pl.end {
+			recEnd = pl.end
+		}
+	}
+
+	if source != nil && recEnd > recStart {
+		for _, kind := range []string{"video", "audio"} {
+			if (kind == "video" && !video) || (kind == "audio" && !audio) {
+				continue
+			}
+
+			f, s, length := source()
+			p.items++
+			r := tl.rate
+			ci := &ClipItem{
+				ID:       fmt.Sprintf("clipitem-%d", p.items),
+				Name:     name(itemName),
+				Duration: duration(length),
+				Rate:     &r,
+				Start:    start(recStart),
+				End:      end(recEnd),
+				In:       in(srcIn),
+				Out:      out(srcIn + recEnd - recStart),
+				Enabled:  c.Enabled != "0",
+				File:     f,
+				Sequence: s,
+			}
+			if kind == "video" {
+				ci.Filters = p.adjustments(c)
+				tl.place(tl.video, lane, ci)
+			} else {
+				tl.place(tl.audio, lane, ci)
+			}
+			items = append(items, ci)
+		}
+	}
+
+	children := fcpxPlacement{
+		origin: local,
+		record: record,
+		lane:   lane,
+		name:   itemName,
+		trim:   true,
+		start:  recStart,
+		end:    recEnd,
+	}
+
+	counts := map[string]int{}
+	for _, o := range c.Children {
+		n := o.XMLName.Local
+		cp := childPath(path, n, counts[n])
+		counts[n]++
+
+		switch {
+		case isFCPXMarker(n):
+			if err := p.marker(tl, o, items, origin, children, cp); err != nil {
+				return err
+			}
+		case isFCPXStoryElement(n) && n != "transition":
+			if err := p.element(tl, o, children, cp); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// marker adds a marker to the clip items of its element in source time, or to the sequence in record time when
+// the element has none.
+func (p *fcpxParser) marker(tl *fcpxTimeline, c *fcpxClip, items []*ClipItem, origin rational, pl fcpxPlacement, path string) error {
+	at, err := parseRational(c.Start)
+	if err != nil {
+		return fcpxFail(path+"@start", err)
+	}
+
+	length, err := parseRational(c.Duration)
+	if err != nil {
+		return fcpxFail(path+"@duration", err)
+	}
+
+	frame := pl.frames(at, tl.rate)
+	if len(items) > 0 {
+		frame = at.sub(origin).frames(tl.rate)
+	}
+
+	m := &Marker{Name: name(c.Value), Comment: comment(c.Note), In: in(frame), Out: -1}
+	if n := length.frames(tl.rate); n > 1 {
+		m.Out = out(frame + n)
+	}
+
+	if len(items) == 0 {
+		tl.markers = append(tl.markers, m)
+	}
+	for _, ci := range items {
+		mc := *m
+		ci.Markers = append(ci.Markers, &mc)
+	}
+
+	return nil
+}
+
+// file returns the definition of the file of an asset the first time it is used, and a reference to it afterwards,
+// along with the length of the file at a rate.
+func (p *fcpxParser) file(a *fcpxAsset, rate Rate) (*File, int) {
+	fileRate := rate
+	var format *fcpxFormat
+	if a.Format != "" {
+		if r, f, err := p.rate(a.Format, ""); err == nil {
+			fileRate, format = r, f
+		}
+	}
+
+	length, _ := parseRational(a.Duration)
+	if f, ok := p.files[a.ID]; ok {
+		return &File{ID: f.ID}, length.frames(rate)
+	}
+
+	p.fileIDs++
+	r := fileRate
+	f := &File{
+		ID:       fmt.Sprintf("file-%d", p.fileIDs),
+		Name:     name(a.Name),
+		Duration: duration(length.frames(fileRate)),
+		Rate:     &r,
+		PathURL:  pathURL(a.Src),
+		Media:    &Media{},
+	}
+	if a.MediaRep != nil {
+		f.PathURL = pathURL(a.MediaRep.Src)
+	}
+
+	assetStart, _ := parseRational(a.Start)
+	f.TimeCode = &TimeCode{DisplayFormat: timeCodeNonDropFrame, Rate: &r}
+	f.TimeCode.SetFrames(assetStart.frames(fileRate))
+
+	if a.HasVideo == "1" {
+		f.Media.Video = &Video{SampleCharacteristics: &SampleCharacteristics{}}
+		if format != nil {
+			f.Media.Video.SampleCharacteristics.Width = width(format.Width)
+			f.Media.Video.SampleCharacteristics.Height = height(format.Height)
+		}
+	}
+	if a.HasAudio == "1" {
+		f.Media.Audio = &Audio{}
+		if n, err := strconv.Atoi(a.AudioChannels); err == nil {
+			f.Media.Audio.ChannelCount = channelCount(n)
+		}
+	}
+
+	p.files[a.ID] = f
+
+	return f, length.frames(rate)
+}
+
+// compound flattens the sequence of a compound clip once, returning it along with the time its timeline starts at.
+func (p *fcpxParser) compound(id string, path string) (*Sequence, rational, error) {
+	m, ok := p.media[id]
+	if !ok || m.Sequence == nil {
+		return nil, rational{}, fcpxFail(path+"@ref", fmt.Errorf("media %q is never defined", id))
+	}
+
+	tcStart, err := parseRational(m.Sequence.TCStart)
+	if err != nil {
+		return nil, rational{}, fcpxFail(path+"@ref", err)
+	}
+
+	if s, ok := p.sequences[id]; ok {
+		if s == nil {
+			return nil, rational{}, fcpxFail(path+"@ref", fmt.Errorf("compound clip %q contains itself", id))
+		}
+
+		return s, tcStart, nil
+	}
+
+	p.sequences[id] = nil
+	s, err := p.sequence(m.Sequence, m.Name, childPath(p.mediaPaths[id], "sequence", 0))
+	if err != nil {
+		return nil, rational{}, err
+	}
+
+	p.seqIDs++
+	s.ID = fmt.Sprintf("sequence-%d", p.seqIDs)
+	p.sequences[id] = s
+
+	return s, tcStart, nil
+}
+
+// adjustments converts the blend and transform adjustments of a clip to opacity and basic motion filters.
+func (p *fcpxParser) adjustments(c *fcpxClip) []*Filter {
+	var filters []*Filter
+	if c.Blend != nil && c.Blend.Amount != "" {
+		if amount, err := strconv.ParseFloat(c.Blend.Amount, 64); err == nil {
+			filters = append(filters, opacityFilter(amount*100))
+		}
+	}
+
+	if c.Transform != nil {
+		w, h := fcpxmlDefaultWidth, fcpxmlDefaultHeight
+		if f, ok := p.files[c.Ref]; ok && f.Media != nil {
+			if fw, fh := sampleSize(f.Media.Video); fw > 0 && fh > 0 {
+				w, h = fw, fh
+			}
+		}
+		filters = append(filters, motionFilter(c.Transform, w, h))
+	}
+
+	return filters
+}
+
+// opacityFilter describes an opacity filter with an opacity from 0 to 100.
+func opacityFilter(percent float64) *Filter {
+	return &Filter{
+		Enabled: true,
+		Effect: &Effect{
+			Name:           "Opacity",
+			EffectID:       fcpxmlOpacityID,
+			EffectType:     "motion",
+			MediaType:      "video",
+			EffectCategory: "motion",
+			Parameters: []*Parameter{
+				{ParameterID: fcpxmlOpacityID, Name: "opacity", Value: &Value{Data: fcpxNumber(percent)}},
+			},
+		},
+	}
+}
+
+// motionFilter describes a basic motion filter from a transform, the reverse of fcpxMotion.
+func motionFilter(t *fcpxTransform, w int, h int) *Filter {
+	pair := func(s string, def float64) (float64, float64) {
+		var a, b float64
+		if _, err := fmt.Sscanf(s, "%g %g", &a, &b); err != nil {
+			return def, def
+		}
+
+		return a, b
+	}
+
+	scale, _ := pair(t.Scale, 1)
+	x, y := pair(t.Position, 0)
+	rotation, err := strconv.ParseFloat(t.Rotation, 64)
+	if err != nil {
+		rotation = 0
+	}
+
+	return &Filter{
+		Enabled: true,
+		Effect: &Effect{
+			Name:           "Basic Motion",
+			EffectID:       fcpxmlMotionID,
+			EffectType:     "motion",
+			MediaType:      "video",
+			EffectCategory: "motion",
+			Parameters: []*Parameter{
+				{ParameterID: "scale", Name: "Scale", Value: &Value{Data: fcpxNumber(scale * 100)}},
+				{ParameterID: "rotation", Name: "Rotation", Value: &Value{Data: fcpxNumber(-rotation)}},
+				{ParameterID: "center", Name: "Center", Value: &Value{
+					Horiz: horiz(x / 100 * float64(h) / float64(w)),
+					Vert:  vert(-y / 100),
+				}},
+			},
+		},
+	}
+}
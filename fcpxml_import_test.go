@@ -0,0 +1,179 @@
+package converter
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestImportingFCPXML(t *testing.T) {
+	fc := `<?xml version="1.0" encoding="UTF-8"?>
+	<!DOCTYPE fcpxml>
+	<fcpxml version="1.9">
+		<resources>
+			<format id="r1" name="FFVideoFormat1080p25" frameDuration="1/25s" width="1920" height="1080"/>
+			<asset id="r2" name="A001" start="36000s" duration="100s" hasVideo="1" format="r1" hasAudio="1" audioSources="1" audioChannels="2">
+				<media-rep kind="original-media" src="file:///media/A001.mov"/>
+			</asset>
+			<asset id="r3" name="music" start="0s" duration="60s" hasAudio="1" audioSources="1" audioChannels="2" src="file:///media/music.wav"/>
+			<media id="r4" name="Compound">
+				<sequence format="r1" duration="2s" tcStart="0s">
+					<spine>
+						<asset-clip ref="r2" offset="0s" start="36010s" duration="2s"/>
+					</spine>
+				</sequence>
+			</media>
+		</resources>
+		<library>
+			<event name="Dailies">
+				<project name="Cut 1">
+					<sequence format="r1" duration="10s" tcStart="3600s" tcFormat="NDF">
+						<spine>
+							<asset-clip ref="r2" offset="3600s" name="Shot 1" start="36001s" duration="4s">
+								<adjust-blend amount="0.5"/>
+								<asset-clip ref="r3" lane="-1" offset="36002s" start="10s" duration="2s"/>
+								<spine lane="1" offset="36001s">
+									<asset-clip ref="r2" offset="0s" name="B1" start="36050s" duration="1s" srcEnable="video"/>
+									<asset-clip ref="r2" offset="1s" name="B2" start="36060s" duration="1s" srcEnable="video"/>
+								</spine>
+								<marker start="36002s" duration="1/25s" value="Flash" note="check"/>
+							</asset-clip>
+							<transition name="Cross Dissolve" offset="3603s" duration="2s"/>
+							<ref-clip ref="r4" offset="3604s" name="Compound" start="1s" duration="1s"/>
+							<gap offset="3605s" name="Gap" start="3600s" duration="5s">
+								<marker start="3601s" duration="1/25s" value="Ending"/>
+							</gap>
+						</spine>
+					</sequence>
+				</project>
+			</event>
+		</library>
+	</fcpxml>`
+
+	x, err := ParseFCPXML(strings.NewReader(fc))
+	if err != nil {
+		t.Fatal("FCPXML could not be imported: " + err.Error())
+	}
+
+	s := x.Sequence
+	if s.Name != "Cut 1" || s.Rate.TimeBase != 25 || s.Rate.NTSC || s.Duration != 250 {
+		t.Error("sequence does not match expectations")
+	}
+
+	if s.TimeCode.TimeCodeString != "01:00:00:00" {
+		t.Error("sequence timecode does not match expectations: " + string(s.TimeCode.TimeCodeString))
+	}
+
+	video := s.Media.Video.Tracks
+	if len(video) != 2 || len(video[0].ClipItems) != 2 || len(video[1].ClipItems) != 2 {
+		t.Fatalf("video tracks do not match expectations: %d", len(video))
+	}
+
+	shot1 := video[0].ClipItems[0]
+	if shot1.Start != 0 || shot1.End != 100 || shot1.In != 25 || shot1.Out != 125 {
+		t.Errorf("clip item does not match expectations: %d %d %d %d", shot1.Start, shot1.End, shot1.In, shot1.Out)
+	}
+
+	if shot1.File == nil || shot1.File.PathURL != "file:///media/A001.mov" || shot1.File.TimeCode.TimeCodeString != "10:00:00:00" {
+		t.Error("file not mapped from asset")
+	}
+
+	if len(shot1.Filters) != 1 || shot1.Filters[0].Effect.Parameters[0].Value.Data != "50" {
+		t.Error("blend not converted to opacity")
+	}
+
+	if len(shot1.Markers) != 1 || shot1.Markers[0].In != 50 || shot1.Markers[0].Comment != "check" {
+		t.Error("clip marker not imported")
+	}
+
+	if b2 := video[1].ClipItems[1]; b2.Name != "B2" || b2.Start != 25 || b2.In != 1500 {
+		t.Errorf("secondary storyline does not match expectations: %d %d", b2.Start, b2.In)
+	}
+
+	compound := video[0].ClipItems[1]
+	if compound.Sequence == nil || compound.In != 25 || compound.Start != 100 {
+		t.Fatal("compound clip not imported as a nested sequence")
+	}
+
+	if nested := compound.Sequence.Media.Video.Tracks[0].ClipItems[0]; nested.File.ID != "file-1" || nested.File.Name != "" {
+		t.Error("nested file not referenced by id")
+	}
+
+	if ts := video[0].TransitionItems; len(ts) != 1 || ts[0].Start != 75 || ts[0].End != 125 || ts[0].Alignment != alignmentCenter {
+		t.Error("transition does not match expectations")
+	}
+
+	audio := s.Media.Audio.Tracks
+	if len(audio) != 2 || len(audio[0].ClipItems) != 2 || len(audio[1].ClipItems) != 1 {
+		t.Fatalf("audio tracks do not match expectations: %d", len(audio))
+	}
+
+	if music := audio[1].ClipItems[0]; music.Start != 25 || music.In != 250 || music.File.PathURL != "file:///media/music.wav" {
+		t.Error("connected audio does not match expectations")
+	}
+
+	if len(s.Markers) != 1 || s.Markers[0].Name != "Ending" || s.Markers[0].In != 150 {
+		t.Error("sequence marker not imported")
+	}
+
+	if _, err := PackageXEML(x); err != nil {
+		t.Error("imported sequence could not be packaged: " + err.Error())
+	}
+}
+
+func TestFCPXMLRoundTrip(t *testing.T) {
+	s, err := ioutil.ReadFile("export-examples/resolve-export.xml")
+	if err != nil {
+		t.Fatal("example export could not be read: " + err.Error())
+	}
+
+	x := ImportRawXEML(s)
+
+	var b bytes.Buffer
+	if err := WriteFCPXML(&b, x.Sequence, FCPXMLOptions{}); err != nil {
+		t.Fatal("FCPXML could not be written: " + err.Error())
+	}
+
+	y, err := ParseFCPXML(&b)
+	if err != nil {
+		t.Fatal("FCPXML could not be imported: " + err.Error())
+	}
+
+	if y.Sequence.Duration != x.Sequence.Duration || !y.Sequence.Rate.Equal(*x.Sequence.Rate) {
+		t.Error("sequence timing not preserved")
+	}
+
+	ci := y.Sequence.Media.Video.Tracks[0].ClipItems[0]
+	if ci.Start != 0 || ci.End != 9 || ci.In != 0 || ci.Out != 9 || ci.File.Name != "NAMI.mp4" {
+		t.Error("clip item not preserved")
+	}
+
+	if len(y.Sequence.Media.Audio.Tracks) != 1 {
+		t.Error("audio of clip not imported")
+	}
+}
+
+func TestImportingFCPXMLErrors(t *testing.T) {
+	fc := `<fcpxml version="1.9">
+		<resources>
+			<format id="r1" frameDuration="1/25s"/>
+		</resources>
+		<project name="Cut">
+			<sequence format="r1">
+				<spine>
+					<gap offset="0s" duration="1s"/>
+					<asset-clip ref="r9" offset="1s" duration="1s"/>
+				</spine>
+			</sequence>
+		</project>
+	</fcpxml>`
+
+	_, err := ParseFCPXML(strings.NewReader(fc))
+
+	var fe *FCPXMLError
+	if !errors.As(err, &fe) || fe.Path != "fcpxml/project/sequence/spine/asset-clip@ref" {
+		t.Errorf("undefined asset not reported: %v", err)
+	}
+}
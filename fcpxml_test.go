@@ -0,0 +1,301 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"testing"
+)
+
+func TestWritingFCPXML(t *testing.T) {
+	xc := `<xmeml version="5">
+		<sequence>
+			<name>Cut 1</name>
+			<duration>120</duration>
+			<rate>
+				<timebase>24</timebase>
+				<ntsc>TRUE</ntsc>
+			</rate>
+			<timecode>
+				<string>01:00:00:00</string>
+				<displayformat>NDF</displayformat>
+				<rate>
+					<timebase>24</timebase>
+					<ntsc>TRUE</ntsc>
+				</rate>
+			</timecode>
+			<marker>
+				<name>Fix colour</name>
+				<in>60</in>
+				<out>-1</out>
+			</marker>
+			<media>
+				<video>
+					<track>
+						<clipitem id="clipitem-1">
+							<name>Shot 1</name>
+							<start>0</start>
+							<end>-1</end>
+							<in>24</in>
+							<out>72</out>
+							<file id="file-1">
+								<name>A001.mov</name>
+								<duration>1000</duration>
+								<pathurl>file://localhost/media/A001.mov</pathurl>
+								<rate>
+									<timebase>24</timebase>
+									<ntsc>TRUE</ntsc>
+								</rate>
+								<timecode>
+									<string>10:00:00:00</string>
+									<displayformat>NDF</displayformat>
+									<rate>
+										<timebase>24</timebase>
+										<ntsc>TRUE</ntsc>
+									</rate>
+								</timecode>
+								<media>
+									<video>
+										<samplecharacteristics>
+											<width>1920</width>
+											<height>1080</height>
+										</samplecharacteristics>
+									</video>
+									<audio>
+										<channelcount>2</channelcount>
+									</audio>
+								</media>
+							</file>
+							<filter>
+								<effect>
+									<name>Opacity</name>
+									<effectid>opacity</effectid>
+									<effecttype>motion</effecttype>
+									<mediatype>video</mediatype>
+									<parameter>
+										<parameterid>opacity</parameterid>
+										<value>50</value>
+									</parameter>
+								</effect>
+							</filter>
+						</clipitem>
+						<transitionitem>
+							<start>36</start>
+							<end>60</end>
+							<alignment>center</alignment>
+							<effect>
+								<name>Cross Dissolve</name>
+								<effectid>Cross Dissolve</effectid>
+								<effecttype>transition</effecttype>
+								<mediatype>video</mediatype>
+							</effect>
+						</transitionitem>
+						<clipitem id="clipitem-2">
+							<name>Shot 2</name>
+							<start>-1</start>
+							<end>96</end>
+							<in>0</in>
+							<out>48</out>
+							<file id="file-1"/>
+							<marker>
+								<name>Flash</name>
+								<in>12</in>
+								<out>-1</out>
+							</marker>
+						</clipitem>
+					</track>
+					<track>
+						<clipitem id="clipitem-3">
+							<name>Title</name>
+							<start>12</start>
+							<end>36</end>
+							<in>100</in>
+							<out>124</out>
+							<file id="file-1"/>
+							<filter>
+								<effect>
+									<name>Basic Motion</name>
+									<effectid>basic</effectid>
+									<effecttype>motion</effecttype>
+									<mediatype>video</mediatype>
+									<parameter>
+										<parameterid>scale</parameterid>
+										<value>50</value>
+									</parameter>
+									<parameter>
+										<parameterid>center</parameterid>
+										<value>
+											<horiz>0.25</horiz>
+											<vert>-0.25</vert>
+										</value>
+									</parameter>
+									<parameter>
+										<parameterid>rotation</parameterid>
+										<value>90</value>
+									</parameter>
+								</effect>
+							</filter>
+						</clipitem>
+					</track>
+				</video>
+				<audio>
+					<track>
+						<clipitem id="clipitem-4">
+							<name>Shot 1</name>
+							<start>0</start>
+							<end>48</end>
+							<in>24</in>
+							<out>72</out>
+							<file id="file-1"/>
+						</clipitem>
+						<clipitem id="clipitem-5">
+							<name>Music</name>
+							<start>48</start>
+							<end>96</end>
+							<in>0</in>
+							<out>48</out>
+							<file id="file-2">
+								<name>music.wav</name>
+								<duration>480</duration>
+								<rate>
+									<timebase>24</timebase>
+									<ntsc>TRUE</ntsc>
+								</rate>
+								<media>
+									<audio>
+										<channelcount>2</channelcount>
+									</audio>
+								</media>
+							</file>
+						</clipitem>
+					</track>
+				</audio>
+			</media>
+		</sequence>
+	</xmeml>`
+
+	x := ImportRawXEML([]byte(xc))
+
+	var b bytes.Buffer
+	if err := WriteFCPXML(&b, x.Sequence, FCPXMLOptions{EventName: "Dailies"}); err != nil {
+		t.Fatal("FCPXML could not be written: " + err.Error())
+	}
+
+	var doc fcpxDocument
+	if err := xml.Unmarshal(b.Bytes(), &doc); err != nil {
+		t.Fatal("FCPXML could not be read back: " + err.Error())
+	}
+
+	if doc.Version != "1.9" || len(doc.Resources.Formats) != 1 || len(doc.Resources.Assets) != 2 {
+		t.Fatal("resources do not match expectations")
+	}
+
+	if f := doc.Resources.Formats[0]; f.Name != "FFVideoFormat1080p2398" || f.FrameDuration != "1001/24000s" {
+		t.Error("format does not match expectations")
+	}
+
+	a := doc.Resources.Assets[0]
+	if a.Start != "36036s" || a.MediaRep == nil || a.MediaRep.Src != "file:///media/A001.mov" {
+		t.Error("asset does not match expectations")
+	}
+
+	if music := doc.Resources.Assets[1]; music.HasVideo != "" || music.HasAudio != "1" {
+		t.Error("audio asset does not match expectations")
+	}
+
+	event := doc.Library.Events[0]
+	if event.Name != "Dailies" || event.Projects[0].Name != "Cut 1" {
+		t.Error("event or project names do not match expectations")
+	}
+
+	seq := event.Projects[0].Sequence
+	if seq.TCStart != "18018/5s" || seq.Duration != "1001/200s" {
+		t.Errorf("sequence timing does not match expectations: %s %s", seq.TCStart, seq.Duration)
+	}
+
+	spine := seq.Spine.Children
+	if len(spine) != 4 {
+		t.Fatalf("spine items do not match expectations: %d", len(spine))
+	}
+
+	names := []string{"asset-clip", "transition", "asset-clip", "gap"}
+	for i, n := range names {
+		if spine[i].XMLName.Local != n {
+			t.Errorf("spine item %d is a %s, not a %s", i, spine[i].XMLName.Local, n)
+		}
+	}
+
+	shot1 := spine[0]
+	if shot1.Offset != "18018/5s" || shot1.Start != "36037001/1000s" || shot1.Duration != "1001/500s" {
+		t.Errorf("clip timing does not match expectations: %s %s %s", shot1.Offset, shot1.Start, shot1.Duration)
+	}
+
+	if shot1.Blend == nil || shot1.Blend.Amount != "0.5" {
+		t.Error("opacity not converted")
+	}
+
+	if shot1.SrcEnable != "" {
+		t.Error("clip with its audio used muted")
+	}
+
+	if len(shot1.Children) != 1 || shot1.Children[0].Lane != "1" {
+		t.Fatal("connected clip not anchored")
+	}
+
+	title := shot1.Children[0]
+	if title.Offset != "72075003/2000s" || title.SrcEnable != "video" {
+		t.Errorf("connected clip does not match expectations: %s %s", title.Offset, title.SrcEnable)
+	}
+
+	if tr := title.Transform; tr == nil || tr.Scale != "0.5 0.5" || tr.Position != "44.444444 25" || tr.Rotation != "-90" {
+		t.Error("motion not converted")
+	}
+
+	if spine[1].Name != "Cross Dissolve" || spine[1].Duration != "1001/1000s" || len(spine[1].Children) != 1 {
+		t.Error("transition does not match expectations")
+	}
+
+	shot2 := spine[2]
+	if len(shot2.Children) != 3 {
+		t.Fatalf("clip children do not match expectations: %d", len(shot2.Children))
+	}
+
+	if music := shot2.Children[0]; music.Lane != "-1" || music.Offset != "36036s" {
+		t.Error("audio clip not connected below the spine")
+	}
+
+	if m := shot2.Children[1]; m.XMLName.Local != "marker" || m.Value != "Flash" || m.Start != "72073001/2000s" {
+		t.Error("clip marker does not match expectations")
+	}
+
+	if m := shot2.Children[2]; m.Value != "Fix colour" || m.Start != "72073001/2000s" {
+		t.Errorf("sequence marker does not match expectations: %s", m.Start)
+	}
+}
+
+func TestWritingFCPXMLFromExports(t *testing.T) {
+	s, err := ioutil.ReadFile("export-examples/premier-export.xml")
+	if err != nil {
+		t.Fatal("example export could not be read: " + err.Error())
+	}
+
+	x := ImportRawXEML(s)
+
+	var b bytes.Buffer
+	if err := WriteFCPXML(&b, x.Sequence, FCPXMLOptions{}); err != nil {
+		t.Fatal("FCPXML could not be written: " + err.Error())
+	}
+
+	var doc fcpxDocument
+	if err := xml.Unmarshal(b.Bytes(), &doc); err != nil {
+		t.Fatal("FCPXML could not be read back: " + err.Error())
+	}
+
+	if len(doc.Resources.Assets) != 1 || doc.Resources.Formats[0].Name != "FFVideoFormat720p2398" {
+		t.Error("resources do not match expectations")
+	}
+
+	if err := WriteFCPXML(&b, x.Sequence, FCPXMLOptions{Version: "1.8"}); err == nil {
+		t.Error("unsupported version not rejected")
+	}
+}
@@ -0,0 +1,433 @@
+package converter
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// Flatten returns a copy of a sequence with the clip items that nest sequences replaced by the items of the nested
+// sequences, so that it plays the same from a single level of tracks. Nested sequences are flattened first.
+//
+// The items of the first nested track of a kind play on the track of the clip item that nests them, and the items
+// of further nested tracks play on tracks inserted after it. Only the part of a nested sequence between the in and
+// out points of the clip item nesting it is kept: items are trimmed to it and moved to its record range, converting
+// frames in real time when the nested sequence has a different rate, and transitions outside of it are dropped.
+// Items take the filters of the clip item nesting them, and are disabled when it or their nested track is.
+//
+// Nested clip items get ids made of the id of the clip item nesting them and their own id, and their links are pointed
+// at those ids, between video and audio too when the clip items nesting them are linked. Links by clip reference are
+// given the track and clip index of the items they point at in the flattened sequence, and links to clip items that are
+// no longer part of it are dropped. Markers of nested sequences are not kept. Sequences that nest themselves, and
+// nested clip items with a speed change that are trimmed, cannot be flattened.
+func (s *Sequence) Flatten() (*Sequence, error) {
+	flat := &Sequence{}
+	if err := copyElement(flat, s); err != nil {
+		return nil, err
+	}
+
+	rate := Rate{}
+	if flat.Rate != nil {
+		rate = *flat.Rate
+	}
+
+	f := &flattener{sequences: map[string]*Sequence{}, active: map[*Sequence]bool{}}
+	f.index(flat)
+	if err := f.sequence(flat, rate); err != nil {
+		return nil, err
+	}
+
+	relink(flat)
+
+	return flat, nil
+}
+
+// copyElement copies an element of the raw model by marshalling it, so that the copy shares nothing with it.
+func copyElement(dst interface{}, src interface{}) error {
+	b, err := xml.Marshal(src)
+	if err != nil {
+		return err
+	}
+
+	return xml.Unmarshal(b, dst)
+}
+
+type flattener struct {
+	// definitions of nested sequences by id, which may be referenced before they are defined
+	sequences map[string]*Sequence
+	// nested sequences being flattened, to find sequences that nest themselves
+	active map[*Sequence]bool
+}
+
+func (f *flattener) index(s *Sequence) {
+	if s.Media == nil {
+		return
+	}
+
+	for _, t := range s.Media.tracks() {
+		for _, ci := range t.ClipItems {
+			ns := ci.Sequence
+			if ns == nil || ns.Media == nil {
+				continue
+			}
+			if _, ok := f.sequences[ns.ID]; ns.ID != "" && !ok {
+				f.sequences[ns.ID] = ns
+			}
+			f.index(ns)
+		}
+	}
+}
+
+// definition resolves a reference to a nested sequence defined elsewhere.
+func (f *flattener) definition(s *Sequence) *Sequence {
+	if s.Media == nil && s.ID != "" {
+		if d, ok := f.sequences[s.ID]; ok {
+			return d
+		}
+	}
+
+	return s
+}
+
+func (f *flattener) sequence(s *Sequence, rate Rate) error {
+	if s.Media == nil {
+		return nil
+	}
+
+	var err error
+	var windows, more []*nestedWindow
+	if v := s.Media.Video; v != nil {
+		if v.Tracks, windows, err = f.tracks(v.Tracks, "video", rate); err != nil {
+			return err
+		}
+	}
+	if a := s.Media.Audio; a != nil {
+		if a.Tracks, more, err = f.tracks(a.Tracks, "audio", rate); err != nil {
+			return err
+		}
+		windows = append(windows, more...)
+	}
+	relinkNested(windows)
+
+	return nil
+}
+
+// tracks replaces the clip items of a kind of tracks that nest sequences with the items of the nested tracks of the
+// same kind, returning the windows of the clip items nesting them.
+func (f *flattener) tracks(ts []*Track, media string, rate Rate) ([]*Track, []*nestedWindow, error) {
+	var windows []*nestedWindow
+	var flat []*Track
+	for _, t := range ts {
+		edits, _ := editTrack(t)
+		records := map[*ClipItem]*trackEdit{}
+		for _, e := range edits {
+			records[e.item] = e
+		}
+
+		layers := []*Track{t}
+		items := t.ClipItems
+		t.ClipItems = nil
+		for _, ci := range items {
+			if ci.Sequence == nil {
+				t.ClipItems = append(t.ClipItems, ci)
+				continue
+			}
+
+			e := records[ci]
+			w, err := f.expand(ci, e, media, rate)
+			if err != nil {
+				return nil, nil, err
+			}
+			windows = append(windows, w)
+
+			for i, n := range w.tracks {
+				if i == len(layers) {
+					layers = append(layers, &Track{Enabled: t.Enabled, Locked: t.Locked})
+				}
+				l := layers[i]
+				l.ClipItems = append(l.ClipItems, n.ClipItems...)
+				l.GeneratorItems = append(l.GeneratorItems, n.GeneratorItems...)
+				l.TransitionItems = append(l.TransitionItems, n.TransitionItems...)
+			}
+		}
+
+		flat = append(flat, layers...)
+	}
+
+	return flat, windows, nil
+}
+
+// expand returns the window of a clip item on the sequence it nests, with the tracks of a kind of the sequence
+// flattened and moved to the record range of the clip item.
+func (f *flattener) expand(ci *ClipItem, e *trackEdit, media string, parent Rate) (*nestedWindow, error) {
+	def := f.definition(ci.Sequence)
+	if def.Media == nil {
+		return nil, fmt.Errorf("nested sequence %q of clip item %q is not defined", def.ID, ci.ID)
+	}
+	if f.active[def] {
+		return nil, fmt.Errorf("nested sequence %q of clip item %q nests itself", def.ID, ci.ID)
+	}
+	if hasTimeRemap(ci.Filters) {
+		return nil, fmt.Errorf("clip item %q nests a sequence with a speed change", ci.ID)
+	}
+
+	rate := parent
+	if def.Rate != nil && def.Rate.TimeBase > 0 {
+		rate = *def.Rate
+	} else if ci.Rate != nil && ci.Rate.TimeBase > 0 {
+		rate = *ci.Rate
+	}
+
+	nested := &Sequence{}
+	if err := copyElement(nested, def); err != nil {
+		return nil, err
+	}
+
+	f.active[def] = true
+	err := f.sequence(nested, rate)
+	delete(f.active, def)
+	if err != nil {
+		return nil, err
+	}
+
+	var ts []*Track
+	if media == "video" && nested.Media.Video != nil {
+		ts = nested.Media.Video.Tracks
+	}
+	if media == "audio" && nested.Media.Audio != nil {
+		ts = nested.Media.Audio.Tracks
+	}
+
+	w := &nestedWindow{
+		item:   ci,
+		in:     int(ci.In),
+		out:    int(ci.Out),
+		start:  e.start,
+		rate:   rate,
+		parent: parent,
+		ids:    map[string]string{},
+	}
+	if ci.Rate != nil && ci.Rate.TimeBase > 0 && !ci.Rate.Equal(rate) {
+		w.in = ConvertFrames(w.in, *ci.Rate, rate)
+		w.out = ConvertFrames(w.out, *ci.Rate, rate)
+	}
+	if e.start >= 0 && e.end >= 0 && int(ci.Out) <= int(ci.In) {
+		w.out = w.in + ConvertFrames(e.end-e.start, parent, rate)
+	}
+
+	for _, t := range ts {
+		mapped, err := w.track(t)
+		if err != nil {
+			return nil, err
+		}
+		w.tracks = append(w.tracks, mapped)
+	}
+
+	return w, nil
+}
+
+// nestedWindow describes the part of a nested sequence played by the clip item nesting it, between in and out in
+// frames of the nested sequence.
+type nestedWindow struct {
+	item   *ClipItem
+	in     int
+	out    int
+	start  int
+	rate   Rate
+	parent Rate
+	// new ids of nested clip items by their ids in the nested sequence
+	ids map[string]string
+	// flattened tracks of the nested sequence
+	tracks []*Track
+}
+
+// record converts a frame of the nested sequence to a record frame of the sequence nesting it.
+func (w *nestedWindow) record(frame int) int {
+	return w.start + ConvertFrames(frame-w.in, w.rate, w.parent)
+}
+
+// recordRange converts a range of the nested sequence to a record range of the sequence nesting it.
+func (w *nestedWindow) recordRange(from int, to int) (start, end) {
+	return start(w.record(from)), end(w.record(to))
+}
+
+// trim clips a record range of the nested sequence to the window, returning false when nothing of it is played.
+func (w *nestedWindow) trim(from int, to int) (int, int, bool) {
+	if from < w.in {
+		from = w.in
+	}
+	if to > w.out {
+		to = w.out
+	}
+
+	return from, to, from < to
+}
+
+// source moves the source range of an item by the frames trimmed from its record range, in its own rate.
+func (w *nestedWindow) source(srcIn int, srcOut int, itemRate *Rate, trimStart int, trimEnd int) (in, out) {
+	r := w.rate
+	if itemRate != nil && itemRate.TimeBase > 0 {
+		r = *itemRate
+	}
+
+	return in(srcIn + ConvertFrames(trimStart, w.rate, r)), out(srcOut - ConvertFrames(trimEnd, w.rate, r))
+}
+
+// itemRate gives an item the rate of the nested sequence when it inherited it and the rates differ.
+func (w *nestedWindow) itemRate(r *Rate) *Rate {
+	if (r == nil || r.TimeBase <= 0) && !w.rate.Equal(w.parent) {
+		nr := w.rate
+		return &nr
+	}
+
+	return r
+}
+
+func (w *nestedWindow) track(t *Track) (*Track, error) {
+	enabled := t.Enabled && w.item.Enabled
+	flat := &Track{Enabled: t.Enabled, Locked: t.Locked}
+
+	edits, trs := editTrack(t)
+	for _, e := range edits {
+		ci := e.item
+		from, to, ok := w.trim(e.start, e.end)
+		if !ok {
+			continue
+		}
+
+		trimStart, trimEnd := from-e.start, e.end-to
+		if hasTimeRemap(ci.Filters) && (trimStart != 0 || trimEnd != 0) {
+			return nil, fmt.Errorf("nested clip item %q with a speed change is trimmed by clip item %q", ci.ID, w.item.ID)
+		}
+
+		ci.In, ci.Out = w.source(int(ci.In), int(ci.Out), ci.Rate, trimStart, trimEnd)
+		ci.Start, ci.End = w.recordRange(from, to)
+		ci.Rate = w.itemRate(ci.Rate)
+		ci.Enabled = ci.Enabled && enabled
+		ci.Filters = append(ci.Filters, w.item.Filters...)
+
+		if ci.ID != "" && w.item.ID != "" {
+			id := w.item.ID + "-" + ci.ID
+			w.ids[ci.ID] = id
+			ci.ID = id
+		}
+
+		flat.ClipItems = append(flat.ClipItems, ci)
+	}
+
+	for _, g := range t.GeneratorItems {
+		from, to, ok := w.trim(int(g.Start), int(g.End))
+		if !ok {
+			continue
+		}
+
+		g.In, g.Out = w.source(int(g.In), int(g.Out), g.Rate, from-int(g.Start), int(g.End)-to)
+		g.Start, g.End = w.recordRange(from, to)
+		g.Rate = w.itemRate(g.Rate)
+		g.Enabled = g.Enabled && enabled
+
+		flat.GeneratorItems = append(flat.GeneratorItems, g)
+	}
+
+	for _, tr := range trs {
+		if tr.start < w.in || tr.end > w.out {
+			continue
+		}
+
+		ti := tr.item
+		ti.Start, ti.End = w.recordRange(tr.start, tr.end)
+		flat.TransitionItems = append(flat.TransitionItems, ti)
+	}
+
+	return flat, nil
+}
+
+// relinkNested points the links of nested clip items at their new ids once every kind of track of a sequence is
+// flattened. The ids of the windows of clip items linked to each other are shared, so that links between nested
+// video and audio follow the linked clip items nesting them.
+func relinkNested(windows []*nestedWindow) {
+	byID := map[string]*nestedWindow{}
+	for _, w := range windows {
+		if w.item.ID != "" {
+			byID[w.item.ID] = w
+		}
+	}
+
+	for _, w := range windows {
+		ids := map[string]string{}
+		for _, l := range w.item.Links {
+			if linked, ok := byID[string(l.LinkClipRef)]; ok && linked != w {
+				for old, id := range linked.ids {
+					ids[old] = id
+				}
+			}
+		}
+		for old, id := range w.ids {
+			ids[old] = id
+		}
+
+		w.relink(ids)
+	}
+}
+
+// relink points the links of nested clip items at new ids by their old ones, dropping links to items that are not
+// played.
+func (w *nestedWindow) relink(ids map[string]string) {
+	for _, t := range w.tracks {
+		for _, ci := range t.ClipItems {
+			var links []*Link
+			for _, l := range ci.Links {
+				id, ok := ids[string(l.LinkClipRef)]
+				if !ok {
+					continue
+				}
+
+				l.LinkClipRef = linkClipRef(id)
+				links = append(links, l)
+			}
+			ci.Links = links
+		}
+	}
+}
+
+// relink gives links by clip reference the track and clip index of the items they point at, and drops links to
+// clip items that are not part of a sequence.
+func relink(s *Sequence) {
+	idx := newLinkIndex(s)
+
+	type position struct {
+		media string
+		track int
+		clip  int
+	}
+	positions := map[*ClipItem]position{}
+	for media, ts := range idx.tracks {
+		for i, t := range ts {
+			for j, ci := range t.ClipItems {
+				positions[ci] = position{media, i + 1, j + 1}
+			}
+		}
+	}
+
+	for _, li := range idx.items {
+		var links []*Link
+		for _, l := range li.item.Links {
+			if l.LinkClipRef == "" {
+				links = append(links, l)
+				continue
+			}
+
+			target := idx.ids[string(l.LinkClipRef)]
+			if target == nil {
+				continue
+			}
+
+			if l.TrackIndex != 0 || l.ClipIndex != 0 {
+				p := positions[target]
+				l.MediaType = mediaType(p.media)
+				l.TrackIndex, l.ClipIndex = trackIndex(p.track), clipIndex(p.clip)
+			}
+			links = append(links, l)
+		}
+		li.item.Links = links
+	}
+}
@@ -0,0 +1,288 @@
+package converter
+
+import (
+	"testing"
+)
+
+const nestedXML = `<xmeml version="5">
+	<sequence id="sequence-1">
+		<name>Cut</name>
+		<duration>150</duration>
+		<rate>
+			<timebase>25</timebase>
+		</rate>
+		<media>
+			<video>
+				<track>
+					<clipitem id="clipitem-1">
+						<name>A</name>
+						<enabled>TRUE</enabled>
+						<start>0</start>
+						<end>50</end>
+						<in>0</in>
+						<out>50</out>
+					</clipitem>
+					<clipitem id="clipitem-2">
+						<name>Nest</name>
+						<enabled>TRUE</enabled>
+						<start>50</start>
+						<end>150</end>
+						<in>10</in>
+						<out>110</out>
+						<filter>
+							<effect>
+								<name>Opacity</name>
+								<effectid>opacity</effectid>
+							</effect>
+						</filter>
+						<sequence id="sequence-2">
+							<name>Nest</name>
+							<duration>200</duration>
+							<rate>
+								<timebase>25</timebase>
+							</rate>
+							<media>
+								<video>
+									<track>
+										<clipitem id="clipitem-3">
+											<name>X</name>
+											<enabled>TRUE</enabled>
+											<start>0</start>
+											<end>60</end>
+											<in>0</in>
+											<out>60</out>
+											<link>
+												<linkclipref>clipitem-3</linkclipref>
+												<mediatype>video</mediatype>
+												<trackindex>1</trackindex>
+												<clipindex>1</clipindex>
+											</link>
+											<link>
+												<linkclipref>clipitem-5</linkclipref>
+												<mediatype>video</mediatype>
+												<trackindex>2</trackindex>
+												<clipindex>1</clipindex>
+											</link>
+										</clipitem>
+										<clipitem id="clipitem-4">
+											<name>Y</name>
+											<enabled>TRUE</enabled>
+											<start>60</start>
+											<end>200</end>
+											<in>100</in>
+											<out>240</out>
+										</clipitem>
+										<enabled>TRUE</enabled>
+									</track>
+									<track>
+										<clipitem id="clipitem-5">
+											<name>Z</name>
+											<enabled>TRUE</enabled>
+											<start>20</start>
+											<end>40</end>
+											<in>0</in>
+											<out>20</out>
+										</clipitem>
+										<clipitem id="clipitem-6">
+											<name>Outside</name>
+											<enabled>TRUE</enabled>
+											<start>150</start>
+											<end>200</end>
+											<in>0</in>
+											<out>50</out>
+										</clipitem>
+										<enabled>FALSE</enabled>
+									</track>
+								</video>
+							</media>
+						</sequence>
+					</clipitem>
+					<enabled>TRUE</enabled>
+				</track>
+				<track>
+					<clipitem id="clipitem-7">
+						<name>Title</name>
+						<enabled>TRUE</enabled>
+						<start>0</start>
+						<end>25</end>
+						<in>0</in>
+						<out>25</out>
+					</clipitem>
+					<enabled>TRUE</enabled>
+				</track>
+			</video>
+		</media>
+	</sequence>
+</xmeml>`
+
+func TestFlatteningNestedSequences(t *testing.T) {
+	x := ImportRawXEML([]byte(nestedXML))
+
+	flat, err := x.Sequence.Flatten()
+	if err != nil {
+		t.Fatal("sequence could not be flattened: " + err.Error())
+	}
+
+	if x.Sequence.Media.Video.Tracks[0].ClipItems[1].Sequence == nil {
+		t.Error("flattening changed the sequence")
+	}
+
+	tracks := flat.Media.Video.Tracks
+	if len(tracks) != 3 {
+		t.Fatalf("flattened tracks do not match expectations: %d", len(tracks))
+	}
+
+	first := tracks[0].ClipItems
+	if len(first) != 3 || first[0].ID != "clipitem-1" || first[1].ID != "clipitem-2-clipitem-3" || first[2].ID != "clipitem-2-clipitem-4" {
+		t.Fatal("nested clip items not expanded on the track nesting them")
+	}
+
+	if x := first[1]; x.Start != 50 || x.End != 100 || x.In != 10 || x.Out != 60 {
+		t.Errorf("nested clip item not trimmed to the in point: %d %d %d %d", x.Start, x.End, x.In, x.Out)
+	}
+
+	if y := first[2]; y.Start != 100 || y.End != 150 || y.In != 100 || y.Out != 150 {
+		t.Errorf("nested clip item not trimmed to the out point: %d %d %d %d", y.Start, y.End, y.In, y.Out)
+	}
+
+	if len(first[1].Filters) != 1 || first[1].Filters[0].Effect.EffectID != "opacity" || !first[1].Enabled {
+		t.Error("filters of the nesting clip item not carried")
+	}
+
+	inserted := tracks[1].ClipItems
+	if len(inserted) != 1 || inserted[0].ID != "clipitem-2-clipitem-5" || inserted[0].Start != 60 || inserted[0].End != 80 {
+		t.Fatal("second nested track not inserted after the track nesting it")
+	}
+
+	if inserted[0].Enabled {
+		t.Error("clip item of a disabled nested track not disabled")
+	}
+
+	if tracks[2].ClipItems[0].ID != "clipitem-7" {
+		t.Error("following track not kept")
+	}
+
+	links := first[1].Links
+	if len(links) != 2 || links[1].LinkClipRef != "clipitem-2-clipitem-5" || links[1].TrackIndex != 2 || links[1].ClipIndex != 1 {
+		t.Error("links of nested clip items not pointed at their flattened clip items")
+	}
+
+	if links[0].TrackIndex != 1 || links[0].ClipIndex != 2 {
+		t.Error("link indices not updated")
+	}
+
+	if findings := Validate(RawXEML{Sequence: flat}, ValidationOptions{}); len(findings) != 0 {
+		t.Errorf("flattened sequence is not valid: %s", findings[0])
+	}
+}
+
+func TestFlatteningAcrossRates(t *testing.T) {
+	nested := &Sequence{
+		ID:   "sequence-2",
+		Rate: &Rate{TimeBase: 50},
+		Media: &Media{Video: &Video{Tracks: []*Track{{Enabled: true, ClipItems: []*ClipItem{
+			{ID: "x", Enabled: true, Start: 0, End: 100, In: 0, Out: 100},
+			{ID: "y", Enabled: true, Start: 100, End: 200, In: 0, Out: 100},
+		}}}}},
+	}
+	s := &Sequence{
+		Rate: &Rate{TimeBase: 25},
+		Media: &Media{Video: &Video{Tracks: []*Track{{Enabled: true, ClipItems: []*ClipItem{
+			{ID: "nest", Enabled: true, Start: 0, End: 50, In: 50, Out: 150, Sequence: nested},
+			{ID: "again", Enabled: true, Start: 50, End: 100, In: 0, Out: 100, Sequence: &Sequence{ID: "sequence-2"}},
+		}}}}},
+	}
+
+	flat, err := s.Flatten()
+	if err != nil {
+		t.Fatal("sequence could not be flattened: " + err.Error())
+	}
+
+	items := flat.Media.Video.Tracks[0].ClipItems
+	if len(items) != 3 {
+		t.Fatalf("flattened clip items do not match expectations: %d", len(items))
+	}
+
+	x := items[0]
+	if x.Start != 0 || x.End != 25 || x.In != 50 || x.Out != 100 || x.Rate == nil || x.Rate.TimeBase != 50 {
+		t.Errorf("nested clip item not converted to the sequence rate: %d %d %d %d", x.Start, x.End, x.In, x.Out)
+	}
+
+	if y := items[1]; y.Start != 25 || y.End != 50 || y.In != 0 || y.Out != 50 {
+		t.Errorf("nested clip item not converted to the sequence rate: %d %d %d %d", y.Start, y.End, y.In, y.Out)
+	}
+
+	if again := items[2]; again.ID != "again-x" || again.Start != 50 || again.End != 100 {
+		t.Error("reference to a nested sequence not flattened")
+	}
+}
+
+func TestFlatteningLinkedNestedSequences(t *testing.T) {
+	link := func(id string, media mediaType) *Link {
+		return &Link{LinkClipRef: linkClipRef(id), MediaType: media, TrackIndex: 1, ClipIndex: 1}
+	}
+	nested := &Sequence{
+		ID:   "sequence-2",
+		Rate: &Rate{TimeBase: 25},
+		Media: &Media{
+			Video: &Video{Tracks: []*Track{{Enabled: true, ClipItems: []*ClipItem{
+				{ID: "v", Enabled: true, Start: 0, End: 50, In: 0, Out: 50, Links: []*Link{link("v", "video"), link("a", "audio")}},
+			}}}},
+			Audio: &Audio{Tracks: []*Track{{Enabled: true, ClipItems: []*ClipItem{
+				{ID: "a", Enabled: true, Start: 0, End: 50, In: 0, Out: 50, Links: []*Link{link("v", "video"), link("a", "audio")}},
+			}}}},
+		},
+	}
+	s := &Sequence{
+		Rate: &Rate{TimeBase: 25},
+		Media: &Media{
+			Video: &Video{Tracks: []*Track{{Enabled: true, ClipItems: []*ClipItem{
+				{ID: "nv", Enabled: true, Start: 0, End: 50, In: 0, Out: 50, Sequence: nested,
+					Links: []*Link{link("nv", "video"), link("na", "audio")}},
+			}}}},
+			Audio: &Audio{Tracks: []*Track{{Enabled: true, ClipItems: []*ClipItem{
+				{ID: "na", Enabled: true, Start: 0, End: 50, In: 0, Out: 50, Sequence: &Sequence{ID: "sequence-2"},
+					Links: []*Link{link("nv", "video"), link("na", "audio")}},
+			}}}},
+		},
+	}
+
+	flat, err := s.Flatten()
+	if err != nil {
+		t.Fatal("sequence could not be flattened: " + err.Error())
+	}
+
+	v := flat.Media.Video.Tracks[0].ClipItems[0]
+	a := flat.Media.Audio.Tracks[0].ClipItems[0]
+	if v.ID != "nv-v" || a.ID != "na-a" {
+		t.Fatalf("nested clip items do not match expectations: %s %s", v.ID, a.ID)
+	}
+
+	for _, ci := range []*ClipItem{v, a} {
+		if len(ci.Links) != 2 || ci.Links[0].LinkClipRef != "nv-v" || ci.Links[1].LinkClipRef != "na-a" {
+			t.Errorf("links of nested clip item %s not kept between video and audio", ci.ID)
+		}
+	}
+}
+
+func TestFlatteningErrors(t *testing.T) {
+	s := &Sequence{
+		Rate: &Rate{TimeBase: 25},
+		Media: &Media{Video: &Video{Tracks: []*Track{{ClipItems: []*ClipItem{
+			{ID: "nest", Start: 0, End: 50, In: 0, Out: 50, Sequence: &Sequence{ID: "missing"}},
+		}}}}},
+	}
+
+	if _, err := s.Flatten(); err == nil {
+		t.Error("undefined nested sequence not reported")
+	}
+
+	loop := &Sequence{ID: "loop", Media: &Media{Video: &Video{Tracks: []*Track{{ClipItems: []*ClipItem{
+		{ID: "inner", Start: 0, End: 50, In: 0, Out: 50, Sequence: &Sequence{ID: "loop"}},
+	}}}}}}
+	s.Media.Video.Tracks[0].ClipItems[0].Sequence = loop
+
+	if _, err := s.Flatten(); err == nil {
+		t.Error("sequence nesting itself not reported")
+	}
+}
@@ -0,0 +1,739 @@
+package converter
+
+import (
+	"encoding"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Section: Document Layout
+
+// The elements of the raw model are read and written by decodeModel and encodeModel rather than by encoding/xml
+// directly, so that the children of an element that was read are written back in the order they were read, unknown
+// children included, and so that children that were read with a zero value are written back rather than omitted.
+
+// modelField describes a field of a model struct along with the attribute or child element it is read from.
+type modelField struct {
+	index     int
+	name      string
+	omitEmpty bool
+}
+
+// modelType describes the fields of a model struct that are read from XML.
+type modelType struct {
+	// xmlName is the element name given by the XMLName field, if any.
+	xmlName  string
+	attrs    []*modelField
+	elements []*modelField
+	chardata *modelField
+	// elements by name
+	byName map[string]int
+}
+
+var modelTypes sync.Map
+
+// modelTypeOf returns the fields of a model struct. Embedded fields, which hold Extensions, are left out.
+func modelTypeOf(t reflect.Type) *modelType {
+	if mt, ok := modelTypes.Load(t); ok {
+		return mt.(*modelType)
+	}
+
+	mt := &modelType{byName: map[string]int{}}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("xml")
+		if f.Anonymous || f.PkgPath != "" || tag == "-" {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		mf := &modelField{index: i, name: parts[0]}
+		if mf.name == "" {
+			mf.name = f.Name
+		}
+
+		opts := map[string]bool{}
+		for _, o := range parts[1:] {
+			opts[o] = true
+		}
+		mf.omitEmpty = opts["omitempty"]
+
+		switch {
+		case f.Name == "XMLName":
+			mt.xmlName = parts[0]
+		case opts["attr"]:
+			mt.attrs = append(mt.attrs, mf)
+		case opts["chardata"]:
+			mt.chardata = mf
+		default:
+			mt.byName[mf.name] = len(mt.elements)
+			mt.elements = append(mt.elements, mf)
+		}
+	}
+
+	modelTypes.Store(t, mt)
+
+	return mt
+}
+
+// extensionsOf returns the extensions of a model struct.
+func extensionsOf(v reflect.Value) *Extensions {
+	return v.Addr().Interface().(interface{ extensions() *Extensions }).extensions()
+}
+
+// decodeModel reads an element into a model struct, keeping the names of its children in the order they were read.
+// Attributes and children the model does not know are kept as extensions. Character data between children is
+// dropped, as it is indentation rather than content.
+func decodeModel(d *xml.Decoder, start xml.StartElement, v interface{}) error {
+	rv := reflect.ValueOf(v).Elem()
+	mt := modelTypeOf(rv.Type())
+	ext := extensionsOf(rv)
+
+	for _, a := range start.Attr {
+		f := mt.attr(a.Name)
+		if f == nil {
+			ext.UnknownAttrs = append(ext.UnknownAttrs, a)
+			continue
+		}
+
+		if err := setText(rv.Field(f.index), a.Value); err != nil {
+			return err
+		}
+	}
+
+	if ext.children == nil {
+		ext.children = []layoutChild{}
+	}
+
+	var data strings.Builder
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch tt := tok.(type) {
+		case xml.StartElement:
+			if err := decodeChild(d, tt, rv, mt, ext); err != nil {
+				return err
+			}
+		case xml.CharData:
+			data.Write(tt)
+		case xml.EndElement:
+			if mt.chardata != nil && (len(ext.children) == 0 || strings.TrimSpace(data.String()) != "") {
+				f := rv.Field(mt.chardata.index)
+				f.SetString(f.String() + data.String())
+			}
+
+			return nil
+		}
+	}
+}
+
+func decodeChild(d *xml.Decoder, start xml.StartElement, rv reflect.Value, mt *modelType, ext *Extensions) error {
+	i, ok := mt.byName[start.Name.Local]
+	if !ok {
+		ext.children = append(ext.children, layoutChild{name: start.Name.Local})
+		u := &UnknownElement{}
+		if err := d.DecodeElement(u, &start); err != nil {
+			return err
+		}
+		ext.UnknownElements = append(ext.UnknownElements, u)
+
+		return nil
+	}
+
+	f := rv.Field(mt.elements[i].index)
+	v := f
+	if isRepeated(f) {
+		v = reflect.New(f.Type().Elem()).Elem()
+	}
+
+	child := layoutChild{name: start.Name.Local}
+	if isLeafType(indirectType(v.Type())) {
+		s, empty, err := readText(d)
+		if err != nil {
+			return err
+		}
+		child.empty = empty
+
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		if err := setText(v, s); err != nil {
+			return err
+		}
+	} else if err := d.DecodeElement(v.Addr().Interface(), &start); err != nil {
+		return err
+	}
+
+	ext.children = append(ext.children, child)
+	if isRepeated(f) {
+		f.Set(reflect.Append(f, v))
+	}
+
+	return nil
+}
+
+// readText reads the character data of a simple value up to its end element, reporting whether it had no content
+// at all. Child elements are skipped.
+func readText(d *xml.Decoder) (string, bool, error) {
+	var s strings.Builder
+	empty := true
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return "", false, err
+		}
+
+		switch tt := tok.(type) {
+		case xml.CharData:
+			s.Write(tt)
+			empty = false
+		case xml.StartElement:
+			empty = false
+			if err := d.Skip(); err != nil {
+				return "", false, err
+			}
+		case xml.EndElement:
+			return s.String(), empty, nil
+		}
+	}
+}
+
+func (mt *modelType) attr(n xml.Name) *modelField {
+	if n.Space != "" {
+		return nil
+	}
+
+	for _, f := range mt.attrs {
+		if f.name == n.Local {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// isRepeated reports whether a field holds a repeatable element.
+func isRepeated(f reflect.Value) bool {
+	return f.Kind() == reflect.Slice && f.Type().Elem().Kind() != reflect.Uint8
+}
+
+// setText sets a simple value or an attribute value the same way encoding/xml would.
+func setText(v reflect.Value, s string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if strings.TrimSpace(s) == "" {
+			v.SetInt(0)
+			return nil
+		}
+
+		n, err := strconv.ParseInt(strings.TrimSpace(s), 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Float32, reflect.Float64:
+		if strings.TrimSpace(s) == "" {
+			v.SetFloat(0)
+			return nil
+		}
+
+		n, err := strconv.ParseFloat(strings.TrimSpace(s), v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("cannot read %q into %s", s, v.Type())
+	}
+
+	return nil
+}
+
+// getText formats an attribute value the same way encoding/xml would.
+func getText(v reflect.Value) (string, error) {
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		return string(b), err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	}
+
+	return "", fmt.Errorf("cannot write attribute of %s", v.Type())
+}
+
+// encodeModel writes a model struct as an element. The children of a struct that was read are written in the order
+// they were read, and children that were not read are left out unless they have been set since. The children of a
+// struct that was not read are written in the order of its fields. Unknown attributes follow the known attributes.
+func encodeModel(e *xml.Encoder, start xml.StartElement, v interface{}) error {
+	rv := reflect.ValueOf(v).Elem()
+
+	start, err := modelStart(start, rv)
+	if err != nil {
+		return err
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	if err := encodeChildren(e, rv); err != nil {
+		return err
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// modelStart returns the start element of a model struct with its attributes.
+func modelStart(start xml.StartElement, rv reflect.Value) (xml.StartElement, error) {
+	mt := modelTypeOf(rv.Type())
+	if mt.xmlName != "" {
+		start.Name = xml.Name{Local: mt.xmlName}
+	}
+
+	start.Attr = nil
+	for _, f := range mt.attrs {
+		fv := rv.Field(f.index)
+		if f.omitEmpty && fv.IsZero() {
+			continue
+		}
+
+		s, err := getText(fv)
+		if err != nil {
+			return start, err
+		}
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: f.name}, Value: s})
+	}
+	start.Attr = append(start.Attr, extensionsOf(rv).UnknownAttrs...)
+
+	return start, nil
+}
+
+// encodeChildren writes the character data and the children of a model struct. Fields that were set after the
+// struct was read are written ahead of the first child that was read from a later field.
+func encodeChildren(e *xml.Encoder, rv reflect.Value) error {
+	mt := modelTypeOf(rv.Type())
+	ext := extensionsOf(rv)
+	read := ext.children != nil
+
+	if mt.chardata != nil {
+		if s := rv.Field(mt.chardata.index).String(); s != "" {
+			if err := e.EncodeToken(xml.CharData(s)); err != nil {
+				return err
+			}
+		}
+	}
+
+	// position of the last child of every name
+	last := map[string]int{}
+	for i, c := range ext.children {
+		last[c.name] = i
+	}
+
+	var pending []int
+	for i, f := range mt.elements {
+		if _, ok := last[f.name]; ok {
+			continue
+		}
+
+		fv := rv.Field(f.index)
+		if !isAbsent(fv) && !(fv.IsZero() && (read || f.omitEmpty)) {
+			pending = append(pending, i)
+		}
+	}
+
+	next := make([]int, len(mt.elements))
+	done := make([]bool, len(mt.elements))
+	unknown := 0
+
+	list, isList := rv.Addr().Interface().(itemList)
+	itemsDone := false
+	writeItems := func() error {
+		if itemsDone {
+			return nil
+		}
+		itemsDone = true
+
+		for _, it := range list.items() {
+			name := ""
+			for _, f := range mt.elements {
+				if fv := rv.Field(f.index); isRepeated(fv) && fv.Type().Elem() == reflect.TypeOf(it) {
+					name = f.name
+				}
+			}
+			if err := e.EncodeElement(it, xml.StartElement{Name: xml.Name{Local: name}}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	// write writes the elements of a field from the next one up to but not including to. A simple value that was
+	// read empty is written empty while it is unchanged.
+	write := func(i int, to int, empty bool) error {
+		f := mt.elements[i]
+		fv := rv.Field(f.index)
+		child := xml.StartElement{Name: xml.Name{Local: f.name}}
+
+		if isList && isRepeated(fv) {
+			return writeItems()
+		}
+
+		if !isRepeated(fv) {
+			if done[i] || isAbsent(fv) {
+				return nil
+			}
+			done[i] = true
+
+			if empty && fv.IsZero() {
+				if err := e.EncodeToken(child); err != nil {
+					return err
+				}
+				return e.EncodeToken(child.End())
+			}
+
+			return e.EncodeElement(fv.Interface(), child)
+		}
+
+		for ; next[i] < minInt(to, fv.Len()); next[i]++ {
+			if err := e.EncodeElement(fv.Index(next[i]).Interface(), child); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for pos, c := range ext.children {
+		i, ok := mt.byName[c.name]
+		if !ok {
+			if unknown < len(ext.UnknownElements) {
+				if err := e.Encode(ext.UnknownElements[unknown]); err != nil {
+					return err
+				}
+				unknown++
+			}
+			continue
+		}
+
+		for len(pending) > 0 && pending[0] < i {
+			if err := write(pending[0], math.MaxInt32, false); err != nil {
+				return err
+			}
+			pending = pending[1:]
+		}
+
+		// repeated elements appended since the struct was read follow the last one that was read
+		to := next[i] + 1
+		if last[c.name] == pos {
+			to = math.MaxInt32
+		}
+		if err := write(i, to, c.empty); err != nil {
+			return err
+		}
+	}
+
+	for _, i := range pending {
+		if err := write(i, math.MaxInt32, false); err != nil {
+			return err
+		}
+	}
+
+	for _, u := range ext.UnknownElements[unknown:] {
+		if err := e.Encode(u); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// itemList is implemented by model structs that keep the elements of their repeated fields in one list, e.g. the
+// clips, sequences and bins of a bin. Their repeated fields are written as one block, in the order of the list, in
+// the place of the first of them.
+type itemList interface {
+	items() []interface{}
+}
+
+// readOrder lists the elements of the repeated fields of a model struct that was just read, in the order they were
+// read.
+func readOrder(v interface{}) []interface{} {
+	rv := reflect.ValueOf(v).Elem()
+	mt := modelTypeOf(rv.Type())
+	next := map[int]int{}
+
+	var order []interface{}
+	for _, c := range extensionsOf(rv).children {
+		i, ok := mt.byName[c.name]
+		if !ok {
+			continue
+		}
+
+		fv := rv.Field(mt.elements[i].index)
+		if isRepeated(fv) && next[i] < fv.Len() {
+			order = append(order, fv.Index(next[i]).Interface())
+			next[i]++
+		}
+	}
+
+	return order
+}
+
+// keepOrder returns the elements of a list that are still held by slices, in the order of the list, and the
+// elements of the slices that are not in the list, in the order of the slices.
+func keepOrder(order []interface{}, slices ...interface{}) ([]interface{}, []interface{}) {
+	held := map[interface{}]bool{}
+	listed := map[interface{}]bool{}
+	for _, o := range order {
+		listed[o] = true
+	}
+
+	var added []interface{}
+	for _, s := range slices {
+		sv := reflect.ValueOf(s)
+		for i := 0; i < sv.Len(); i++ {
+			e := sv.Index(i).Interface()
+			held[e] = true
+			if !listed[e] {
+				added = append(added, e)
+			}
+		}
+	}
+
+	var kept []interface{}
+	for _, o := range order {
+		if held[o] {
+			kept = append(kept, o)
+		}
+	}
+
+	return kept, added
+}
+
+// isAbsent reports whether a field holds no element at all, the same way encoding/xml leaves out nil pointers.
+func isAbsent(fv reflect.Value) bool {
+	switch fv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return fv.IsNil()
+	case reflect.Slice:
+		return isRepeated(fv) && fv.Len() == 0
+	}
+
+	return false
+}
+
+// Section: Model Marshalling
+
+func (x *RawXEML) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error { return decodeModel(d, s, x) }
+func (x RawXEML) MarshalXML(e *xml.Encoder, s xml.StartElement) error    { return encodeModel(e, s, &x) }
+
+func (p *Project) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error { return decodeModel(d, s, p) }
+func (p Project) MarshalXML(e *xml.Encoder, s xml.StartElement) error    { return encodeModel(e, s, &p) }
+
+func (b *Bin) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error { return decodeModel(d, s, b) }
+func (b Bin) MarshalXML(e *xml.Encoder, s xml.StartElement) error    { return encodeModel(e, s, &b) }
+
+func (c *Children) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error {
+	if err := decodeModel(d, s, c); err != nil {
+		return err
+	}
+	c.order = readOrder(c)
+
+	return nil
+}
+func (c Children) MarshalXML(e *xml.Encoder, s xml.StartElement) error { return encodeModel(e, s, &c) }
+
+func (q *Sequence) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error {
+	return decodeModel(d, s, q)
+}
+func (q Sequence) MarshalXML(e *xml.Encoder, s xml.StartElement) error { return encodeModel(e, s, &q) }
+
+func (m *Media) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error { return decodeModel(d, s, m) }
+func (m Media) MarshalXML(e *xml.Encoder, s xml.StartElement) error    { return encodeModel(e, s, &m) }
+
+func (t *Track) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error {
+	if err := decodeModel(d, s, t); err != nil {
+		return err
+	}
+	t.order = readOrder(t)
+
+	return nil
+}
+func (t Track) MarshalXML(e *xml.Encoder, s xml.StartElement) error { return encodeModel(e, s, &t) }
+
+func (l *Link) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error { return decodeModel(d, s, l) }
+func (l Link) MarshalXML(e *xml.Encoder, s xml.StartElement) error    { return encodeModel(e, s, &l) }
+
+func (c *Clip) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error { return decodeModel(d, s, c) }
+func (c Clip) MarshalXML(e *xml.Encoder, s xml.StartElement) error    { return encodeModel(e, s, &c) }
+
+func (c *ClipItem) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error {
+	return decodeModel(d, s, c)
+}
+func (c ClipItem) MarshalXML(e *xml.Encoder, s xml.StartElement) error { return encodeModel(e, s, &c) }
+
+func (l *LoggingInfo) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error {
+	return decodeModel(d, s, l)
+}
+func (l LoggingInfo) MarshalXML(e *xml.Encoder, s xml.StartElement) error {
+	return encodeModel(e, s, &l)
+}
+
+func (l *Labels) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error { return decodeModel(d, s, l) }
+func (l Labels) MarshalXML(e *xml.Encoder, s xml.StartElement) error    { return encodeModel(e, s, &l) }
+
+func (c *Comments) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error {
+	return decodeModel(d, s, c)
+}
+func (c Comments) MarshalXML(e *xml.Encoder, s xml.StartElement) error { return encodeModel(e, s, &c) }
+
+func (t *SourceTrack) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error {
+	return decodeModel(d, s, t)
+}
+func (t SourceTrack) MarshalXML(e *xml.Encoder, s xml.StartElement) error {
+	return encodeModel(e, s, &t)
+}
+
+func (i *SubClipInfo) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error {
+	return decodeModel(d, s, i)
+}
+func (i SubClipInfo) MarshalXML(e *xml.Encoder, s xml.StartElement) error {
+	return encodeModel(e, s, &i)
+}
+
+func (v *Video) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error { return decodeModel(d, s, v) }
+func (v Video) MarshalXML(e *xml.Encoder, s xml.StartElement) error    { return encodeModel(e, s, &v) }
+
+func (a *Audio) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error { return decodeModel(d, s, a) }
+func (a Audio) MarshalXML(e *xml.Encoder, s xml.StartElement) error    { return encodeModel(e, s, &a) }
+
+func (f *File) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error { return decodeModel(d, s, f) }
+func (f File) MarshalXML(e *xml.Encoder, s xml.StartElement) error    { return encodeModel(e, s, &f) }
+
+func (m *Marker) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error { return decodeModel(d, s, m) }
+func (m Marker) MarshalXML(e *xml.Encoder, s xml.StartElement) error    { return encodeModel(e, s, &m) }
+
+func (r *Rate) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error { return decodeModel(d, s, r) }
+func (r Rate) MarshalXML(e *xml.Encoder, s xml.StartElement) error    { return encodeModel(e, s, &r) }
+
+func (t *TimeCode) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error {
+	return decodeModel(d, s, t)
+}
+func (t TimeCode) MarshalXML(e *xml.Encoder, s xml.StartElement) error { return encodeModel(e, s, &t) }
+
+func (r *Reel) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error { return decodeModel(d, s, r) }
+func (r Reel) MarshalXML(e *xml.Encoder, s xml.StartElement) error    { return encodeModel(e, s, &r) }
+
+func (g *GeneratorItem) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error {
+	return decodeModel(d, s, g)
+}
+func (g GeneratorItem) MarshalXML(e *xml.Encoder, s xml.StartElement) error {
+	return encodeModel(e, s, &g)
+}
+
+func (t *TransitionItem) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error {
+	return decodeModel(d, s, t)
+}
+func (t TransitionItem) MarshalXML(e *xml.Encoder, s xml.StartElement) error {
+	return encodeModel(e, s, &t)
+}
+
+func (f *Filter) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error { return decodeModel(d, s, f) }
+func (f Filter) MarshalXML(e *xml.Encoder, s xml.StartElement) error    { return encodeModel(e, s, &f) }
+
+func (f *Effect) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error { return decodeModel(d, s, f) }
+func (f Effect) MarshalXML(e *xml.Encoder, s xml.StartElement) error    { return encodeModel(e, s, &f) }
+
+func (p *Parameter) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error {
+	return decodeModel(d, s, p)
+}
+func (p Parameter) MarshalXML(e *xml.Encoder, s xml.StartElement) error { return encodeModel(e, s, &p) }
+
+func (l *ValueList) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error {
+	return decodeModel(d, s, l)
+}
+func (l ValueList) MarshalXML(e *xml.Encoder, s xml.StartElement) error { return encodeModel(e, s, &l) }
+
+func (v *ValueEntry) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error {
+	return decodeModel(d, s, v)
+}
+func (v ValueEntry) MarshalXML(e *xml.Encoder, s xml.StartElement) error {
+	return encodeModel(e, s, &v)
+}
+
+func (v *Value) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error { return decodeModel(d, s, v) }
+func (v Value) MarshalXML(e *xml.Encoder, s xml.StartElement) error    { return encodeModel(e, s, &v) }
+
+func (k *KeyFrame) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error {
+	return decodeModel(d, s, k)
+}
+func (k KeyFrame) MarshalXML(e *xml.Encoder, s xml.StartElement) error { return encodeModel(e, s, &k) }
+
+func (b *InBEZ) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error { return decodeModel(d, s, b) }
+func (b InBEZ) MarshalXML(e *xml.Encoder, s xml.StartElement) error    { return encodeModel(e, s, &b) }
+
+func (b *OutBEZ) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error { return decodeModel(d, s, b) }
+func (b OutBEZ) MarshalXML(e *xml.Encoder, s xml.StartElement) error    { return encodeModel(e, s, &b) }
+
+func (i *Interpolation) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error {
+	return decodeModel(d, s, i)
+}
+func (i Interpolation) MarshalXML(e *xml.Encoder, s xml.StartElement) error {
+	return encodeModel(e, s, &i)
+}
+
+func (f *Format) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error { return decodeModel(d, s, f) }
+func (f Format) MarshalXML(e *xml.Encoder, s xml.StartElement) error    { return encodeModel(e, s, &f) }
+
+func (c *SampleCharacteristics) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error {
+	return decodeModel(d, s, c)
+}
+func (c SampleCharacteristics) MarshalXML(e *xml.Encoder, s xml.StartElement) error {
+	return encodeModel(e, s, &c)
+}
+
+func (c *Codec) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error { return decodeModel(d, s, c) }
+func (c Codec) MarshalXML(e *xml.Encoder, s xml.StartElement) error    { return encodeModel(e, s, &c) }
+
+func (o *Outputs) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error { return decodeModel(d, s, o) }
+func (o Outputs) MarshalXML(e *xml.Encoder, s xml.StartElement) error    { return encodeModel(e, s, &o) }
+
+func (g *Group) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error { return decodeModel(d, s, g) }
+func (g Group) MarshalXML(e *xml.Encoder, s xml.StartElement) error    { return encodeModel(e, s, &g) }
+
+func (c *Channel) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error { return decodeModel(d, s, c) }
+func (c Channel) MarshalXML(e *xml.Encoder, s xml.StartElement) error    { return encodeModel(e, s, &c) }
+
+func (a *AppSpecificData) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error {
+	return decodeModel(d, s, a)
+}
+func (a AppSpecificData) MarshalXML(e *xml.Encoder, s xml.StartElement) error {
+	return encodeModel(e, s, &a)
+}
+
+func (f *FilmData) UnmarshalXML(d *xml.Decoder, s xml.StartElement) error {
+	return decodeModel(d, s, f)
+}
+func (f FilmData) MarshalXML(e *xml.Encoder, s xml.StartElement) error { return encodeModel(e, s, &f) }
@@ -0,0 +1,85 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+)
+
+// marshalElement marshals v as an element of a name.
+func marshalElement(t *testing.T, v interface{}, name string) string {
+	var b bytes.Buffer
+	e := xml.NewEncoder(&b)
+	if err := e.EncodeElement(v, xml.StartElement{Name: xml.Name{Local: name}}); err != nil {
+		t.Fatal(name + " could not be marshalled: " + err.Error())
+	}
+	if err := e.Flush(); err != nil {
+		t.Fatal(name + " could not be marshalled: " + err.Error())
+	}
+
+	return b.String()
+}
+
+func TestWritingChildrenInReadOrder(t *testing.T) {
+	xc := `<clipitem id="clipitem-1">
+		<name>Shot</name>
+		<start>0</start>
+		<end>10</end>
+		<pproTicksIn>0</pproTicksIn>
+		<in>0</in>
+		<out>10</out>
+		<marker>
+			<name>A</name>
+			<in>2</in>
+			<out>-1</out>
+		</marker>
+		<anamorphic>false</anamorphic>
+		<logginginfo>
+			<description></description>
+			<good/>
+		</logginginfo>
+	</clipitem>`
+
+	var ci ClipItem
+	if err := xml.Unmarshal([]byte(xc), &ci); err != nil {
+		t.Fatal("clip item could not be unmarshalled: " + err.Error())
+	}
+
+	b := marshalElement(t, ci, "clipitem")
+	want := `<clipitem id="clipitem-1"><name>Shot</name><start>0</start><end>10</end><pproTicksIn>0</pproTicksIn>` +
+		`<in>0</in><out>10</out><marker><name>A</name><in>2</in><out>-1</out></marker><anamorphic>false</anamorphic>` +
+		`<logginginfo><description></description><good></good></logginginfo></clipitem>`
+	if b != want {
+		t.Error("clip item not written back as it was read: " + b)
+	}
+
+	ci.Rate = &Rate{TimeBase: 25}
+	ci.Markers = append(ci.Markers, &Marker{Name: "B", In: 4, Out: -1})
+	ci.LoggingInfo.Good = true
+
+	b = marshalElement(t, ci, "clipitem")
+	want = `<clipitem id="clipitem-1"><name>Shot</name><rate><timebase>25</timebase></rate>` +
+		`<start>0</start><end>10</end><pproTicksIn>0</pproTicksIn><in>0</in><out>10</out>` +
+		`<marker><name>A</name><in>2</in><out>-1</out></marker><marker><name>B</name><in>4</in><out>-1</out></marker>` +
+		`<anamorphic>false</anamorphic><logginginfo><description></description><good>true</good></logginginfo></clipitem>`
+	if b != want {
+		t.Error("fields set after reading not written in place: " + b)
+	}
+}
+
+func TestWritingUnreadElements(t *testing.T) {
+	b := marshalElement(t, &File{ID: "file-1"}, "file")
+	if b != `<file id="file-1"><duration>0</duration></file>` {
+		t.Error("file not written in the order of its fields: " + b)
+	}
+
+	var f File
+	if err := xml.Unmarshal([]byte(`<file id="file-1"/>`), &f); err != nil {
+		t.Fatal("file could not be unmarshalled: " + err.Error())
+	}
+
+	b = marshalElement(t, f, "file")
+	if b != `<file id="file-1"></file>` {
+		t.Error("file reference written with children it did not have: " + b)
+	}
+}
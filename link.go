@@ -0,0 +1,117 @@
+package converter
+
+// LinkGroup describes the clip items of a sequence that are linked together, e.g. a video clip item and the audio
+// clip items recorded with it, in track order.
+type LinkGroup struct {
+	Video []*ClipItem
+	Audio []*ClipItem
+}
+
+// Items lists the video clip items of a link group followed by its audio clip items.
+func (g LinkGroup) Items() []*ClipItem {
+	return append(append([]*ClipItem{}, g.Video...), g.Audio...)
+}
+
+// linkedItem describes where a clip item is in a sequence.
+type linkedItem struct {
+	item  *ClipItem
+	media string
+}
+
+// linkIndex finds the clip items of a sequence by id and by track and clip index.
+type linkIndex struct {
+	items  []linkedItem
+	ids    map[string]*ClipItem
+	tracks map[string][]*Track
+}
+
+func newLinkIndex(s *Sequence) *linkIndex {
+	idx := &linkIndex{ids: map[string]*ClipItem{}, tracks: map[string][]*Track{}}
+	if s.Media == nil {
+		return idx
+	}
+
+	add := func(media string, ts []*Track) {
+		idx.tracks[media] = ts
+		for _, t := range ts {
+			for _, ci := range t.ClipItems {
+				idx.items = append(idx.items, linkedItem{ci, media})
+				if _, ok := idx.ids[ci.ID]; !ok && ci.ID != "" {
+					idx.ids[ci.ID] = ci
+				}
+			}
+		}
+	}
+	if s.Media.Video != nil {
+		add("video", s.Media.Video.Tracks)
+	}
+	if s.Media.Audio != nil {
+		add("audio", s.Media.Audio.Tracks)
+	}
+
+	return idx
+}
+
+// resolve finds the clip item a link points at, by its clip reference or else by its track and clip index. media
+// is the media of the track of the clip item the link belongs to, for links without a media type.
+func (idx *linkIndex) resolve(l *Link, media string) *ClipItem {
+	if l.LinkClipRef != "" {
+		return idx.ids[string(l.LinkClipRef)]
+	}
+
+	if l.MediaType != "" {
+		media = string(l.MediaType)
+	}
+
+	ts := idx.tracks[media]
+	if l.TrackIndex < 1 || int(l.TrackIndex) > len(ts) {
+		return nil
+	}
+
+	items := ts[l.TrackIndex-1].ClipItems
+	if l.ClipIndex < 1 || int(l.ClipIndex) > len(items) {
+		return nil
+	}
+
+	return items[l.ClipIndex-1]
+}
+
+// LinkGroup resolves the links of a clip item of a sequence to the clip items it is linked with, including itself.
+// Links point at clip items by their clip reference, or by their media type, track index and clip index when they
+// have no clip reference. Links that point at nothing are skipped. A clip item without links makes a group of its
+// own, and a clip item that is not part of the sequence makes an empty group.
+func (s *Sequence) LinkGroup(ci *ClipItem) LinkGroup {
+	idx := newLinkIndex(s)
+
+	media := ""
+	for _, li := range idx.items {
+		if li.item == ci {
+			media = li.media
+		}
+	}
+	if media == "" {
+		return LinkGroup{}
+	}
+
+	linked := map[*ClipItem]bool{ci: true}
+	for _, l := range ci.Links {
+		if target := idx.resolve(l, media); target != nil {
+			linked[target] = true
+		}
+	}
+
+	var g LinkGroup
+	for _, li := range idx.items {
+		if !linked[li.item] {
+			continue
+		}
+
+		if li.media == "video" {
+			g.Video = append(g.Video, li.item)
+		} else {
+			g.Audio = append(g.Audio, li.item)
+		}
+	}
+
+	return g
+}
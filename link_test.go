@@ -0,0 +1,71 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"testing"
+)
+
+func TestImportingLinksFromExports(t *testing.T) {
+	s, err := ioutil.ReadFile("export-examples/premier-export.xml")
+	if err != nil {
+		t.Fatal("example export could not be read: " + err.Error())
+	}
+
+	x := ImportRawXEML(s)
+	video := x.Sequence.Media.Video.Tracks[0].ClipItems[0]
+
+	if len(video.Links) != 3 || video.Links[1].LinkClipRef != "clipitem-121" || video.Links[1].MediaType != "audio" {
+		t.Fatal("clip item links not imported")
+	}
+
+	b, _ := xml.Marshal(x)
+	if !bytes.Contains(b, []byte("<linkclipref>clipitem-151</linkclipref>")) {
+		t.Error("link clip reference not written back")
+	}
+
+	g := x.Sequence.LinkGroup(video)
+	if len(g.Video) != 1 || g.Video[0] != video || len(g.Audio) != 2 {
+		t.Fatal("link group does not match expectations")
+	}
+
+	if g.Audio[0].ID != "clipitem-121" || g.Audio[1].ID != "clipitem-151" {
+		t.Error("linked audio clip items not listed in track order")
+	}
+
+	if items := g.Items(); len(items) != 3 || items[0] != video {
+		t.Error("link group items do not match expectations")
+	}
+}
+
+func TestResolvingLinksByIndex(t *testing.T) {
+	video := &ClipItem{ID: "v", Links: []*Link{
+		{MediaType: "video", TrackIndex: 1, ClipIndex: 2},
+		{MediaType: "audio", TrackIndex: 2, ClipIndex: 1},
+		{MediaType: "audio", TrackIndex: 3, ClipIndex: 1},
+	}}
+	audio := &ClipItem{ID: "a"}
+	s := &Sequence{Media: &Media{
+		Video: &Video{Tracks: []*Track{{ClipItems: []*ClipItem{{ID: "first"}, video}}}},
+		Audio: &Audio{Tracks: []*Track{{}, {ClipItems: []*ClipItem{audio}}}},
+	}}
+
+	g := s.LinkGroup(video)
+	if len(g.Video) != 1 || g.Video[0] != video || len(g.Audio) != 1 || g.Audio[0] != audio {
+		t.Error("links not resolved by track and clip index")
+	}
+
+	if g := s.LinkGroup(audio); len(g.Video) != 0 || len(g.Audio) != 1 {
+		t.Error("clip item without links not in a group of its own")
+	}
+
+	if g := s.LinkGroup(&ClipItem{}); len(g.Items()) != 0 {
+		t.Error("clip item outside the sequence not in an empty group")
+	}
+
+	findings := Validate(RawXEML{Sequence: s}, ValidationOptions{Disabled: []string{"sequence-rate"}})
+	if len(findings) != 1 || findings[0].Path != "xmeml/sequence/media/video/track/clipitem[2]/link[3]/trackindex" {
+		t.Errorf("link to a missing track not reported: %v", findings)
+	}
+}
@@ -0,0 +1,201 @@
+package converter
+
+import "fmt"
+
+// MediaRegistry describes the source media of a document. Every occurrence of a file is linked to one canonical
+// file, the first full definition of its id.
+type MediaRegistry struct {
+	// Files lists every unique file in the order they are first defined.
+	Files []*File
+	// Conflicts lists definitions of a file id that disagree with its canonical file.
+	Conflicts []*MediaConflict
+	// Undefined lists occurrences of file ids that are never defined.
+	Undefined []*MediaOccurrence
+
+	occurrences []*MediaOccurrence
+	canonical   map[*File]*File
+	ids         map[string]*File
+}
+
+// MediaOccurrence describes where a file element appears in a document.
+type MediaOccurrence struct {
+	Path string
+	File *File
+}
+
+// MediaConflict describes a file id that is defined more than once with different values.
+type MediaConflict struct {
+	ID           string
+	Path         string
+	Definition   *File
+	Redefinition *File
+}
+
+func (c *MediaConflict) Error() string {
+	return fmt.Sprintf("%s: file id %q is redefined with different values", c.Path, c.ID)
+}
+
+// MediaRegistry builds the media registry of a document.
+func (x *RawXEML) MediaRegistry() *MediaRegistry {
+	r := &MediaRegistry{
+		canonical: map[*File]*File{},
+		ids:       map[string]*File{},
+	}
+
+	x.walkFiles(func(f *File, path string) {
+		r.occurrences = append(r.occurrences, &MediaOccurrence{Path: path, File: f})
+	})
+
+	// definitions come first so that references before a definition still resolve
+	for _, o := range r.occurrences {
+		f := o.File
+		if !f.isDefinition() {
+			continue
+		}
+
+		if f.ID == "" {
+			r.canonical[f] = f
+			r.Files = append(r.Files, f)
+			continue
+		}
+
+		def, ok := r.ids[f.ID]
+		if !ok {
+			r.ids[f.ID] = f
+			r.canonical[f] = f
+			r.Files = append(r.Files, f)
+			continue
+		}
+
+		r.canonical[f] = def
+		if !def.sameDefinition(f) {
+			r.Conflicts = append(r.Conflicts, &MediaConflict{ID: f.ID, Path: o.Path, Definition: def, Redefinition: f})
+		}
+	}
+
+	for _, o := range r.occurrences {
+		f := o.File
+		if f.isDefinition() {
+			continue
+		}
+
+		if def, ok := r.ids[f.ID]; ok {
+			r.canonical[f] = def
+		} else {
+			r.Undefined = append(r.Undefined, o)
+		}
+	}
+
+	return r
+}
+
+// Canonical finds the canonical file of a file occurrence, or nil when the occurrence refers to a file id that is
+// never defined.
+func (r *MediaRegistry) Canonical(f *File) *File {
+	if f == nil {
+		return nil
+	}
+
+	return r.canonical[f]
+}
+
+// Lookup finds the canonical file of a file id.
+func (r *MediaRegistry) Lookup(id string) *File {
+	return r.ids[id]
+}
+
+// Occurrences lists every occurrence of a canonical file in document order.
+func (r *MediaRegistry) Occurrences(f *File) []*MediaOccurrence {
+	var os []*MediaOccurrence
+	for _, o := range r.occurrences {
+		if r.canonical[o.File] == f {
+			os = append(os, o)
+		}
+	}
+
+	return os
+}
+
+// SequenceFiles lists every unique canonical file used by a sequence, including nested sequences.
+func (r *MediaRegistry) SequenceFiles(s *Sequence) []*File {
+	var fs []*File
+	seen := map[*File]bool{}
+
+	s.walkFiles("sequence", func(f *File, path string) {
+		c := r.Canonical(f)
+		if c != nil && !seen[c] {
+			seen[c] = true
+			fs = append(fs, c)
+		}
+	})
+
+	return fs
+}
+
+// isDefinition reports whether a file carries its own definition, rather than referring to one by id.
+func (f *File) isDefinition() bool {
+	return f.Name != "" || f.PathURL != "" || f.Duration != 0 || f.Rate != nil || f.TimeCode != nil || f.Media != nil
+}
+
+// sameDefinition reports whether two file definitions agree on the values that identify their media.
+func (f *File) sameDefinition(g *File) bool {
+	if f.Name != g.Name || f.PathURL != g.PathURL || f.Duration != g.Duration {
+		return false
+	}
+
+	if f.Rate == nil || g.Rate == nil {
+		return true
+	}
+
+	return f.Rate.Equal(*g.Rate)
+}
+
+// walkFiles calls fn for every file element of a document in document order.
+func (x *RawXEML) walkFiles(fn func(f *File, path string)) {
+	_ = x.Walk(func(item BinItem) error {
+		if item.Clip != nil && item.Clip.File != nil {
+			fn(item.Clip.File, childPath(item.Path, "file", 0))
+		}
+		if item.Sequence != nil {
+			item.Sequence.walkFiles(item.Path, fn)
+		}
+
+		return nil
+	})
+}
+
+// walkFiles calls fn for every file element of a sequence, including nested sequences, in document order.
+func (s *Sequence) walkFiles(path string, fn func(f *File, path string)) {
+	if s.Media != nil {
+		mediaPath := childPath(path, "media", 0)
+		if s.Media.Video != nil {
+			walkTrackFiles(s.Media.Video.Tracks, childPath(mediaPath, "video", 0), fn)
+		}
+		if s.Media.Audio != nil {
+			walkTrackFiles(s.Media.Audio.Tracks, childPath(mediaPath, "audio", 0), fn)
+		}
+	}
+
+	if s.File != nil {
+		fn(s.File, childPath(path, "file", 0))
+	}
+}
+
+func walkTrackFiles(ts []*Track, path string, fn func(f *File, path string)) {
+	for i, t := range ts {
+		trackPath := childPath(path, "track", i)
+		for j, ci := range t.ClipItems {
+			ci.walkFiles(childPath(trackPath, "clipitem", j), fn)
+		}
+	}
+}
+
+// walkFiles calls fn for the file element of a clip item and the file elements of its nested sequence.
+func (ci *ClipItem) walkFiles(path string, fn func(f *File, path string)) {
+	if ci.File != nil {
+		fn(ci.File, childPath(path, "file", 0))
+	}
+	if ci.Sequence != nil {
+		ci.Sequence.walkFiles(childPath(path, "sequence", 0), fn)
+	}
+}
@@ -0,0 +1,96 @@
+package converter
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestMediaRegistryLinksFileReferences(t *testing.T) {
+	s, err := ioutil.ReadFile("export-examples/premier-export.xml")
+	if err != nil {
+		t.Fatal("example export could not be read: " + err.Error())
+	}
+
+	x := ImportRawXEML(s)
+	r := x.MediaRegistry()
+
+	if len(r.Files) != 1 {
+		t.Fatalf("unique files do not match expectations: %d", len(r.Files))
+	}
+
+	f := r.Files[0]
+	stub := x.Sequence.Media.Audio.Tracks[1].ClipItems[0].File
+
+	if r.Canonical(stub) != f || r.Lookup("file-2") != f {
+		t.Error("file reference not linked to its definition")
+	}
+
+	os := r.Occurrences(f)
+	if len(os) != 3 {
+		t.Fatalf("file occurrences do not match expectations: %d", len(os))
+	}
+
+	if os[2].Path != "xmeml/sequence/media/audio/track[2]/clipitem/file" {
+		t.Error("file occurrence path does not match expectations: " + os[2].Path)
+	}
+
+	if fs := r.SequenceFiles(x.Sequence); len(fs) != 1 || fs[0] != f {
+		t.Error("sequence files not enumerated")
+	}
+
+	if len(r.Conflicts) != 0 || len(r.Undefined) != 0 {
+		t.Error("unexpected conflicts or undefined files reported")
+	}
+}
+
+func TestMediaRegistryReportsConflicts(t *testing.T) {
+	xc := `<xmeml version="4">
+		<sequence>
+			<media>
+				<video>
+					<track>
+						<clipitem id="clipitem-1">
+							<file id="file-1"/>
+						</clipitem>
+						<clipitem id="clipitem-2">
+							<file id="file-1">
+								<name>a.mov</name>
+								<duration>100</duration>
+							</file>
+						</clipitem>
+						<clipitem id="clipitem-3">
+							<file id="file-1">
+								<name>b.mov</name>
+								<duration>100</duration>
+							</file>
+						</clipitem>
+						<clipitem id="clipitem-4">
+							<file id="file-2"/>
+						</clipitem>
+					</track>
+				</video>
+			</media>
+		</sequence>
+	</xmeml>`
+
+	x := ImportRawXEML([]byte(xc))
+	r := x.MediaRegistry()
+
+	items := x.Sequence.Media.Video.Tracks[0].ClipItems
+
+	if r.Canonical(items[0].File) != items[1].File {
+		t.Error("reference before its definition not linked")
+	}
+
+	if len(r.Conflicts) != 1 || r.Conflicts[0].Redefinition != items[2].File {
+		t.Fatal("conflicting redefinition not reported")
+	}
+
+	if r.Conflicts[0].Path != "xmeml/sequence/media/video/track/clipitem[3]/file" {
+		t.Error("conflict path does not match expectations: " + r.Conflicts[0].Path)
+	}
+
+	if len(r.Undefined) != 1 || r.Canonical(items[3].File) != nil {
+		t.Error("undefined file reference not reported")
+	}
+}
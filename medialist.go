@@ -0,0 +1,362 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MediaListOptions describes how the source ranges of a media list are gathered.
+type MediaListOptions struct {
+	// Handles are the frames added ahead of and after every source range, at the rate of its file. Ranges with
+	// handles are kept within the file when its duration is known.
+	Handles int
+}
+
+// MediaRange describes frames of a file, from In up to but not including Out, counted from the start of the file.
+type MediaRange struct {
+	In  int
+	Out int
+}
+
+// MediaUse describes the source ranges of a file used by a sequence.
+type MediaUse struct {
+	// File is the canonical file, see MediaRegistry.
+	File *File
+	// Path is the path of the file decoded from its path URL, or the path URL when it cannot be decoded.
+	Path string
+	// Reel names the source of the file from the reel of its timecode, or else from its name.
+	Reel string
+	// Rate is the rate of the file, or of the first clip item using it when the file has none.
+	Rate Rate
+	// Ranges are the merged source ranges used, including handles, in source order.
+	Ranges []MediaRange
+	// Items counts the clip items using the file.
+	Items int
+}
+
+// Used returns the number of frames of a file used, including handles.
+func (u *MediaUse) Used() int {
+	n := 0
+	for _, r := range u.Ranges {
+		n += r.Out - r.In
+	}
+
+	return n
+}
+
+// Duration returns the length of a file in frames, or 0 when it is not known.
+func (u *MediaUse) Duration() int {
+	return int(u.File.Duration)
+}
+
+// MediaList describes the source media used by a sequence and the parts of it that are used, e.g. to transcode or
+// archive only what a cut needs.
+type MediaList struct {
+	Sequence *Sequence
+	Handles  int
+	// Uses lists every file used by the sequence in the order it is first used.
+	Uses []*MediaUse
+}
+
+// MediaList gathers the source range of every clip item of a sequence playing a file, including the clip items of
+// nested sequences as far as the clip item nesting them plays them, and merges the ranges of each file once handles
+// are added. Ranges that overlap or touch become one range. Source ranges are converted to the rate of their file when
+// the clip item has a different rate. Clip items with a speed change contribute their source range as it is written.
+func (s *Sequence) MediaList(o MediaListOptions) (*MediaList, error) {
+	if s.Rate == nil || s.Rate.TimeBase <= 0 {
+		return nil, errors.New("sequence has no rate")
+	}
+	if o.Handles < 0 {
+		return nil, errors.New("handles must not be negative")
+	}
+
+	ml := &MediaList{Sequence: s, Handles: o.Handles}
+	reg := (&RawXEML{Sequence: s}).MediaRegistry()
+	uses := map[*File]*MediaUse{}
+
+	// walk gathers the clip items of a sequence, keeping nested ones within the window played by the item nesting it
+	var walk func(s *Sequence, rate Rate, w *nestedWindow)
+	walk = func(s *Sequence, rate Rate, w *nestedWindow) {
+		rate = rateOf(s.Rate, rate)
+		if s.Media == nil {
+			return
+		}
+
+		for _, t := range s.Media.tracks() {
+			edits, _ := editTrack(t)
+			for _, e := range edits {
+				ci := e.item
+				itemRate := rateOf(ci.Rate, rate)
+				srcIn, srcOut := ci.In, ci.Out
+				if w != nil {
+					from, to, ok := w.trim(e.start, e.end)
+					if !ok {
+						continue
+					}
+					srcIn, srcOut = w.source(int(ci.In), int(ci.Out), ci.Rate, from-e.start, e.end-to)
+				}
+
+				if ci.Sequence != nil {
+					walk(ci.Sequence, itemRate, mediaWindow(e, srcIn, srcOut, rate, itemRate, ci.Sequence))
+				}
+
+				f := reg.Canonical(ci.File)
+				if f == nil || srcOut <= out(srcIn) {
+					continue
+				}
+
+				u, ok := uses[f]
+				if !ok {
+					u = newMediaUse(ci, f, rateOf(f.Rate, itemRate))
+					uses[f] = u
+					ml.Uses = append(ml.Uses, u)
+				}
+
+				u.Items++
+				u.Ranges = append(u.Ranges, MediaRange{
+					In:  ConvertFrames(int(srcIn), itemRate, u.Rate) - o.Handles,
+					Out: ConvertFrames(int(srcOut), itemRate, u.Rate) + o.Handles,
+				})
+			}
+		}
+	}
+	walk(s, *s.Rate, nil)
+
+	for _, u := range ml.Uses {
+		u.Ranges = mergeRanges(u.Ranges, u.Duration())
+	}
+
+	return ml, nil
+}
+
+// mediaWindow gives the part of a nested sequence played by the clip item of an edit between a source in and out
+// at the rate of the item, in frames of the nested sequence. A clip item without a source range plays its length.
+func mediaWindow(e *trackEdit, srcIn in, srcOut out, rate Rate, itemRate Rate, nested *Sequence) *nestedWindow {
+	r := rateOf(nested.Rate, itemRate)
+	w := &nestedWindow{in: ConvertFrames(int(srcIn), itemRate, r), out: ConvertFrames(int(srcOut), itemRate, r), rate: r}
+	if int(srcOut) <= int(srcIn) {
+		w.out = w.in + ConvertFrames(e.end-e.start, rate, r)
+	}
+
+	return w
+}
+
+func newMediaUse(ci *ClipItem, f *File, rate Rate) *MediaUse {
+	u := &MediaUse{File: f, Path: string(f.PathURL), Reel: reelName(ci, f, f.TimeCode), Rate: rate}
+	if p, err := DecodeFileURL(u.Path); err == nil {
+		u.Path = p
+	}
+
+	return u
+}
+
+// mergeRanges sorts ranges, keeps them within a file of a duration when it is known, and merges the ranges that
+// overlap or touch.
+func mergeRanges(ranges []MediaRange, duration int) []MediaRange {
+	for i := range ranges {
+		ranges[i].In = maxInt(ranges[i].In, 0)
+		if duration > 0 {
+			ranges[i].Out = minInt(ranges[i].Out, duration)
+		}
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].In < ranges[j].In
+	})
+
+	var merged []MediaRange
+	for _, r := range ranges {
+		if r.Out <= r.In {
+			continue
+		}
+
+		if n := len(merged); n > 0 && r.In <= merged[n-1].Out {
+			merged[n-1].Out = maxInt(merged[n-1].Out, r.Out)
+			continue
+		}
+		merged = append(merged, r)
+	}
+
+	return merged
+}
+
+// sourceTimeCoder formats frames of a file as source timecode, from the start timecode of the file.
+func (u *MediaUse) sourceTimeCoder() func(frame int) string {
+	return timeCoder(u.File.TimeCode, u.Rate)
+}
+
+// Section: Media List Output
+
+// roundFPS returns the frames per second of a rate as it is usually written, e.g. 23.976 or 29.97.
+func roundFPS(r Rate) float64 {
+	return math.Round(r.FPS()*1000) / 1000
+}
+
+var mediaListColumns = []string{
+	"File", "Path", "Reel", "Range", "Source In", "Source Out", "In", "Out", "Frames", "Used Frames", "Duration",
+}
+
+// WriteCSV writes a media list as CSV with a header row and a row for every source range, along with the frames of
+// the file used and its duration, which is empty when it is not known. Out and Source Out are exclusive.
+func (ml *MediaList) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(mediaListColumns); err != nil {
+		return err
+	}
+
+	for _, u := range ml.Uses {
+		source := u.sourceTimeCoder()
+		duration := ""
+		if u.Duration() > 0 {
+			duration = strconv.Itoa(u.Duration())
+		}
+
+		for i, r := range u.Ranges {
+			err := cw.Write([]string{
+				fileName(u.File), u.Path, u.Reel, strconv.Itoa(i + 1), source(r.In), source(r.Out),
+				strconv.Itoa(r.In), strconv.Itoa(r.Out), strconv.Itoa(r.Out - r.In), strconv.Itoa(u.Used()), duration,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+type mediaListJSON struct {
+	Sequence string          `json:"sequence"`
+	Handles  int             `json:"handles"`
+	Files    []mediaUseJSON  `json:"files"`
+	Totals   mediaTotalsJSON `json:"totals"`
+}
+
+type mediaUseJSON struct {
+	Name     string           `json:"name"`
+	Path     string           `json:"path"`
+	Reel     string           `json:"reel"`
+	Rate     float64          `json:"rate"`
+	Items    int              `json:"items"`
+	Ranges   []mediaRangeJSON `json:"ranges"`
+	Used     int              `json:"used"`
+	Duration int              `json:"duration,omitempty"`
+}
+
+type mediaRangeJSON struct {
+	In        int    `json:"in"`
+	Out       int    `json:"out"`
+	SourceIn  string `json:"sourceIn"`
+	SourceOut string `json:"sourceOut"`
+}
+
+type mediaTotalsJSON struct {
+	Files int `json:"files"`
+	// Used and Duration are frame counts at the rates of the files.
+	Used     int `json:"used"`
+	Duration int `json:"duration"`
+}
+
+// WriteJSON writes a media list as JSON, with the source ranges of every file and the total frames used.
+func (ml *MediaList) WriteJSON(w io.Writer) error {
+	doc := mediaListJSON{Sequence: string(ml.Sequence.Name), Handles: ml.Handles, Files: []mediaUseJSON{}}
+	for _, u := range ml.Uses {
+		source := u.sourceTimeCoder()
+		f := mediaUseJSON{
+			Name:     fileName(u.File),
+			Path:     u.Path,
+			Reel:     u.Reel,
+			Rate:     roundFPS(u.Rate),
+			Items:    u.Items,
+			Ranges:   []mediaRangeJSON{},
+			Used:     u.Used(),
+			Duration: u.Duration(),
+		}
+		for _, r := range u.Ranges {
+			f.Ranges = append(f.Ranges, mediaRangeJSON{r.In, r.Out, source(r.In), source(r.Out)})
+		}
+
+		doc.Files = append(doc.Files, f)
+		doc.Totals.Files++
+		doc.Totals.Used += f.Used
+		doc.Totals.Duration += f.Duration
+	}
+
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+
+	return e.Encode(doc)
+}
+
+// ByRate splits a media list into media lists with files of a single rate, in the order the rates are first used,
+// e.g. to write an ALE for each.
+func (ml *MediaList) ByRate() []*MediaList {
+	var lists []*MediaList
+	for _, u := range ml.Uses {
+		var list *MediaList
+		for _, l := range lists {
+			if l.Uses[0].Rate.Equal(u.Rate) {
+				list = l
+				break
+			}
+		}
+		if list == nil {
+			list = &MediaList{Sequence: ml.Sequence, Handles: ml.Handles}
+			lists = append(lists, list)
+		}
+		list.Uses = append(list.Uses, u)
+	}
+
+	return lists
+}
+
+// WriteALE writes a media list as an Avid Log Exchange file with a clip for every source range, named after its file
+// and numbered when the file has more than one range. The heading takes the rate of the files, or of the sequence
+// when there are none. An ALE has a single rate, so a media list of files at different rates is refused, see ByRate.
+func (ml *MediaList) WriteALE(w io.Writer) error {
+	r := *ml.Sequence.Rate
+	if lists := ml.ByRate(); len(lists) > 1 {
+		var fps []string
+		for _, l := range lists {
+			fps = append(fps, strconv.FormatFloat(roundFPS(l.Uses[0].Rate), 'f', -1, 64))
+		}
+
+		return fmt.Errorf("files have different rates: %s", strings.Join(fps, ", "))
+	} else if len(lists) == 1 {
+		r = lists[0].Uses[0].Rate
+	}
+
+	var b bytes.Buffer
+	fps := strconv.FormatFloat(roundFPS(r), 'f', -1, 64)
+	fmt.Fprintf(&b, "Heading\nFIELD_DELIM\tTABS\nFPS\t%s\n\n", fps)
+	fmt.Fprint(&b, "Column\nName\tTape\tStart\tEnd\tDuration\tSource File\n\nData\n")
+
+	clean := strings.NewReplacer("\t", " ", "\n", " ")
+	for _, u := range ml.Uses {
+		source := u.sourceTimeCoder()
+		for i, r := range u.Ranges {
+			name := strings.TrimSuffix(fileName(u.File), path.Ext(fileName(u.File)))
+			if len(u.Ranges) > 1 {
+				name = fmt.Sprintf("%s_%d", name, i+1)
+			}
+
+			fmt.Fprintf(&b, "%s\t%s\t%s\t%s\t%s\t%s\n", clean.Replace(name), clean.Replace(u.Reel), source(r.In),
+				source(r.Out), FormatTimeCode(r.Out-r.In, u.Rate, false), clean.Replace(u.Path))
+		}
+	}
+
+	_, err := b.WriteTo(w)
+
+	return err
+}
@@ -0,0 +1,187 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func mediaListSequence() *Sequence {
+	a := &File{
+		ID: "file-1", Name: "A001.mov", PathURL: "file://localhost/Volumes/Media/A001.mov", Duration: 500,
+		Rate:     &Rate{TimeBase: 25},
+		TimeCode: &TimeCode{Rate: &Rate{TimeBase: 25}, TimeCodeString: "10:00:00:00", DisplayFormat: timeCodeNonDropFrame, Reel: &Reel{Name: "A001"}},
+	}
+	b := &File{ID: "file-2", Name: "B001.wav", PathURL: "file:///Volumes/Sound/B%20001.wav", Duration: 1000}
+
+	return &Sequence{
+		Name: "Cut",
+		Rate: &Rate{TimeBase: 25},
+		Media: &Media{
+			Video: &Video{Tracks: []*Track{{ClipItems: []*ClipItem{
+				{ID: "1", Start: 0, End: 50, In: 10, Out: 60, File: a},
+				{ID: "2", Start: 50, End: 75, In: 70, Out: 95, File: &File{ID: "file-1"}},
+				{ID: "3", Start: 75, End: 100, In: 300, Out: 325, File: &File{ID: "file-1"}},
+				{ID: "4", Start: 100, End: 110, In: 495, Out: 500, File: &File{ID: "file-1"}},
+			}}}},
+			Audio: &Audio{Tracks: []*Track{{ClipItems: []*ClipItem{
+				{ID: "5", Start: 0, End: 100, In: 0, Out: 100, File: b},
+			}}}},
+		},
+	}
+}
+
+func TestMakingMediaLists(t *testing.T) {
+	ml, err := mediaListSequence().MediaList(MediaListOptions{Handles: 10})
+	if err != nil {
+		t.Fatal("media list could not be made: " + err.Error())
+	}
+
+	if len(ml.Uses) != 2 {
+		t.Fatalf("files do not match expectations: %d", len(ml.Uses))
+	}
+
+	a := ml.Uses[0]
+	want := []MediaRange{{0, 105}, {290, 335}, {485, 500}}
+	if len(a.Ranges) != len(want) {
+		t.Fatalf("ranges do not match expectations: %v", a.Ranges)
+	}
+	for i, r := range want {
+		if a.Ranges[i] != r {
+			t.Errorf("range %d does not match expectations: %v", i+1, a.Ranges[i])
+		}
+	}
+
+	if a.Items != 4 || a.Used() != 165 || a.Duration() != 500 || a.Reel != "A001" || a.Path != "/Volumes/Media/A001.mov" {
+		t.Error("file use does not match expectations")
+	}
+
+	if b := ml.Uses[1]; b.Reel != "B001" || b.Path != "/Volumes/Sound/B 001.wav" || len(b.Ranges) != 1 || b.Ranges[0] != (MediaRange{0, 110}) {
+		t.Error("file without a rate or timecode does not match expectations")
+	}
+
+	if _, err := mediaListSequence().MediaList(MediaListOptions{Handles: -1}); err == nil {
+		t.Error("negative handles not reported")
+	}
+}
+
+func TestMakingMediaListsAcrossRates(t *testing.T) {
+	s := mediaListSequence()
+	s.Media.Video.Tracks[0].ClipItems[0].Rate = &Rate{TimeBase: 50}
+
+	ml, _ := s.MediaList(MediaListOptions{})
+	if r := ml.Uses[0].Ranges[0]; r != (MediaRange{5, 30}) {
+		t.Errorf("source range not converted to the rate of the file: %v", r)
+	}
+
+	x := ImportRawXEML(mustRead(t, "export-examples/premier-export.xml"))
+	ml, err := x.Sequence.MediaList(MediaListOptions{Handles: 24})
+	if err != nil || len(ml.Uses) != 1 || ml.Uses[0].Items != 3 || ml.Uses[0].Used() > ml.Uses[0].Duration() {
+		t.Error("media list of an export does not match expectations")
+	}
+}
+
+func TestMakingMediaListsOfNestedSequences(t *testing.T) {
+	a := &File{ID: "file-a", Name: "A.mov", PathURL: "file:///A.mov", Duration: 500}
+	b := &File{ID: "file-b", Name: "B.mov", PathURL: "file:///B.mov", Duration: 500}
+	nested := &Sequence{ID: "sequence-2", Rate: &Rate{TimeBase: 25}, Media: &Media{Video: &Video{Tracks: []*Track{{
+		ClipItems: []*ClipItem{
+			{ID: "a", Start: 0, End: 20, In: 100, Out: 120, File: a},
+			{ID: "b", Start: 20, End: 40, In: 200, Out: 220, File: b},
+		},
+	}}}}}
+	s := &Sequence{Rate: &Rate{TimeBase: 25}, Media: &Media{Video: &Video{Tracks: []*Track{{ClipItems: []*ClipItem{
+		{ID: "nest", Start: 0, End: 10, In: 0, Out: 10, Sequence: nested},
+	}}}}}}
+
+	ml, err := s.MediaList(MediaListOptions{})
+	if err != nil {
+		t.Fatal("media list could not be made: " + err.Error())
+	}
+
+	if len(ml.Uses) != 1 || ml.Uses[0].File != a {
+		t.Fatalf("files outside the played part of a nested sequence reported: %d", len(ml.Uses))
+	}
+	if r := ml.Uses[0].Ranges; len(r) != 1 || r[0] != (MediaRange{100, 110}) {
+		t.Errorf("nested source range not trimmed to the played part: %v", r)
+	}
+
+	s.Media.Video.Tracks[0].ClipItems[0].In, s.Media.Video.Tracks[0].ClipItems[0].Out = 15, 25
+	ml, _ = s.MediaList(MediaListOptions{})
+	if len(ml.Uses) != 2 || ml.Uses[0].Ranges[0] != (MediaRange{115, 120}) || ml.Uses[1].Ranges[0] != (MediaRange{200, 205}) {
+		t.Error("nested source ranges across an edit not trimmed to the played part")
+	}
+}
+
+func TestWritingMediaLists(t *testing.T) {
+	ml, _ := mediaListSequence().MediaList(MediaListOptions{Handles: 10})
+
+	var c bytes.Buffer
+	if err := ml.WriteCSV(&c); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(c.String(), "\n")
+	if len(lines) != 6 || lines[0] != "File,Path,Reel,Range,Source In,Source Out,In,Out,Frames,Used Frames,Duration" {
+		t.Fatalf("CSV does not match expectations:\n%s", c.String())
+	}
+	if lines[2] != "A001.mov,/Volumes/Media/A001.mov,A001,2,10:00:11:15,10:00:13:10,290,335,45,165,500" {
+		t.Errorf("CSV row does not match expectations: %s", lines[2])
+	}
+
+	var j bytes.Buffer
+	if err := ml.WriteJSON(&j); err != nil {
+		t.Fatal(err)
+	}
+	var doc mediaListJSON
+	if err := json.Unmarshal(j.Bytes(), &doc); err != nil {
+		t.Fatal("JSON could not be read: " + err.Error())
+	}
+	if doc.Totals.Files != 2 || doc.Totals.Used != 275 || doc.Totals.Duration != 1500 || doc.Files[0].Ranges[0].SourceIn != "10:00:00:00" {
+		t.Errorf("JSON does not match expectations: %s", j.String())
+	}
+
+	var a bytes.Buffer
+	if err := ml.WriteALE(&a); err != nil {
+		t.Fatal(err)
+	}
+	ale := a.String()
+	if !strings.HasPrefix(ale, "Heading\nFIELD_DELIM\tTABS\nFPS\t25\n\nColumn\nName\tTape\tStart\tEnd\tDuration\tSource File\n\nData\n") {
+		t.Errorf("ALE heading does not match expectations:\n%s", ale)
+	}
+	if !strings.Contains(ale, "A001_3\tA001\t10:00:19:10\t10:00:20:00\t00:00:00:15\t/Volumes/Media/A001.mov\n") ||
+		!strings.Contains(ale, "B001\tB001\t00:00:00:00\t00:00:04:10\t00:00:04:10\t/Volumes/Sound/B 001.wav\n") {
+		t.Errorf("ALE clips do not match expectations:\n%s", ale)
+	}
+}
+
+func TestWritingALEsOfFilesAtDifferentRates(t *testing.T) {
+	s := mediaListSequence()
+	film := &Rate{TimeBase: 24, NTSC: true}
+	s.Media.Audio.Tracks[0].ClipItems[0].File.Rate = film
+	s.Media.Audio.Tracks[0].ClipItems[0].File.TimeCode = &TimeCode{Rate: film, TimeCodeString: "01:00:00:00",
+		DisplayFormat: timeCodeNonDropFrame}
+
+	ml, err := s.MediaList(MediaListOptions{})
+	if err != nil {
+		t.Fatal("media list could not be made: " + err.Error())
+	}
+
+	var b bytes.Buffer
+	if err := ml.WriteALE(&b); err == nil || b.Len() != 0 {
+		t.Error("ALE of files at different rates not refused")
+	}
+
+	lists := ml.ByRate()
+	if len(lists) != 2 || len(lists[0].Uses) != 1 || lists[0].Uses[0].Reel != "A001" || lists[1].Uses[0].Reel != "B001" {
+		t.Fatal("media list not split by rate")
+	}
+
+	var a bytes.Buffer
+	if err := lists[1].WriteALE(&a); err != nil {
+		t.Fatal(err)
+	}
+	if ale := a.String(); !strings.Contains(ale, "FPS\t23.976\n") || !strings.Contains(ale, "\t01:00:00:00\t01:00:04:00\t") {
+		t.Errorf("ALE not written at the rate of its files:\n%s", ale)
+	}
+}
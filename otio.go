@@ -0,0 +1,856 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	otioMetadataKey    = "fcp_xml"
+	otioDissolve       = "SMPTE_Dissolve"
+	otioCustom         = "Custom_Transition"
+	otioVideo          = "Video"
+	otioAudio          = "Audio"
+	otioMarkerColor    = "RED"
+	otioSequenceKey    = "sequence"
+	otioLayoutKey      = "#layout"
+	otioTimelineSchema = "Timeline.1"
+)
+
+// Section: OTIO Schema
+
+// otioRationalTime describes an OTIO time as a number of frames at a rate.
+type otioRationalTime struct {
+	Schema string  `json:"OTIO_SCHEMA"`
+	Rate   float64 `json:"rate"`
+	Value  float64 `json:"value"`
+}
+
+// otioTimeRange describes an OTIO range of time.
+type otioTimeRange struct {
+	Schema    string           `json:"OTIO_SCHEMA"`
+	StartTime otioRationalTime `json:"start_time"`
+	Duration  otioRationalTime `json:"duration"`
+}
+
+// otioTimeline describes an OTIO timeline and the stack of its tracks.
+type otioTimeline struct {
+	Schema          string            `json:"OTIO_SCHEMA"`
+	Metadata        otioMetadata      `json:"metadata"`
+	Name            string            `json:"name"`
+	GlobalStartTime *otioRationalTime `json:"global_start_time"`
+	Tracks          *otioStack        `json:"tracks"`
+}
+
+// otioStack describes tracks played on top of each other, the tracks of a timeline or of a nested sequence.
+type otioStack struct {
+	Schema      string         `json:"OTIO_SCHEMA"`
+	Metadata    otioMetadata   `json:"metadata"`
+	Name        string         `json:"name"`
+	SourceRange *otioTimeRange `json:"source_range"`
+	Effects     []interface{}  `json:"effects"`
+	Markers     []*otioMarker  `json:"markers"`
+	Children    otioChildren   `json:"children"`
+}
+
+// otioTrack describes items played one after another.
+type otioTrack struct {
+	Schema      string         `json:"OTIO_SCHEMA"`
+	Metadata    otioMetadata   `json:"metadata"`
+	Name        string         `json:"name"`
+	SourceRange *otioTimeRange `json:"source_range"`
+	Effects     []interface{}  `json:"effects"`
+	Markers     []*otioMarker  `json:"markers"`
+	Kind        string         `json:"kind"`
+	Children    otioChildren   `json:"children"`
+}
+
+// otioClip describes a range of media. Clip.1 holds one media reference, Clip.2 a map of them.
+type otioClip struct {
+	Schema          string                    `json:"OTIO_SCHEMA"`
+	Metadata        otioMetadata              `json:"metadata"`
+	Name            string                    `json:"name"`
+	SourceRange     *otioTimeRange            `json:"source_range"`
+	Effects         []interface{}             `json:"effects"`
+	Markers         []*otioMarker             `json:"markers"`
+	MediaReference  *otioReference            `json:"media_reference,omitempty"`
+	MediaReferences map[string]*otioReference `json:"media_references,omitempty"`
+	ActiveReference string                    `json:"active_media_reference_key,omitempty"`
+}
+
+// otioGap describes empty time in a track.
+type otioGap struct {
+	Schema      string         `json:"OTIO_SCHEMA"`
+	Metadata    otioMetadata   `json:"metadata"`
+	Name        string         `json:"name"`
+	SourceRange *otioTimeRange `json:"source_range"`
+	Effects     []interface{}  `json:"effects"`
+	Markers     []*otioMarker  `json:"markers"`
+}
+
+// otioTransition describes a transition across the cut between its neighbours.
+type otioTransition struct {
+	Schema         string           `json:"OTIO_SCHEMA"`
+	Metadata       otioMetadata     `json:"metadata"`
+	Name           string           `json:"name"`
+	TransitionType string           `json:"transition_type"`
+	InOffset       otioRationalTime `json:"in_offset"`
+	OutOffset      otioRationalTime `json:"out_offset"`
+}
+
+// otioMarker describes a marker. Marker.1 has no comment.
+type otioMarker struct {
+	Schema      string        `json:"OTIO_SCHEMA"`
+	Metadata    otioMetadata  `json:"metadata"`
+	Name        string        `json:"name"`
+	Color       string        `json:"color"`
+	MarkedRange otioTimeRange `json:"marked_range"`
+	Comment     string        `json:"comment"`
+}
+
+// otioReference describes an external or missing media reference.
+type otioReference struct {
+	Schema         string         `json:"OTIO_SCHEMA"`
+	Metadata       otioMetadata   `json:"metadata"`
+	Name           string         `json:"name"`
+	AvailableRange *otioTimeRange `json:"available_range"`
+	TargetURL      string         `json:"target_url,omitempty"`
+}
+
+// otioChildren describes the children of a stack or track, read according to their schema.
+type otioChildren []interface{}
+
+func (c *otioChildren) UnmarshalJSON(b []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*c = nil
+	for _, r := range raw {
+		var head struct {
+			Schema string `json:"OTIO_SCHEMA"`
+		}
+		if err := json.Unmarshal(r, &head); err != nil {
+			return err
+		}
+
+		var v interface{}
+		switch otioSchemaName(head.Schema) {
+		case "Stack":
+			v = &otioStack{}
+		case "Track":
+			v = &otioTrack{}
+		case "Clip":
+			v = &otioClip{}
+		case "Gap":
+			v = &otioGap{}
+		case "Transition":
+			v = &otioTransition{}
+		default:
+			return &json.UnsupportedValueError{Str: "OTIO schema " + strconv.Quote(head.Schema) + " is not supported"}
+		}
+
+		if err := json.Unmarshal(r, v); err != nil {
+			return err
+		}
+		*c = append(*c, v)
+	}
+
+	return nil
+}
+
+// otioSchemaName drops the version of a schema, e.g. Clip.2 is a Clip.
+func otioSchemaName(schema string) string {
+	return strings.SplitN(schema, ".", 2)[0]
+}
+
+func otioTime(frames int, r Rate) otioRationalTime {
+	return otioRationalTime{Schema: "RationalTime.1", Rate: r.FPS(), Value: float64(frames)}
+}
+
+func otioRange(frames int, length int, r Rate) *otioTimeRange {
+	return &otioTimeRange{Schema: "TimeRange.1", StartTime: otioTime(frames, r), Duration: otioTime(length, r)}
+}
+
+// frames converts a time to the nearest frame count at a rate. Rates written rounded, e.g. 23.976, count frames
+// at the time base they round to.
+func (t otioRationalTime) frames(r Rate) int {
+	if t.Rate <= 0 || otioRate(t.Rate).Equal(r) {
+		return int(math.Round(t.Value))
+	}
+
+	return int(math.Round(t.Value * r.FPS() / t.Rate))
+}
+
+// otioRate converts an OTIO rate to the nearest time base, e.g. 23.976 to 24 fps NTSC.
+func otioRate(fps float64) Rate {
+	tb := math.Round(fps)
+
+	return Rate{TimeBase: int(tb), NTSC: math.Abs(fps-tb) > 1e-3}
+}
+
+// Section: Metadata
+
+// otioMetadata describes the metadata of an OTIO object. The fcp_xml namespace is read as an fcpDict, so that the
+// order of attributes and children survives a round trip.
+type otioMetadata map[string]interface{}
+
+func (m *otioMetadata) UnmarshalJSON(b []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*m = otioMetadata{}
+	for k, r := range raw {
+		if k == otioMetadataKey {
+			var d fcpDict
+			if err := json.Unmarshal(r, &d); err != nil {
+				return err
+			}
+			(*m)[k] = d
+
+			continue
+		}
+
+		var v interface{}
+		if err := json.Unmarshal(r, &v); err != nil {
+			return err
+		}
+		(*m)[k] = v
+	}
+
+	return nil
+}
+
+// fcpDict describes an element converted to OTIO metadata, keys in the order of the attributes and children of
+// the element. Values are strings, dicts, or lists of them.
+type fcpDict []fcpEntry
+
+type fcpEntry struct {
+	key   string
+	value interface{}
+}
+
+func (d fcpDict) get(key string) (interface{}, bool) {
+	for _, e := range d {
+		if e.key == key {
+			return e.value, true
+		}
+	}
+
+	return nil, false
+}
+
+func (d fcpDict) has(key string) bool {
+	_, ok := d.get(key)
+
+	return ok
+}
+
+// set replaces the value of a key, or adds the key at the end.
+func (d *fcpDict) set(key string, v interface{}) {
+	for i, e := range *d {
+		if e.key == key {
+			(*d)[i].value = v
+			return
+		}
+	}
+
+	*d = append(*d, fcpEntry{key: key, value: v})
+}
+
+func (d fcpDict) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, e := range d {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		k, err := json.Marshal(e.key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(e.value)
+		if err != nil {
+			return nil, err
+		}
+
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+
+	return b.Bytes(), nil
+}
+
+func (d *fcpDict) UnmarshalJSON(b []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	v, err := fcpReadJSON(dec)
+	if err != nil {
+		return err
+	}
+
+	dict, ok := v.(fcpDict)
+	if !ok {
+		return errors.New("fcp_xml metadata is not an object")
+	}
+	*d = dict
+
+	return nil
+}
+
+// fcpReadJSON reads a JSON value, objects as dicts in key order.
+func fcpReadJSON(dec *json.Decoder) (interface{}, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t {
+	case json.Delim('{'):
+		d := fcpDict{}
+		for dec.More() {
+			k, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := fcpReadJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			d = append(d, fcpEntry{key: k.(string), value: v})
+		}
+		_, err := dec.Token()
+
+		return d, err
+	case json.Delim('['):
+		l := []interface{}{}
+		for dec.More() {
+			v, err := fcpReadJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			l = append(l, v)
+		}
+		_, err := dec.Token()
+
+		return l, err
+	}
+
+	return t, nil
+}
+
+// fcpNode describes an XML element while it is converted to and from OTIO metadata.
+type fcpNode struct {
+	attrs    []xml.Attr
+	children []*fcpNode
+	name     string
+	text     string
+	// names of the children in order, kept once some of them are dropped
+	layout []interface{}
+}
+
+// newFCPDict converts an element to OTIO metadata in the style of the OTIO FCP 7 XML adapter: attributes are keys
+// prefixed with @, children are keys holding a value, or a list of values when repeated, and elements holding only
+// text are strings. Text next to attributes or children is kept as #text. The children named by drop, or by a path
+// such as media/video/track, are left out, as they are written as OTIO objects. An element that loses children keeps
+// the names of all of its children in order as #layout, so that ParseOTIO puts them back in place.
+func newFCPDict(v interface{}, drop ...string) (fcpDict, error) {
+	b, err := xml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	d := xml.NewDecoder(bytes.NewReader(b))
+	var stack []*fcpNode
+	var root *fcpNode
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := t.(type) {
+		case xml.StartElement:
+			n := &fcpNode{name: t.Name.Local, attrs: t.Attr}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+		}
+	}
+
+	for _, k := range drop {
+		root.drop(strings.Split(k, "/"))
+	}
+
+	return fcpMap(root), nil
+}
+
+// drop removes the children at a path below an element, keeping the layout of the element they are removed from.
+func (n *fcpNode) drop(path []string) {
+	var kept []*fcpNode
+	for _, c := range n.children {
+		switch {
+		case c.name != path[0]:
+			kept = append(kept, c)
+		case len(path) > 1:
+			c.drop(path[1:])
+			kept = append(kept, c)
+		case n.layout == nil:
+			for _, c := range n.children {
+				n.layout = append(n.layout, c.name)
+			}
+		}
+	}
+	n.children = kept
+}
+
+func fcpMap(n *fcpNode) fcpDict {
+	m := fcpDict{}
+	for _, a := range n.attrs {
+		m.set("@"+a.Name.Local, a.Value)
+	}
+	for _, c := range n.children {
+		v := fcpValue(c)
+		switch prev, _ := m.get(c.name); prev := prev.(type) {
+		case nil:
+			m.set(c.name, v)
+		case []interface{}:
+			m.set(c.name, append(prev, v))
+		default:
+			m.set(c.name, []interface{}{prev, v})
+		}
+	}
+	if strings.TrimSpace(n.text) != "" {
+		m.set("#text", n.text)
+	}
+	if n.layout != nil {
+		m.set(otioLayoutKey, n.layout)
+	}
+
+	return m
+}
+
+func fcpValue(n *fcpNode) interface{} {
+	if len(n.attrs) == 0 && len(n.children) == 0 && n.layout == nil {
+		return n.text
+	}
+
+	return fcpMap(n)
+}
+
+// fcpXML converts OTIO metadata back to an element, attributes first and then children in key order. The layout of
+// the element is applied by fcpLayout once it is read.
+func fcpXML(name string, m fcpDict) ([]byte, error) {
+	var b bytes.Buffer
+	enc := xml.NewEncoder(&b)
+	if err := fcpEncode(enc, name, m); err != nil {
+		return nil, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+func fcpEncode(enc *xml.Encoder, name string, v interface{}) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+
+	m, ok := v.(fcpDict)
+	if !ok {
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+		if err := enc.EncodeToken(xml.CharData(fcpScalar(v))); err != nil {
+			return err
+		}
+
+		return enc.EncodeToken(start.End())
+	}
+
+	for _, e := range m {
+		if strings.HasPrefix(e.key, "@") {
+			start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: e.key[1:]}, Value: fcpScalar(e.value)})
+		}
+	}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for _, e := range m {
+		switch {
+		case strings.HasPrefix(e.key, "@"), e.key == otioLayoutKey:
+		case e.key == "#text":
+			if err := enc.EncodeToken(xml.CharData(fcpScalar(e.value))); err != nil {
+				return err
+			}
+		default:
+			vs, ok := e.value.([]interface{})
+			if !ok {
+				vs = []interface{}{e.value}
+			}
+			for _, c := range vs {
+				if err := fcpEncode(enc, e.key, c); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return enc.EncodeToken(start.End())
+}
+
+// fcpScalar formats a metadata value, which may have been edited into a number or boolean.
+func fcpScalar(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+
+	return ""
+}
+
+// fcpMetadata wraps converted metadata in the fcp_xml namespace, leaving out empty metadata.
+func fcpMetadata(m fcpDict) otioMetadata {
+	if len(m) == 0 {
+		return otioMetadata{}
+	}
+
+	return otioMetadata{otioMetadataKey: m}
+}
+
+// Section: OTIO Export
+
+// WriteOTIO writes the sequence of a raw XEML data tree as an OpenTimelineIO timeline in JSON. Tracks, clip
+// items, transition items, files and markers become OTIO objects, and nested sequences become stacks. Everything
+// OTIO cannot express is kept in fcp_xml metadata, the order of children included, so that ParseOTIO restores the
+// data tree.
+func WriteOTIO(w io.Writer, x RawXEML) error {
+	if x.Sequence == nil || x.Sequence.Rate == nil || x.Sequence.Rate.TimeBase <= 0 {
+		return errors.New("sequence has no rate")
+	}
+
+	e := &otioWriter{media: x.MediaRegistry()}
+	s := x.Sequence
+	rate := *s.Rate
+
+	doc, err := newFCPDict(&x)
+	if err != nil {
+		return err
+	}
+	if seq, err := e.sequenceDict(s, true); err == nil {
+		doc.set(otioSequenceKey, seq)
+	} else {
+		return err
+	}
+
+	tl := &otioTimeline{
+		Schema:   otioTimelineSchema,
+		Metadata: fcpMetadata(doc),
+		Name:     string(s.Name),
+		Tracks:   e.stack(s, rate, "tracks"),
+	}
+	if s.TimeCode != nil {
+		t := otioTime(otioTimeCodeFrames(s.TimeCode, rate), rate)
+		tl.GlobalStartTime = &t
+	}
+	for _, m := range s.Markers {
+		tl.Tracks.Markers = append(tl.Tracks.Markers, e.marker(m, 0, rate))
+	}
+	if e.err != nil {
+		return e.err
+	}
+
+	b, err := json.MarshalIndent(tl, "", "    ")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	_, err = w.Write(b)
+
+	return err
+}
+
+type otioWriter struct {
+	media *MediaRegistry
+	err   error
+}
+
+func (e *otioWriter) dict(v interface{}, drop ...string) fcpDict {
+	m, err := newFCPDict(v, drop...)
+	if err != nil && e.err == nil {
+		e.err = err
+	}
+
+	return m
+}
+
+// sequenceDict converts a sequence without its tracks, and without its name and markers for a timeline.
+func (e *otioWriter) sequenceDict(s *Sequence, timeline bool) (fcpDict, error) {
+	drop := []string{"media/video/track", "media/audio/track"}
+	if timeline {
+		drop = append(drop, "name", "marker")
+	}
+
+	return newFCPDict(s, drop...)
+}
+
+// otioTimeCodeFrames converts a timecode to a frame count at a rate.
+func otioTimeCodeFrames(tc *TimeCode, r Rate) int {
+	frames, err := tc.Frames()
+	if err != nil {
+		frames = int(tc.Frame)
+	}
+	if tc.Rate != nil && tc.Rate.TimeBase > 0 {
+		frames = ConvertFrames(frames, *tc.Rate, r)
+	}
+
+	return frames
+}
+
+// stack writes the tracks of a sequence, video tracks first.
+func (e *otioWriter) stack(s *Sequence, rate Rate, stackName string) *otioStack {
+	st := &otioStack{
+		Schema:   "Stack.1",
+		Metadata: otioMetadata{},
+		Name:     stackName,
+		Effects:  []interface{}{},
+		Markers:  []*otioMarker{},
+		Children: otioChildren{},
+	}
+
+	if s.Media != nil && s.Media.Video != nil {
+		for _, t := range s.Media.Video.Tracks {
+			st.Children = append(st.Children, e.track(t, otioVideo, rate))
+		}
+	}
+	if s.Media != nil && s.Media.Audio != nil {
+		for _, t := range s.Media.Audio.Tracks {
+			st.Children = append(st.Children, e.track(t, otioAudio, rate))
+		}
+	}
+
+	return st
+}
+
+// track writes the clip items of a track in record order with gaps between them, and transitions at their cuts.
+func (e *otioWriter) track(t *Track, kind string, rate Rate) *otioTrack {
+	ot := &otioTrack{
+		Schema:   "Track.1",
+		Metadata: fcpMetadata(e.dict(t, "clipitem", "transitionitem")),
+		Effects:  []interface{}{},
+		Markers:  []*otioMarker{},
+		Kind:     kind,
+		Children: otioChildren{},
+	}
+
+	edits, trs := editTrack(t)
+	cursor := 0
+	next := 0
+	addTransitions := func(before int) {
+		for ; next < len(trs) && trs[next].cut <= before; next++ {
+			ot.Children = append(ot.Children, e.transition(trs[next], rate))
+		}
+	}
+
+	for _, c := range edits {
+		addTransitions(cursor)
+		if c.start > cursor {
+			ot.Children = append(ot.Children, &otioGap{
+				Schema:      "Gap.1",
+				Metadata:    otioMetadata{},
+				SourceRange: otioRange(0, c.start-cursor, rate),
+				Effects:     []interface{}{},
+				Markers:     []*otioMarker{},
+			})
+		}
+		addTransitions(c.start)
+		ot.Children = append(ot.Children, e.clip(c, rate))
+		if c.end > cursor {
+			cursor = c.end
+		}
+	}
+	addTransitions(math.MaxInt32)
+
+	return ot
+}
+
+func (e *otioWriter) transition(tr *trackTransition, rate Rate) *otioTransition {
+	ot := &otioTransition{
+		Schema:         "Transition.1",
+		Metadata:       fcpMetadata(e.dict(tr.item, "start", "end")),
+		TransitionType: otioDissolve,
+		InOffset:       otioTime(tr.cut-tr.start, rate),
+		OutOffset:      otioTime(tr.end-tr.cut, rate),
+	}
+	if ef := tr.item.Effect; ef != nil {
+		ot.Name = string(ef.Name)
+		if ef.WipeCode > 0 {
+			ot.TransitionType = otioCustom
+		}
+	}
+
+	return ot
+}
+
+// clip writes a clip item as a clip, or as a stack when it nests a sequence. Source ranges count from the start
+// timecode of the media.
+func (e *otioWriter) clip(c *trackEdit, rate Rate) interface{} {
+	ci := c.item
+	srcRate := rate
+	if ci.Rate != nil && ci.Rate.TimeBase > 0 {
+		srcRate = *ci.Rate
+	}
+
+	// the source range lasts as long as the clip item plays, so an out point elsewhere, e.g. after a speed change,
+	// is kept, as is a start or end of -1 next to a transition
+	length := ConvertFrames(c.end-c.start, rate, srcRate)
+	if length <= 0 {
+		length = int(ci.Out) - int(ci.In)
+	}
+	drop := []string{"name", "in", "marker", "file", "sequence"}
+	if int(ci.Out)-int(ci.In) == length {
+		drop = append(drop, "out")
+	}
+	if ci.Start >= 0 {
+		drop = append(drop, "start")
+	}
+	if ci.End >= 0 {
+		drop = append(drop, "end")
+	}
+	m := e.dict(ci, drop...)
+
+	if ns := ci.Sequence; ns != nil {
+		nsRate := srcRate
+		if ns.Rate != nil && ns.Rate.TimeBase > 0 {
+			nsRate = *ns.Rate
+		}
+
+		origin := 0
+		if ns.TimeCode != nil {
+			origin = otioTimeCodeFrames(ns.TimeCode, srcRate)
+		}
+
+		seq, err := e.sequenceDict(ns, false)
+		if err != nil && e.err == nil {
+			e.err = err
+		}
+		m.set(otioSequenceKey, seq)
+
+		st := e.stack(ns, nsRate, string(ci.Name))
+		st.Metadata = fcpMetadata(m)
+		st.SourceRange = otioRange(origin+int(ci.In), length, srcRate)
+		for _, mk := range ci.Markers {
+			st.Markers = append(st.Markers, e.marker(mk, origin, srcRate))
+		}
+
+		return st
+	}
+
+	oc := &otioClip{
+		Schema:   "Clip.1",
+		Metadata: fcpMetadata(m),
+		Name:     string(ci.Name),
+		Effects:  []interface{}{},
+		Markers:  []*otioMarker{},
+	}
+
+	origin := 0
+	oc.MediaReference, origin = e.reference(ci.File, srcRate)
+	oc.SourceRange = otioRange(origin+int(ci.In), length, srcRate)
+	for _, mk := range ci.Markers {
+		oc.Markers = append(oc.Markers, e.marker(mk, origin, srcRate))
+	}
+
+	return oc
+}
+
+// reference writes the file of a clip item, returning the frame its media starts at. References to a file
+// defined elsewhere name the location and range of the definition, and keep the reference itself as metadata.
+func (e *otioWriter) reference(f *File, rate Rate) (*otioReference, int) {
+	ref := &otioReference{Schema: "MissingReference.1", Metadata: otioMetadata{}}
+	if f == nil {
+		return ref, 0
+	}
+	ref.Metadata = fcpMetadata(e.dict(f))
+
+	d := e.media.Canonical(f)
+	if d == nil {
+		d = f
+	}
+
+	ref.Name = string(d.Name)
+	if d.PathURL != "" {
+		ref.Schema = "ExternalReference.1"
+		ref.TargetURL = string(d.PathURL)
+	}
+
+	origin := 0
+	if d.TimeCode != nil {
+		origin = otioTimeCodeFrames(d.TimeCode, rate)
+	}
+
+	length := int(d.Duration)
+	if d.Rate != nil && d.Rate.TimeBase > 0 {
+		length = ConvertFrames(length, *d.Rate, rate)
+	}
+	ref.AvailableRange = otioRange(origin, length, rate)
+
+	return ref, origin
+}
+
+// marker writes a marker relative to the start of its timeline or media, lasting until its out point when set.
+func (e *otioWriter) marker(m *Marker, origin int, rate Rate) *otioMarker {
+	length := 0
+	if int(m.Out) > int(m.In) {
+		length = int(m.Out) - int(m.In)
+	}
+
+	// an out point equal to the in point is kept, as it reads back as -1 otherwise
+	drop := []string{"name", "in", "comment"}
+	if m.Out != out(m.In) {
+		drop = append(drop, "out")
+	}
+
+	return &otioMarker{
+		Schema:      "Marker.2",
+		Metadata:    fcpMetadata(e.dict(m, drop...)),
+		Name:        string(m.Name),
+		Color:       otioMarkerColor,
+		MarkedRange: *otioRange(origin+int(m.In), length, rate),
+		Comment:     string(m.Comment),
+	}
+}
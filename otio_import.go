@@ -0,0 +1,544 @@
+package converter
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// ParseOTIO imports an OpenTimelineIO timeline in JSON into a raw XEML data tree holding its sequence. Timelines
+// written by WriteOTIO are restored from their fcp_xml metadata. Other timelines are read from their OTIO objects:
+// video and audio tracks become tracks, clips become clip items, stacks become nested sequences, external
+// references become files, and transitions and markers keep their timing.
+func ParseOTIO(r io.Reader) (RawXEML, error) {
+	var tl otioTimeline
+	if err := json.NewDecoder(r).Decode(&tl); err != nil {
+		return RawXEML{}, err
+	}
+
+	if otioSchemaName(tl.Schema) != "Timeline" {
+		return RawXEML{}, fmt.Errorf("OTIO schema %q is not a timeline", tl.Schema)
+	}
+	if tl.Tracks == nil {
+		return RawXEML{}, errors.New("timeline has no tracks")
+	}
+
+	x := RawXEML{Version: 5}
+	p := &otioParser{fileIDs: map[string]bool{}, clipIDs: map[string]bool{}, urls: map[string]*File{}}
+	if m := otioFCPMetadata(tl.Metadata); m != nil {
+		b, err := fcpXML("xmeml", m)
+		if err != nil {
+			return RawXEML{}, err
+		}
+		if x, err = ParseRawXEML(b); err != nil {
+			return RawXEML{}, err
+		}
+		fcpLayout(reflect.ValueOf(&x).Elem(), m)
+	}
+
+	if x.Sequence == nil {
+		x.Sequence = &Sequence{In: -1, Out: -1}
+	}
+	s := x.Sequence
+	s.Name = name(tl.Name)
+
+	if s.Rate == nil || s.Rate.TimeBase <= 0 {
+		switch {
+		case tl.GlobalStartTime != nil && tl.GlobalStartTime.Rate > 0:
+			r := otioRate(tl.GlobalStartTime.Rate)
+			s.Rate = &r
+		case otioFirstRate(tl.Tracks.Children) > 0:
+			r := otioRate(otioFirstRate(tl.Tracks.Children))
+			s.Rate = &r
+		default:
+			return RawXEML{}, errors.New("timeline has no rate")
+		}
+	}
+	rate := *s.Rate
+
+	// the timecode of the metadata is kept unless the global start time was edited
+	if gst := tl.GlobalStartTime; gst != nil && (s.TimeCode == nil || otioTimeCodeFrames(s.TimeCode, rate) != gst.frames(rate)) {
+		if s.TimeCode == nil {
+			r := rate
+			s.TimeCode = &TimeCode{DisplayFormat: timeCodeNonDropFrame, Rate: &r}
+		}
+		frames := gst.frames(rate)
+		if s.TimeCode.Rate != nil && s.TimeCode.Rate.TimeBase > 0 {
+			frames = ConvertFrames(frames, rate, *s.TimeCode.Rate)
+		}
+		s.TimeCode.SetFrames(frames)
+	}
+
+	if err := p.stack(s, tl.Tracks, rate); err != nil {
+		return RawXEML{}, err
+	}
+
+	s.Markers = nil
+	for _, m := range tl.Tracks.Markers {
+		mk, err := p.marker(m, 0, rate)
+		if err != nil {
+			return RawXEML{}, err
+		}
+		s.Markers = append(s.Markers, mk)
+	}
+
+	p.assignIDs()
+
+	return x, nil
+}
+
+type otioParser struct {
+	fileIDs map[string]bool
+	clipIDs map[string]bool
+	urls    map[string]*File
+	files   []*File
+	stubs   map[*File]*File
+	clips   []*ClipItem
+}
+
+// otioFCPMetadata returns the fcp_xml metadata of an OTIO object, or nil when it has none.
+func otioFCPMetadata(m otioMetadata) fcpDict {
+	fm, _ := m[otioMetadataKey].(fcpDict)
+
+	return fm
+}
+
+// otioRestore unmarshals the fcp_xml metadata of an OTIO object into an element, reporting whether it had any.
+func otioRestore(m otioMetadata, elementName string, v interface{}) (fcpDict, error) {
+	fm := otioFCPMetadata(m)
+	if fm == nil {
+		return nil, nil
+	}
+
+	b, err := fcpXML(elementName, fm)
+	if err != nil {
+		return nil, err
+	}
+	if err := xml.Unmarshal(b, v); err != nil {
+		return nil, err
+	}
+	fcpLayout(reflect.ValueOf(v).Elem(), fm)
+
+	return fm, nil
+}
+
+// fcpLayout puts back the children of an element restored from metadata, and of the elements below it, in the
+// order kept as #layout, so that children written as OTIO objects are written back in place, along with the ones
+// read with a zero value. Children restored from the metadata keep whether they were read empty.
+func fcpLayout(rv reflect.Value, m fcpDict) {
+	ext := extensionsOf(rv)
+	if names, ok := m.get(otioLayoutKey); ok {
+		names, _ := names.([]interface{})
+
+		// whether the children of every name were read empty, in order
+		empty := map[string][]bool{}
+		for _, c := range ext.children {
+			empty[c.name] = append(empty[c.name], c.empty)
+		}
+
+		children := []layoutChild{}
+		for _, n := range names {
+			c := layoutChild{name: fcpScalar(n)}
+			if e := empty[c.name]; len(e) > 0 {
+				c.empty, empty[c.name] = e[0], e[1:]
+			}
+			children = append(children, c)
+		}
+		ext.children = children
+	}
+
+	mt := modelTypeOf(rv.Type())
+	for _, e := range m {
+		child, ok := e.value.(fcpDict)
+		i, known := mt.byName[e.key]
+		if !ok || !known {
+			continue
+		}
+
+		fv := rv.Field(mt.elements[i].index)
+		if fv.Kind() == reflect.Ptr && !fv.IsNil() && fv.Elem().Kind() == reflect.Struct {
+			fcpLayout(fv.Elem(), child)
+		}
+	}
+}
+
+// otioFirstRate finds the rate of the first timed item, for timelines without a global start time.
+func otioFirstRate(children otioChildren) float64 {
+	for _, c := range children {
+		switch c := c.(type) {
+		case *otioStack:
+			if c.SourceRange != nil && c.SourceRange.Duration.Rate > 0 {
+				return c.SourceRange.Duration.Rate
+			}
+			if r := otioFirstRate(c.Children); r > 0 {
+				return r
+			}
+		case *otioTrack:
+			if r := otioFirstRate(c.Children); r > 0 {
+				return r
+			}
+		case *otioClip:
+			if c.SourceRange != nil && c.SourceRange.Duration.Rate > 0 {
+				return c.SourceRange.Duration.Rate
+			}
+		case *otioGap:
+			if c.SourceRange != nil && c.SourceRange.Duration.Rate > 0 {
+				return c.SourceRange.Duration.Rate
+			}
+		}
+	}
+
+	return 0
+}
+
+// stack reads the tracks of a stack into a sequence, computing its duration when it has none.
+func (p *otioParser) stack(s *Sequence, st *otioStack, rate Rate) error {
+	if s.Media == nil {
+		s.Media = &Media{}
+	}
+
+	length := 0
+	for i, c := range st.Children {
+		ot, ok := c.(*otioTrack)
+		if !ok {
+			return fmt.Errorf("child %d of stack %q is not a track", i, st.Name)
+		}
+
+		t := &Track{}
+		if _, err := otioRestore(ot.Metadata, "track", t); err != nil {
+			return err
+		}
+
+		end, err := p.track(t, ot, rate)
+		if err != nil {
+			return err
+		}
+		t.order = readOrder(t)
+		if end > length {
+			length = end
+		}
+
+		switch ot.Kind {
+		case otioAudio:
+			if s.Media.Audio == nil {
+				s.Media.Audio = &Audio{}
+			}
+			s.Media.Audio.Tracks = append(s.Media.Audio.Tracks, t)
+		case otioVideo:
+			if s.Media.Video == nil {
+				s.Media.Video = &Video{}
+			}
+			s.Media.Video.Tracks = append(s.Media.Video.Tracks, t)
+		default:
+			return fmt.Errorf("track %q has unsupported kind %q", ot.Name, ot.Kind)
+		}
+	}
+
+	if s.Duration == 0 {
+		s.Duration = duration(length)
+	}
+
+	return nil
+}
+
+// track reads the items of a track one after another, returning the frame the last one ends at.
+func (p *otioParser) track(t *Track, ot *otioTrack, rate Rate) (int, error) {
+	cursor := 0
+	for i, c := range ot.Children {
+		switch c := c.(type) {
+		case *otioGap:
+			if c.SourceRange != nil {
+				cursor += c.SourceRange.Duration.frames(rate)
+			}
+		case *otioTransition:
+			prev := i > 0 && otioIsItem(ot.Children[i-1])
+			next := i+1 < len(ot.Children) && otioIsItem(ot.Children[i+1])
+			ti, err := p.transition(c, cursor, prev, next, ot.Kind, rate)
+			if err != nil {
+				return 0, err
+			}
+			t.TransitionItems = append(t.TransitionItems, ti)
+		case *otioClip, *otioStack:
+			ci, clipEnd, err := p.clip(c, cursor, rate)
+			if err != nil {
+				return 0, err
+			}
+			t.ClipItems = append(t.ClipItems, ci)
+			cursor = clipEnd
+		default:
+			return 0, fmt.Errorf("track %q holds an unsupported item", ot.Name)
+		}
+	}
+
+	return cursor, nil
+}
+
+// otioIsItem reports whether an item of a track plays media, a clip or a nested stack.
+func otioIsItem(c interface{}) bool {
+	switch c.(type) {
+	case *otioClip, *otioStack:
+		return true
+	}
+
+	return false
+}
+
+// transition reads a transition at a cut, aligned to the cut when it starts or ends there, and to black when there
+// is no clip on one side of it.
+func (p *otioParser) transition(ot *otioTransition, cut int, prev bool, next bool, kind string, rate Rate) (*TransitionItem, error) {
+	ti := &TransitionItem{}
+	fm, err := otioRestore(ot.Metadata, "transitionitem", ti)
+	if err != nil {
+		return nil, err
+	}
+
+	inOffset := ot.InOffset.frames(rate)
+	outOffset := ot.OutOffset.frames(rate)
+	ti.Start = start(cut - inOffset)
+	ti.End = end(cut + outOffset)
+
+	if fm == nil {
+		media := "video"
+		if kind == otioAudio {
+			media = "audio"
+		}
+		wipe := 0
+		if ot.TransitionType == otioCustom {
+			wipe = 1
+		}
+		ti.Effect = transitionEffect(media, wipe)
+	}
+
+	if ti.Alignment == "" {
+		switch {
+		case !prev:
+			ti.Alignment = alignmentStartBlack
+		case !next:
+			ti.Alignment = alignmentEndBlack
+		case inOffset == 0:
+			ti.Alignment = alignmentStart
+		case outOffset == 0:
+			ti.Alignment = alignmentEnd
+		default:
+			ti.Alignment = alignmentCenter
+		}
+	}
+
+	return ti, nil
+}
+
+// clip reads a clip or a nested stack at a record frame into a clip item, returning the frame it ends at. Its in
+// point counts from the start of the available range of its media.
+func (p *otioParser) clip(c interface{}, cursor int, rate Rate) (*ClipItem, int, error) {
+	var metadata otioMetadata
+	var clipName string
+	var sourceRange *otioTimeRange
+	var markers []*otioMarker
+	switch c := c.(type) {
+	case *otioClip:
+		metadata, clipName, sourceRange, markers = c.Metadata, c.Name, c.SourceRange, c.Markers
+	case *otioStack:
+		metadata, clipName, sourceRange, markers = c.Metadata, c.Name, c.SourceRange, c.Markers
+	}
+
+	ci := &ClipItem{}
+	fm, err := otioRestore(metadata, "clipitem", ci)
+	if err != nil {
+		return nil, 0, err
+	}
+	if ci.ID != "" {
+		p.clipIDs[ci.ID] = true
+	}
+	p.clips = append(p.clips, ci)
+	ci.Name = name(clipName)
+
+	srcRate := rate
+	if ci.Rate != nil && ci.Rate.TimeBase > 0 {
+		srcRate = *ci.Rate
+	} else if fm == nil {
+		r := rate
+		ci.Rate = &r
+	}
+
+	origin := 0
+	switch c := c.(type) {
+	case *otioClip:
+		ref := c.MediaReference
+		if ref == nil {
+			key := c.ActiveReference
+			if key == "" {
+				key = "DEFAULT_MEDIA"
+			}
+			ref = c.MediaReferences[key]
+		}
+		if ci.File, origin, err = p.file(ref, srcRate); err != nil {
+			return nil, 0, err
+		}
+		if fm == nil && ref != nil && ref.AvailableRange != nil {
+			ci.Duration = duration(ref.AvailableRange.Duration.frames(srcRate))
+		}
+	case *otioStack:
+		if ci.Sequence == nil {
+			r := srcRate
+			ci.Sequence = &Sequence{Name: name(c.Name), Rate: &r, In: -1, Out: -1}
+		}
+		ns := ci.Sequence
+		nsRate := srcRate
+		if ns.Rate != nil && ns.Rate.TimeBase > 0 {
+			nsRate = *ns.Rate
+		}
+		if err := p.stack(ns, c, nsRate); err != nil {
+			return nil, 0, err
+		}
+		if ns.TimeCode != nil {
+			origin = otioTimeCodeFrames(ns.TimeCode, srcRate)
+		}
+		if fm == nil {
+			ci.Duration = duration(ConvertFrames(int(ns.Duration), nsRate, srcRate))
+		}
+	}
+
+	length := 0
+	if sourceRange != nil {
+		ci.In = in(sourceRange.StartTime.frames(srcRate) - origin)
+		length = sourceRange.Duration.frames(srcRate)
+	}
+	if !fm.has("out") {
+		ci.Out = out(int(ci.In) + length)
+	}
+
+	recordLength := 0
+	if sourceRange != nil {
+		recordLength = sourceRange.Duration.frames(rate)
+	}
+	if !fm.has("start") {
+		ci.Start = start(cursor)
+	}
+	if !fm.has("end") {
+		ci.End = end(cursor + recordLength)
+	}
+
+	ci.Markers = nil
+	for _, m := range markers {
+		mk, err := p.marker(m, origin, srcRate)
+		if err != nil {
+			return nil, 0, err
+		}
+		ci.Markers = append(ci.Markers, mk)
+	}
+
+	return ci, cursor + recordLength, nil
+}
+
+// file reads the media reference of a clip, returning the frame its available range starts at. A reference without
+// fcp_xml metadata defines a file the first time its location is used, and refers to it afterwards.
+func (p *otioParser) file(ref *otioReference, rate Rate) (*File, int, error) {
+	if ref == nil {
+		return nil, 0, nil
+	}
+
+	origin := 0
+	length := 0
+	if ref.AvailableRange != nil {
+		origin = ref.AvailableRange.StartTime.frames(rate)
+		length = ref.AvailableRange.Duration.frames(rate)
+	}
+
+	f := &File{}
+	fm, err := otioRestore(ref.Metadata, "file", f)
+	if err != nil {
+		return nil, 0, err
+	}
+	if fm != nil {
+		if f.ID != "" {
+			p.fileIDs[f.ID] = true
+		}
+		if f.isDefinition() {
+			f.Name = name(ref.Name)
+			if otioSchemaName(ref.Schema) == "ExternalReference" {
+				f.PathURL = pathURL(ref.TargetURL)
+			}
+		}
+
+		return f, origin, nil
+	}
+
+	key := ref.TargetURL
+	if key == "" {
+		key = ref.Name
+	}
+	if key == "" {
+		return nil, origin, nil
+	}
+
+	if d, ok := p.urls[key]; ok {
+		if p.stubs == nil {
+			p.stubs = map[*File]*File{}
+		}
+		p.stubs[f] = d
+
+		return f, origin, nil
+	}
+
+	r := rate
+	f.Name = name(ref.Name)
+	f.PathURL = pathURL(ref.TargetURL)
+	f.Rate = &r
+	f.Duration = duration(length)
+	f.TimeCode = &TimeCode{DisplayFormat: timeCodeNonDropFrame, Rate: &r}
+	f.TimeCode.SetFrames(origin)
+	p.urls[key] = f
+	p.files = append(p.files, f)
+
+	return f, origin, nil
+}
+
+// marker reads a marker relative to the start of its timeline or media. Markers without a duration have an out
+// point of -1, unless their metadata keeps one.
+func (p *otioParser) marker(om *otioMarker, origin int, rate Rate) (*Marker, error) {
+	m := &Marker{}
+	fm, err := otioRestore(om.Metadata, "marker", m)
+	if err != nil {
+		return nil, err
+	}
+
+	m.Name = name(om.Name)
+	m.Comment = comment(om.Comment)
+	m.In = in(om.MarkedRange.StartTime.frames(rate) - origin)
+	if length := om.MarkedRange.Duration.frames(rate); length > 0 {
+		m.Out = out(int(m.In) + length)
+	} else if !fm.has("out") {
+		m.Out = -1
+	}
+
+	return m, nil
+}
+
+// assignIDs numbers the files and clip items read without metadata, skipping ids that metadata already uses.
+func (p *otioParser) assignIDs() {
+	n := 0
+	for _, f := range p.files {
+		for f.ID == "" {
+			n++
+			if id := fmt.Sprintf("file-%d", n); !p.fileIDs[id] {
+				f.ID = id
+			}
+		}
+	}
+	for stub, d := range p.stubs {
+		stub.ID = d.ID
+	}
+
+	n = 0
+	for _, ci := range p.clips {
+		for ci.ID == "" {
+			n++
+			if id := fmt.Sprintf("clipitem-%d", n); !p.clipIDs[id] {
+				ci.ID = id
+			}
+		}
+	}
+}
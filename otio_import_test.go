@@ -0,0 +1,164 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestImportingOTIO(t *testing.T) {
+	oc := `{
+		"OTIO_SCHEMA": "Timeline.1",
+		"metadata": {},
+		"name": "Cut 1",
+		"global_start_time": {"OTIO_SCHEMA": "RationalTime.1", "rate": 23.976, "value": 86400},
+		"tracks": {
+			"OTIO_SCHEMA": "Stack.1",
+			"name": "tracks",
+			"markers": [
+				{
+					"OTIO_SCHEMA": "Marker.1",
+					"name": "Fix colour",
+					"color": "RED",
+					"marked_range": {
+						"OTIO_SCHEMA": "TimeRange.1",
+						"start_time": {"OTIO_SCHEMA": "RationalTime.1", "rate": 23.976, "value": 30},
+						"duration": {"OTIO_SCHEMA": "RationalTime.1", "rate": 23.976, "value": 0}
+					}
+				}
+			],
+			"children": [
+				{
+					"OTIO_SCHEMA": "Track.1",
+					"name": "V1",
+					"kind": "Video",
+					"children": [
+						{
+							"OTIO_SCHEMA": "Gap.1",
+							"source_range": {
+								"OTIO_SCHEMA": "TimeRange.1",
+								"start_time": {"OTIO_SCHEMA": "RationalTime.1", "rate": 23.976, "value": 0},
+								"duration": {"OTIO_SCHEMA": "RationalTime.1", "rate": 23.976, "value": 12}
+							}
+						},
+						{
+							"OTIO_SCHEMA": "Clip.2",
+							"name": "Shot 1",
+							"source_range": {
+								"OTIO_SCHEMA": "TimeRange.1",
+								"start_time": {"OTIO_SCHEMA": "RationalTime.1", "rate": 23.976, "value": 864024},
+								"duration": {"OTIO_SCHEMA": "RationalTime.1", "rate": 23.976, "value": 48}
+							},
+							"media_references": {
+								"DEFAULT_MEDIA": {
+									"OTIO_SCHEMA": "ExternalReference.1",
+									"name": "A001.mov",
+									"target_url": "file:///media/A001.mov",
+									"available_range": {
+										"OTIO_SCHEMA": "TimeRange.1",
+										"start_time": {"OTIO_SCHEMA": "RationalTime.1", "rate": 23.976, "value": 864000},
+										"duration": {"OTIO_SCHEMA": "RationalTime.1", "rate": 23.976, "value": 1000}
+									}
+								}
+							},
+							"active_media_reference_key": "DEFAULT_MEDIA"
+						},
+						{
+							"OTIO_SCHEMA": "Transition.1",
+							"name": "Dissolve",
+							"transition_type": "SMPTE_Dissolve",
+							"in_offset": {"OTIO_SCHEMA": "RationalTime.1", "rate": 23.976, "value": 6},
+							"out_offset": {"OTIO_SCHEMA": "RationalTime.1", "rate": 23.976, "value": 6}
+						},
+						{
+							"OTIO_SCHEMA": "Clip.1",
+							"name": "Shot 2",
+							"source_range": {
+								"OTIO_SCHEMA": "TimeRange.1",
+								"start_time": {"OTIO_SCHEMA": "RationalTime.1", "rate": 23.976, "value": 864100},
+								"duration": {"OTIO_SCHEMA": "RationalTime.1", "rate": 23.976, "value": 24}
+							},
+							"media_reference": {
+								"OTIO_SCHEMA": "ExternalReference.1",
+								"name": "A001.mov",
+								"target_url": "file:///media/A001.mov",
+								"available_range": {
+									"OTIO_SCHEMA": "TimeRange.1",
+									"start_time": {"OTIO_SCHEMA": "RationalTime.1", "rate": 23.976, "value": 864000},
+									"duration": {"OTIO_SCHEMA": "RationalTime.1", "rate": 23.976, "value": 1000}
+								}
+							}
+						}
+					]
+				},
+				{
+					"OTIO_SCHEMA": "Track.1",
+					"name": "A1",
+					"kind": "Audio",
+					"children": []
+				}
+			]
+		}
+	}`
+
+	x, err := ParseOTIO(strings.NewReader(oc))
+	if err != nil {
+		t.Fatal("OTIO could not be imported: " + err.Error())
+	}
+
+	s := x.Sequence
+	if s.Name != "Cut 1" || s.Rate.TimeBase != 24 || !s.Rate.NTSC || s.Duration != 84 {
+		t.Error("sequence does not match expectations")
+	}
+
+	if s.TimeCode.TimeCodeString != "01:00:00:00" {
+		t.Error("sequence timecode does not match expectations: " + string(s.TimeCode.TimeCodeString))
+	}
+
+	if len(s.Markers) != 1 || s.Markers[0].In != 30 || s.Markers[0].Out != -1 {
+		t.Error("sequence marker not imported")
+	}
+
+	video := s.Media.Video.Tracks
+	if len(video) != 1 || len(video[0].ClipItems) != 2 || len(s.Media.Audio.Tracks) != 1 {
+		t.Fatal("tracks do not match expectations")
+	}
+
+	shot1 := video[0].ClipItems[0]
+	if shot1.ID != "clipitem-1" || shot1.Start != 12 || shot1.End != 60 || shot1.In != 24 || shot1.Out != 72 {
+		t.Errorf("clip item does not match expectations: %d %d %d %d", shot1.Start, shot1.End, shot1.In, shot1.Out)
+	}
+
+	if f := shot1.File; f == nil || f.ID != "file-1" || f.PathURL != "file:///media/A001.mov" || f.TimeCode.TimeCodeString != "10:00:00:00" {
+		t.Error("file not defined from the media reference")
+	}
+
+	shot2 := video[0].ClipItems[1]
+	if shot2.Start != 60 || shot2.In != 100 || shot2.File.ID != "file-1" || shot2.File.PathURL != "" {
+		t.Error("file not referenced by id")
+	}
+
+	if ts := video[0].TransitionItems; len(ts) != 1 || ts[0].Start != 54 || ts[0].End != 66 || ts[0].Alignment != alignmentCenter {
+		t.Error("transition does not match expectations")
+	}
+
+	if _, err := PackageXEML(x); err != nil {
+		t.Error("imported sequence could not be packaged: " + err.Error())
+	}
+}
+
+func TestImportingOTIOErrors(t *testing.T) {
+	if _, err := ParseOTIO(strings.NewReader(`{"OTIO_SCHEMA": "SerializableCollection.1"}`)); err == nil {
+		t.Error("document other than a timeline not rejected")
+	}
+
+	oc := `{
+		"OTIO_SCHEMA": "Timeline.1",
+		"tracks": {
+			"OTIO_SCHEMA": "Stack.1",
+			"children": [{"OTIO_SCHEMA": "Track.1", "kind": "Video", "children": [{"OTIO_SCHEMA": "Effect.1"}]}]
+		}
+	}`
+	if _, err := ParseOTIO(strings.NewReader(oc)); err == nil {
+		t.Error("unsupported schema not rejected")
+	}
+}
@@ -0,0 +1,260 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestOTIORoundTripFromExports(t *testing.T) {
+	for _, path := range []string{"export-examples/premier-export.xml", "export-examples/resolve-export.xml"} {
+		s, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal("example export could not be read: " + err.Error())
+		}
+
+		x := ImportRawXEML(s)
+
+		var b bytes.Buffer
+		if err := WriteOTIO(&b, x); err != nil {
+			t.Fatal("OTIO could not be written: " + err.Error())
+		}
+
+		y, err := ParseOTIO(&b)
+		if err != nil {
+			t.Fatal("OTIO could not be imported: " + err.Error())
+		}
+
+		if !reflect.DeepEqual(x, y) {
+			want, _ := xml.MarshalIndent(x, "", "  ")
+			got, _ := xml.MarshalIndent(y, "", "  ")
+			t.Errorf("%s not preserved:\n%s\n%s", path, want, got)
+		}
+	}
+}
+
+func TestWritingOTIO(t *testing.T) {
+	xc := `<xmeml version="5">
+		<sequence>
+			<name>Cut 1</name>
+			<duration>100</duration>
+			<rate>
+				<timebase>25</timebase>
+			</rate>
+			<timecode>
+				<string>01:00:00:00</string>
+				<displayformat>NDF</displayformat>
+				<rate>
+					<timebase>25</timebase>
+				</rate>
+			</timecode>
+			<marker>
+				<name>Fix colour</name>
+				<in>60</in>
+				<out>-1</out>
+			</marker>
+			<media>
+				<video>
+					<track>
+						<clipitem id="clipitem-1">
+							<name>Shot 1</name>
+							<start>10</start>
+							<end>-1</end>
+							<in>25</in>
+							<out>65</out>
+							<file id="file-1">
+								<name>A001.mov</name>
+								<duration>1000</duration>
+								<pathurl>file://localhost/media/A001.mov</pathurl>
+								<rate>
+									<timebase>25</timebase>
+								</rate>
+								<timecode>
+									<string>10:00:00:00</string>
+									<displayformat>NDF</displayformat>
+									<rate>
+										<timebase>25</timebase>
+									</rate>
+								</timecode>
+							</file>
+							<marker>
+								<name>Flash</name>
+								<in>30</in>
+								<out>35</out>
+							</marker>
+						</clipitem>
+						<transitionitem>
+							<start>40</start>
+							<end>60</end>
+							<alignment>center</alignment>
+							<effect>
+								<name>Cross Dissolve</name>
+								<effectid>Cross Dissolve</effectid>
+								<effecttype>transition</effecttype>
+								<mediatype>video</mediatype>
+							</effect>
+						</transitionitem>
+						<clipitem id="clipitem-2">
+							<name>Compound</name>
+							<start>-1</start>
+							<end>100</end>
+							<in>0</in>
+							<out>50</out>
+							<sequence id="sequence-1">
+								<name>Compound</name>
+								<duration>50</duration>
+								<rate>
+									<timebase>25</timebase>
+								</rate>
+								<media>
+									<video>
+										<track>
+											<clipitem id="clipitem-3">
+												<name>Shot 2</name>
+												<start>0</start>
+												<end>50</end>
+												<in>0</in>
+												<out>50</out>
+												<file id="file-1"/>
+											</clipitem>
+										</track>
+									</video>
+								</media>
+							</sequence>
+						</clipitem>
+					</track>
+				</video>
+			</media>
+		</sequence>
+	</xmeml>`
+
+	x := ImportRawXEML([]byte(xc))
+
+	var b bytes.Buffer
+	if err := WriteOTIO(&b, x); err != nil {
+		t.Fatal("OTIO could not be written: " + err.Error())
+	}
+
+	var tl otioTimeline
+	if err := json.Unmarshal(b.Bytes(), &tl); err != nil {
+		t.Fatal("OTIO could not be read back: " + err.Error())
+	}
+
+	if tl.Name != "Cut 1" || tl.GlobalStartTime == nil || tl.GlobalStartTime.Value != 90000 || tl.GlobalStartTime.Rate != 25 {
+		t.Error("timeline does not match expectations")
+	}
+
+	if len(tl.Tracks.Markers) != 1 || tl.Tracks.Markers[0].MarkedRange.StartTime.Value != 60 {
+		t.Error("sequence marker not written")
+	}
+
+	track := tl.Tracks.Children[0].(*otioTrack)
+	if track.Kind != otioVideo || len(track.Children) != 4 {
+		t.Fatalf("track items do not match expectations: %d", len(track.Children))
+	}
+
+	if gap, ok := track.Children[0].(*otioGap); !ok || gap.SourceRange.Duration.Value != 10 {
+		t.Error("gap not written before the first clip")
+	}
+
+	shot := track.Children[1].(*otioClip)
+	if shot.Name != "Shot 1" || shot.SourceRange.StartTime.Value != 900025 || shot.SourceRange.Duration.Value != 40 {
+		t.Errorf("clip does not match expectations: %v", shot.SourceRange)
+	}
+
+	if shot.MediaReference.TargetURL != "file://localhost/media/A001.mov" || shot.MediaReference.AvailableRange.Duration.Value != 1000 {
+		t.Error("media reference does not match expectations")
+	}
+
+	if len(shot.Markers) != 1 || shot.Markers[0].MarkedRange.StartTime.Value != 900030 || shot.Markers[0].MarkedRange.Duration.Value != 5 {
+		t.Error("clip marker does not match expectations")
+	}
+
+	tr := track.Children[2].(*otioTransition)
+	if tr.Name != "Cross Dissolve" || tr.TransitionType != otioDissolve || tr.InOffset.Value != 10 || tr.OutOffset.Value != 10 {
+		t.Error("transition does not match expectations")
+	}
+
+	nested, ok := track.Children[3].(*otioStack)
+	if !ok || len(nested.Children) != 1 || nested.SourceRange.Duration.Value != 50 {
+		t.Fatal("nested sequence not written as a stack")
+	}
+
+	if ref := nested.Children[0].(*otioTrack).Children[0].(*otioClip).MediaReference; ref.Name != "A001.mov" {
+		t.Error("reference to a file defined elsewhere not resolved")
+	}
+
+	y, err := ParseOTIO(&b)
+	if err != nil {
+		t.Fatal("OTIO could not be imported: " + err.Error())
+	}
+
+	if !reflect.DeepEqual(x, y) {
+		want, _ := xml.MarshalIndent(x, "", "  ")
+		got, _ := xml.MarshalIndent(y, "", "  ")
+		t.Errorf("sequence not preserved:\n%s\n%s", want, got)
+	}
+}
+
+func TestOTIORoundTripKeepsLayout(t *testing.T) {
+	xc := `<xmeml version="5">
+		<sequence>
+			<rate>
+				<timebase>25</timebase>
+			</rate>
+			<name>Layout</name>
+			<duration>100</duration>
+			<media>
+				<video>
+					<track>
+						<enabled>TRUE</enabled>
+						<clipitem id="clipitem-1">
+							<in>0</in>
+							<out>50</out>
+							<name>A</name>
+							<start>0</start>
+							<end>50</end>
+						</clipitem>
+						<transitionitem>
+							<start>40</start>
+							<end>60</end>
+							<alignment>center</alignment>
+						</transitionitem>
+						<generatoritem id="generatoritem-1">
+							<name>Slug</name>
+							<start>50</start>
+							<end>60</end>
+						</generatoritem>
+						<clipitem id="clipitem-2">
+							<name>B</name>
+							<start>60</start>
+							<end>100</end>
+							<in>0</in>
+							<out>40</out>
+						</clipitem>
+					</track>
+					<format/>
+				</video>
+			</media>
+		</sequence>
+	</xmeml>`
+	x := ImportRawXEML([]byte(xc))
+
+	var b bytes.Buffer
+	if err := WriteOTIO(&b, x); err != nil {
+		t.Fatal("OTIO could not be written: " + err.Error())
+	}
+	y, err := ParseOTIO(&b)
+	if err != nil {
+		t.Fatal("OTIO could not be imported: " + err.Error())
+	}
+
+	want, _ := xml.MarshalIndent(x, "", "  ")
+	got, _ := xml.MarshalIndent(y, "", "  ")
+	if !bytes.Equal(want, got) {
+		t.Errorf("layout not preserved:\n%s\n%s", want, got)
+	}
+}
@@ -0,0 +1,152 @@
+package converter
+
+import "strings"
+
+// BinItem describes a clip or sequence of a document along with the bins it is in.
+type BinItem struct {
+	// Bins names the bins containing the item, outermost first. It is empty for items at the top level of a
+	// document or project.
+	Bins []string
+	// Path is the element path of the item, e.g. xmeml/project/children/bin[2]/children/sequence
+	Path     string
+	Clip     *Clip
+	Sequence *Sequence
+}
+
+// BinPath joins the names of the bins containing an item, e.g. Dailies/Day 1.
+func (i BinItem) BinPath() string {
+	return strings.Join(i.Bins, "/")
+}
+
+// Items lists the clips, sequences and bins of a project or bin in document order, each a *Clip, *Sequence or *Bin.
+// Items added to Clips, Sequences or Bins after they were read follow the items that were read, clips first, then
+// sequences and then bins.
+func (c *Children) Items() []interface{} {
+	kept, added := keepOrder(c.order, c.Clips, c.Sequences, c.Bins)
+
+	return append(kept, added...)
+}
+
+func (c *Children) items() []interface{} {
+	return c.Items()
+}
+
+// Walk calls fn for every clip and sequence of a document in document order, descending into bins where they are,
+// see Children.Items. Walking stops at the first error returned by fn, which is returned by Walk. Sequences nested
+// in clip items are not bin items and are not walked.
+func (x *RawXEML) Walk(fn func(item BinItem) error) error {
+	if x.Project != nil {
+		if err := walkChildren(x.Project.Children, nil, "xmeml/project", fn); err != nil {
+			return err
+		}
+	}
+
+	if x.Bin != nil {
+		if err := walkBin(x.Bin, nil, "xmeml/bin", fn); err != nil {
+			return err
+		}
+	}
+
+	if x.Clip != nil {
+		if err := fn(BinItem{Path: "xmeml/clip", Clip: x.Clip}); err != nil {
+			return err
+		}
+	}
+
+	if x.Sequence != nil {
+		return fn(BinItem{Path: "xmeml/sequence", Sequence: x.Sequence})
+	}
+
+	return nil
+}
+
+func walkBin(b *Bin, bins []string, path string, fn func(item BinItem) error) error {
+	// copied so that items of sibling bins do not share a backing array
+	inner := append(append([]string{}, bins...), string(b.Name))
+
+	return walkChildren(b.Children, inner, path, fn)
+}
+
+func walkChildren(c *Children, bins []string, path string, fn func(item BinItem) error) error {
+	if c == nil {
+		return nil
+	}
+
+	path = childPath(path, "children", 0)
+	clips, sequences, binCount := 0, 0, 0
+	for _, it := range c.Items() {
+		var err error
+		switch it := it.(type) {
+		case *Clip:
+			err = fn(BinItem{Bins: bins, Path: childPath(path, "clip", clips), Clip: it})
+			clips++
+		case *Sequence:
+			err = fn(BinItem{Bins: bins, Path: childPath(path, "sequence", sequences), Sequence: it})
+			sequences++
+		case *Bin:
+			err = walkBin(it, bins, childPath(path, "bin", binCount), fn)
+			binCount++
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Sequences lists every sequence of a document along with its bin path.
+func (x *RawXEML) Sequences() []BinItem {
+	var items []BinItem
+	_ = x.Walk(func(item BinItem) error {
+		if item.Sequence != nil {
+			items = append(items, item)
+		}
+
+		return nil
+	})
+
+	return items
+}
+
+// MasterClips lists every master clip of a document along with its bin path.
+func (x *RawXEML) MasterClips() []BinItem {
+	var items []BinItem
+	_ = x.Walk(func(item BinItem) error {
+		if item.Clip != nil && item.Clip.IsMasterClip {
+			items = append(items, item)
+		}
+
+		return nil
+	})
+
+	return items
+}
+
+// walkSequences calls fn for every sequence of a document in the order Walk finds them, each followed by the
+// sequences nested in its clip items, video tracks first.
+func (x *RawXEML) walkSequences(fn func(s *Sequence)) {
+	_ = x.Walk(func(item BinItem) error {
+		if item.Sequence != nil {
+			item.Sequence.walkSequences(fn)
+		}
+
+		return nil
+	})
+}
+
+func (s *Sequence) walkSequences(fn func(s *Sequence)) {
+	fn(s)
+
+	if s.Media == nil {
+		return
+	}
+
+	for _, t := range s.Media.tracks() {
+		for _, ci := range t.ClipItems {
+			if ci.Sequence != nil {
+				ci.Sequence.walkSequences(fn)
+			}
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package converter
+
+import (
+	"encoding/xml"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestImportingAProject(t *testing.T) {
+	xc := `<?xml version="1.0" encoding="UTF-8"?>
+	<!DOCTYPE xmeml>
+	<xmeml version="5">
+		<project>
+			<name>Feature</name>
+			<children>
+				<bin>
+					<name>Dailies</name>
+					<children>
+						<bin>
+							<name>Day 1</name>
+							<children>
+								<clip id="masterclip-1">
+									<name>A001</name>
+									<duration>1000</duration>
+									<rate>
+										<timebase>25</timebase>
+									</rate>
+									<masterclipid>masterclip-1</masterclipid>
+									<ismasterclip>TRUE</ismasterclip>
+									<file id="file-1">
+										<name>A001.mov</name>
+										<pathurl>file://localhost/media/A001.mov</pathurl>
+										<duration>1000</duration>
+										<rate>
+											<timebase>25</timebase>
+										</rate>
+									</file>
+								</clip>
+							</children>
+						</bin>
+						<bin>
+							<name>Day 2</name>
+						</bin>
+					</children>
+				</bin>
+				<sequence id="sequence-1">
+					<name>Cut 1</name>
+					<duration>50</duration>
+					<rate>
+						<timebase>25</timebase>
+					</rate>
+					<media>
+						<video>
+							<track>
+								<clipitem id="clipitem-1">
+									<name>A001</name>
+									<masterclipid>masterclip-1</masterclipid>
+									<start>0</start>
+									<end>50</end>
+									<in>0</in>
+									<out>50</out>
+									<file id="file-1"/>
+								</clipitem>
+							</track>
+						</video>
+					</media>
+				</sequence>
+				<bin>
+					<name>Cuts</name>
+					<children>
+						<sequence id="sequence-2">
+							<name>Cut 2</name>
+							<duration>0</duration>
+							<rate>
+								<timebase>25</timebase>
+							</rate>
+						</sequence>
+					</children>
+				</bin>
+			</children>
+		</project>
+	</xmeml>`
+
+	x := ImportRawXEML([]byte(xc))
+
+	if x.Project == nil || x.Project.Name != "Feature" || len(x.Project.Children.Bins) != 2 {
+		t.Fatal("project not imported")
+	}
+
+	if bins := x.Project.Children.Bins[0].Children.Bins; len(bins) != 2 || bins[0].Children.Clips[0].ID != "masterclip-1" {
+		t.Error("nested bins not imported")
+	}
+
+	sequences := x.Sequences()
+	if len(sequences) != 2 {
+		t.Fatalf("sequences not listed: %d", len(sequences))
+	}
+
+	if s := sequences[0]; s.Sequence.Name != "Cut 1" || s.BinPath() != "" || s.Path != "xmeml/project/children/sequence" {
+		t.Error("top level sequence does not match expectations")
+	}
+
+	if s := sequences[1]; s.Sequence.Name != "Cut 2" || s.BinPath() != "Cuts" || s.Path != "xmeml/project/children/bin[2]/children/sequence" {
+		t.Errorf("sequence in a bin does not match expectations: %s %s", s.BinPath(), s.Path)
+	}
+
+	clips := x.MasterClips()
+	if len(clips) != 1 || !reflect.DeepEqual(clips[0].Bins, []string{"Dailies", "Day 1"}) || clips[0].BinPath() != "Dailies/Day 1" {
+		t.Fatal("master clips not listed with their bins")
+	}
+
+	if f := x.MediaRegistry().Lookup("file-1"); f == nil || f.PathURL != "file://localhost/media/A001.mov" {
+		t.Error("file defined in a bin not registered")
+	}
+
+	xm, err := PackageXEML(x)
+	if err != nil {
+		t.Fatal("project could not be packaged: " + err.Error())
+	}
+
+	ci := xm.Project.Sequences[0].Video[0].ClipItems[0]
+	if ci.MasterClip == nil || ci.MasterClip != xm.Project.Bins[0].Bins[0].Clips[0] || ci.File.Name != "A001.mov" {
+		t.Error("clip item not resolved to the master clip in its bin")
+	}
+
+	b, err := xml.Marshal(x)
+	if err != nil {
+		t.Fatal("project could not be marshalled: " + err.Error())
+	}
+
+	y, err := ParseRawXEML(b)
+	if err != nil || !reflect.DeepEqual(x, y) {
+		t.Error("project not preserved by a marshal")
+	}
+}
+
+func TestWalkingStopsAtAnError(t *testing.T) {
+	x := RawXEML{
+		Bin: &Bin{Name: "Bin", Children: &Children{
+			Sequences: []*Sequence{{Name: "One"}, {Name: "Two"}},
+		}},
+		Sequence: &Sequence{Name: "Three"},
+	}
+
+	var names []string
+	stop := errors.New("stop")
+	err := x.Walk(func(item BinItem) error {
+		names = append(names, string(item.Sequence.Name))
+		if len(names) == 2 {
+			return stop
+		}
+
+		return nil
+	})
+
+	if err != stop || len(names) != 2 {
+		t.Error("walk did not stop at the first error")
+	}
+}
+
+func TestWalkingInDocumentOrder(t *testing.T) {
+	xc := `<xmeml version="5">
+		<bin>
+			<name>Mixed</name>
+			<children>
+				<sequence>
+					<name>Cut 1</name>
+				</sequence>
+				<bin>
+					<name>Inner</name>
+					<children>
+						<clip>
+							<name>B001</name>
+						</clip>
+					</children>
+				</bin>
+				<clip>
+					<name>A001</name>
+				</clip>
+				<sequence>
+					<name>Cut 2</name>
+				</sequence>
+			</children>
+		</bin>
+	</xmeml>`
+
+	x := ImportRawXEML([]byte(xc))
+	x.Bin.Children.Clips = append(x.Bin.Children.Clips, &Clip{Name: "C001"})
+
+	var walked []string
+	_ = x.Walk(func(item BinItem) error {
+		if item.Clip != nil {
+			walked = append(walked, string(item.Clip.Name)+" "+item.Path)
+		} else {
+			walked = append(walked, string(item.Sequence.Name)+" "+item.Path)
+		}
+
+		return nil
+	})
+
+	want := []string{
+		"Cut 1 xmeml/bin/children/sequence",
+		"B001 xmeml/bin/children/bin/children/clip",
+		"A001 xmeml/bin/children/clip",
+		"Cut 2 xmeml/bin/children/sequence[2]",
+		"C001 xmeml/bin/children/clip[2]",
+	}
+	if !reflect.DeepEqual(walked, want) {
+		t.Errorf("items not walked in document order: %v", walked)
+	}
+
+	b, err := xml.Marshal(x)
+	if err != nil {
+		t.Fatal("bin could not be marshalled: " + err.Error())
+	}
+
+	y := ImportRawXEML(b)
+	var order []string
+	for _, it := range y.Bin.Children.Items() {
+		switch it := it.(type) {
+		case *Clip:
+			order = append(order, string(it.Name))
+		case *Sequence:
+			order = append(order, string(it.Name))
+		case *Bin:
+			order = append(order, string(it.Name))
+		}
+	}
+
+	if !reflect.DeepEqual(order, []string{"Cut 1", "Inner", "A001", "Cut 2", "C001"}) {
+		t.Errorf("children not written in document order: %v", order)
+	}
+}
@@ -30,32 +30,43 @@ type RawXEML struct {
 	XMLName xml.Name `xml:"xmeml"`
 	Version int      `xml:"version,attr"`
 	// ImportOptions
-	// Project Project
-	// Bin     Bin
+	Project  *Project  `xml:"project,omitempty"`
+	Bin      *Bin      `xml:"bin,omitempty"`
 	Clip     *Clip     `xml:"clip,omitempty"`
 	Sequence *Sequence `xml:"sequence,omitempty"`
+	Extensions
 }
 
-// type Project struct {
-// 	Name name
-// 	Children
-// }
+// Project describes a project and the clips, sequences and bins it contains.
+type Project struct {
+	Name     name      `xml:"name"`
+	Children *Children `xml:"children,omitempty"`
+	Extensions
+}
 
-// type Bin struct {
-// 	Name name
-// 	Children
-// 	Labels
-// 	Comments
-// }
+// Bin describes a bin of a project and the clips, sequences and bins it contains.
+type Bin struct {
+	Name     name      `xml:"name"`
+	Children *Children `xml:"children,omitempty"`
+	Labels   *Labels   `xml:"labels,omitempty"`
+	Comments *Comments `xml:"comments,omitempty"`
+	Extensions
+}
 
-// type children struct {
-// 	clip     *clip
-// 	sequence *sequence
-// 	bin      *bin
-// }
+// Children describes the clips, sequences and bins of a project or bin. They are written in document order, see
+// Items.
+type Children struct {
+	Clips     []*Clip     `xml:"clip,omitempty"`
+	Sequences []*Sequence `xml:"sequence,omitempty"`
+	Bins      []*Bin      `xml:"bin,omitempty"`
+	Extensions
+	// the clips, sequences and bins in the order they were read
+	order []interface{}
+}
 
 // Sequence describes a collection of clips and generators sequenced in relation to each other by time, layer, and position.
 type Sequence struct {
+	ID               string           `xml:"id,attr,omitempty"`
 	Name             name             `xml:"name"`
 	Duration         duration         `xml:"duration"`
 	Rate             *Rate            `xml:"rate"`
@@ -63,47 +74,69 @@ type Sequence struct {
 	Out              out              `xml:"out,omitempty"`
 	TimeCode         *TimeCode        `xml:"timecode,omitempty"`
 	Media            *Media           `xml:"media,omitempty"`
-	Marker           *Marker          `xml:"marker,omitempty"`
+	Markers          []*Marker        `xml:"marker,omitempty"`
 	Sequence         *Sequence        `xml:"sequence,omitempty"`
 	Labels           *Labels          `xml:"labels,omitempty"`
 	Comment          comment          `xml:"comment,omitempty"`
 	MasterClipID     masterClipID     `xml:"masterclipid,omitempty"`
 	IsMasterClip     isMasterClip     `xml:"ismasterclip,omitempty"`
-	LoggingInfo      *LoggingInfo     `xml:"descriptionlogginginfo,omitempty"`
+	LoggingInfo      *LoggingInfo     `xml:"logginginfo,omitempty"`
 	FilmData         *FilmData        `xml:"filmdata,omitempty"`
 	File             *File            `xml:"file,omitempty"`
 	PixelAspectRatio pixelAspectRatio `xml:"pixelAspectRatio,omitempty"`
 	UUID             *uuid.UUID       `xml:"uuid,omitempty"`
 	UpdateBehavior   updateBehavior   `xml:"updatebehavior,omitempty"`
+	Extensions
 }
 
 // Media describes specific media tracks for a clip or a sequence.
 type Media struct {
-	Audio *Audio `xml:"audio,omitempty"`
 	Video *Video `xml:"video,omitempty"`
+	Audio *Audio `xml:"audio,omitempty"`
+	Extensions
 }
 
-// Track describes data specific to one or more video or audio elements for a track.
+// tracks lists the video tracks followed by the audio tracks of media.
+func (m *Media) tracks() []*Track {
+	var ts []*Track
+	if m.Video != nil {
+		ts = append(ts, m.Video.Tracks...)
+	}
+	if m.Audio != nil {
+		ts = append(ts, m.Audio.Tracks...)
+	}
+
+	return ts
+}
+
+// Track describes data specific to one or more video or audio elements for a track. Its clip items, generator items
+// and transition items are written interleaved in document order, see Items.
 type Track struct {
-	ClipItem *ClipItem `xml:"clipitem,omitempty"`
-	Enabled  enabled   `xml:"enabled,omitempty"`
-	Locked   locked    `xml:"locked,omitempty"`
+	ClipItems       []*ClipItem       `xml:"clipitem,omitempty"`
+	GeneratorItems  []*GeneratorItem  `xml:"generatoritem,omitempty"`
+	TransitionItems []*TransitionItem `xml:"transitionitem,omitempty"`
+	Enabled         enabled           `xml:"enabled,omitempty"`
+	Locked          locked            `xml:"locked,omitempty"`
+	Extensions
+	// the clip, generator and transition items in the order they were read
+	order []interface{}
 }
 
-type locked bool
+type locked = fcpBool
 
 type outputChannelIndex int
 
 // Link describes a link between different clips in a sequence.
 type Link struct {
-	LinkClipPref linkClipPref `xml:"linkclippref,omitempty"`
-	MediaType    mediaType    `xml:"mediatype,omitempty"`
-	TrackIndex   trackIndex   `xml:"trackindex,omitempty"`
-	ClipIndex    clipIndex    `xml:"clipindex,omitempty"`
-	GroupIndex   groupIndex   `xml:"groupindex,omitempty"`
+	LinkClipRef linkClipRef `xml:"linkclipref,omitempty"`
+	MediaType   mediaType   `xml:"mediatype,omitempty"`
+	TrackIndex  trackIndex  `xml:"trackindex,omitempty"`
+	ClipIndex   clipIndex   `xml:"clipindex,omitempty"`
+	GroupIndex  groupIndex  `xml:"groupindex,omitempty"`
+	Extensions
 }
 
-type linkClipPref string
+type linkClipRef string
 
 type clipIndex int
 
@@ -115,6 +148,7 @@ type updateBehavior string // enum
 
 // Clip describes an encoded clip in the Browser.
 type Clip struct {
+	ID           string       `xml:"id,attr,omitempty"`
 	Name         name         `xml:"name"`
 	Duration     duration     `xml:"duration"`
 	Rate         *Rate        `xml:"rate"`
@@ -124,7 +158,7 @@ type Clip struct {
 	IsMasterClip isMasterClip `xml:"ismasterclip,omitempty"`
 	Enabled      enabled      `xml:"enabled,omitempty"`
 	// media
-	// marker
+	Markers      []*Marker    `xml:"marker,omitempty"`
 	Anamorphic   anamorphic   `xml:"anamorphic,omitempty"`
 	AlphaType    alphaType    `xml:"alphatype,omitempty"`
 	AlphaReverse alphaReverse `xml:"alphareverse,omitempty"`
@@ -133,7 +167,7 @@ type Clip struct {
 	// sourceTrack
 	CompositeMode compositeMode `xml:"compositemode,omitempty"`
 	// subClipInfo
-	// filter
+	Filters          []*Filter        `xml:"filter,omitempty"`
 	StillFrame       stillFrame       `xml:"stillframe,omitempty"`
 	StillFrameOffset stillFrameOffset `xml:"stillframeoffset,omitempty"`
 	StartOffset      startOffset      `xml:"startoffset,omitempty"`
@@ -141,10 +175,12 @@ type Clip struct {
 	File             *File            `xml:"file,omitempty"`
 	// loggingInfo
 	// timeCode
+	Extensions
 }
 
 // ClipItem describes a clip in a track.
 type ClipItem struct {
+	ID               string           `xml:"id,attr,omitempty"`
 	Name             name             `xml:"name"`
 	Duration         duration         `xml:"duration"`
 	Rate             *Rate            `xml:"rate"`
@@ -155,12 +191,12 @@ type ClipItem struct {
 	Enabled          enabled          `xml:"enabled,omitempty"`
 	Start            start            `xml:"start"`
 	End              end              `xml:"end"`
-	Link             *Link            `xml:"link,omitempty"`
+	Links            []*Link          `xml:"link,omitempty"`
 	SyncOffset       syncOffset       `xml:"syncoffset,omitempty"`
 	LoggingInfo      *LoggingInfo     `xml:"logginginfo,omitempty"`
 	File             *File            `xml:"file,omitempty"`
 	TimeCode         *TimeCode        `xml:"timecode,omitempty"`
-	Marker           *Marker          `xml:"marker,omitempty"`
+	Markers          []*Marker        `xml:"marker,omitempty"`
 	Anamorphic       anamorphic       `xml:"anamorphic,omitempty"`
 	AlphaType        alphaType        `xml:"alphatype,omitempty"`
 	AlphaReverse     alphaReverse     `xml:"alphareverse,omitempty"`
@@ -168,26 +204,27 @@ type ClipItem struct {
 	Comments         *Comments        `xml:"comments,omitempty"`
 	SourceTrack      *SourceTrack     `xml:"sourcetrack,omitempty"`
 	CompositeMode    compositeMode    `xml:"compositemode,omitempty"`
-	SubClipInfo      *SubClipInfo     `xml:"sublipinfo,omitempty"`
-	Filter           *Filter          `xml:"filter,omitempty"`
+	SubClipInfo      *SubClipInfo     `xml:"subclipinfo,omitempty"`
+	Filters          []*Filter        `xml:"filter,omitempty"`
 	StillFrame       stillFrame       `xml:"stillframe,omitempty"`
 	StillFrameOffset stillFrameOffset `xml:"stillframeoffset,omitempty"`
 	Sequence         *Sequence        `xml:"sequence,omitempty"`
 	StartOffset      startOffset      `xml:"startoffset,omitempty"`
 	EndOffset        endOffset        `xml:"endoffset,omitempty"`
+	Extensions
 }
 
-type anamorphic bool
+type anamorphic = fcpBool
 
 type alphaType string // enum alphatype
 
-type alphaReverse bool
+type alphaReverse = fcpBool
 
 type compositeMode string // enum compositemode
 
 type masterClipID string
 
-type isMasterClip bool
+type isMasterClip = fcpBool
 
 // LoggingInfo describes logging information for a clip.
 type LoggingInfo struct {
@@ -196,6 +233,7 @@ type LoggingInfo struct {
 	ShotTake    shotTake    `xml:"shottake,omitempty"`
 	LogNote     logNote     `xml:"lognote,omitempty"`
 	Good        good        `xml:"good,omitempty"`
+	Extensions
 }
 
 type description string
@@ -206,12 +244,13 @@ type shotTake string
 
 type logNote string
 
-type good bool
+type good = fcpBool
 
 // Labels describes Label and Label 2 information for a clip.
 type Labels struct {
 	Label  label `xml:"label,omitempty"`
 	Label2 label `xml:"label2,omitempty"`
+	Extensions
 }
 
 type label string
@@ -224,12 +263,14 @@ type Comments struct {
 	MasterComment4 comment `xml:"mastercomment4,omitempty"`
 	ClipCommentA   comment `xml:"clipcommenta,omitempty"`
 	ClipCommentB   comment `xml:"clipcommentb,omitempty"`
+	Extensions
 }
 
 // SourceTrack describes details of the media connected with a clip.
 type SourceTrack struct {
 	MediaType  mediaType  `xml:"mediatype,omitempty"`
 	TrackIndex trackIndex `xml:"trackindex,omitempty"`
+	Extensions
 }
 
 type start int
@@ -240,13 +281,14 @@ type end int
 type SubClipInfo struct {
 	StartOffset startOffset `xml:"startoffset,omitempty"`
 	EndOffset   endOffset   `xml:"endoffset,omitempty"`
+	Extensions
 }
 
 type startOffset int
 
 type endOffset int
 
-type stillFrame bool
+type stillFrame = fcpBool
 
 type stillFrameOffset int
 
@@ -256,17 +298,18 @@ type syncOffset int
 
 // Video describes data specific to video media.
 type Video struct {
-	Track                 *Track                 `xml:"track,omitempty"`
+	Tracks                []*Track               `xml:"track,omitempty"`
 	Duration              duration               `xml:"duration,omitempty"`
-	Format                *Format                `xml:"forma,omitemptyt"`
+	Format                *Format                `xml:"format,omitempty"`
 	SampleCharacteristics *SampleCharacteristics `xml:"samplecharacteristics,omitempty"`
 	In                    in                     `xml:"in,omitempty"`
 	Out                   out                    `xml:"out,omitempty"`
+	Extensions
 }
 
 // Audio describes data specific to audio media.
 type Audio struct {
-	Track                 *Track                 `xml:"track,omitempty"`
+	Tracks                []*Track               `xml:"track,omitempty"`
 	Format                *Format                `xml:"format,omitempty"`
 	Outputs               *Outputs               `xml:"outputs,omitempty"`
 	In                    in                     `xml:"in,omitempty"`
@@ -276,6 +319,7 @@ type Audio struct {
 	TrackCount            trackCount             `xml:"trackcount,omitempty"`
 	Rate                  *Rate                  `xml:"rate,omitempty"`
 	Duration              duration               `xml:"duration,omitempty"`
+	Extensions
 }
 
 type channelCount int
@@ -290,7 +334,7 @@ type name string
 
 type duration int
 
-type enabled bool
+type enabled = fcpBool
 
 // File describes an encoded media file used by a Clip.
 type File struct {
@@ -301,6 +345,7 @@ type File struct {
 	PathURL  pathURL   `xml:"pathurl,omitempty"`
 	TimeCode *TimeCode `xml:"timecode,omitempty"`
 	Media    *Media    `xml:"media,omitempty"`
+	Extensions
 }
 
 type pathURL string
@@ -311,6 +356,7 @@ type Marker struct {
 	In      in      `xml:"in"`
 	Out     out     `xml:"out"`
 	Comment comment `xml:"comment,omitempty"`
+	Extensions
 }
 
 type comment string
@@ -330,12 +376,13 @@ type layerIndex int
 // Rate describes an encoded time scale to interpret time values for a higher component.
 type Rate struct {
 	TimeBase int  `xml:"timebase,omitempty"`
-	NTSC     bool `xml:"ntsc,omitempty"`
+	NTSC     ntsc `xml:"ntsc,omitempty"`
+	Extensions
 }
 
 type timebase int
 
-type ntsc bool
+type ntsc = fcpBool
 
 // TimeCode describes an encoded value for a clip, sequence, or file.
 type TimeCode struct {
@@ -343,6 +390,8 @@ type TimeCode struct {
 	Frame          frame          `xml:"frame,omitempty"`
 	DisplayFormat  displayFormat  `xml:"displayformat,omitempty"`
 	Rate           *Rate          `xml:"rate"`
+	Reel           *Reel          `xml:"reel,omitempty"`
+	Extensions
 }
 
 type timeCodeString string
@@ -353,38 +402,43 @@ type displayFormat string // enum displayformat
 
 type field int
 
-// type reel struct {
-// 	name
-// }
+// Reel describes the source tape or card of a timecode.
+type Reel struct {
+	Name name `xml:"name,omitempty"`
+	Extensions
+}
 
 type source string
 
 // Section: Effects
 
-// type generatorItem struct {
-// 	name
-// 	duration
-// 	rate
-// 	in
-// 	out
-// 	start
-// 	end
-// 	enabled
-// 	anamorphic
-// 	alphaType
-// 	effect
-// 	sourceTrack
-// }
-
-// type transitionItem struct {
-// 	rate
-// 	start
-// 	end
-// 	alignment
-// 	effect
-// 	name
-// 	duration
-// }
+// GeneratorItem describes a generated clip in a track, e.g. a slug, colour matte or title.
+type GeneratorItem struct {
+	ID          string       `xml:"id,attr,omitempty"`
+	Name        name         `xml:"name"`
+	Duration    duration     `xml:"duration"`
+	Rate        *Rate        `xml:"rate"`
+	In          in           `xml:"in,omitempty"`
+	Out         out          `xml:"out,omitempty"`
+	Start       start        `xml:"start"`
+	End         end          `xml:"end"`
+	Enabled     enabled      `xml:"enabled,omitempty"`
+	Anamorphic  anamorphic   `xml:"anamorphic,omitempty"`
+	AlphaType   alphaType    `xml:"alphatype,omitempty"`
+	Effect      *Effect      `xml:"effect,omitempty"`
+	SourceTrack *SourceTrack `xml:"sourcetrack,omitempty"`
+	Extensions
+}
+
+// TransitionItem describes a transition between clips in a track.
+type TransitionItem struct {
+	Rate      *Rate     `xml:"rate,omitempty"`
+	Start     start     `xml:"start"`
+	End       end       `xml:"end"`
+	Alignment alignment `xml:"alignment,omitempty"`
+	Effect    *Effect   `xml:"effect,omitempty"`
+	Extensions
+}
 
 type alignment string // enum alignment
 
@@ -394,6 +448,7 @@ type Filter struct {
 	Start   start   `xml:"start,omitempty"`
 	End     end     `xml:"end,omitempty"`
 	Effect  *Effect `xml:"effect,omitempty"`
+	Extensions
 }
 
 // Effect describes an effect or processing operation.
@@ -403,7 +458,13 @@ type Effect struct {
 	EffectType     effectType     `xml:"effecttype"`
 	MediaType      mediaType      `xml:"mediatype"`
 	EffectCategory effectCategory `xml:"effectcategory,omitempty"`
-	Parameter      *Parameter     `xml:"parameter,omitempty"`
+	WipeCode       wipeCode       `xml:"wipecode,omitempty"`
+	WipeAccuracy   wipeAccuracy   `xml:"wipeaccuracy,omitempty"`
+	StartRatio     startRatio     `xml:"startratio,omitempty"`
+	EndRatio       endRatio       `xml:"endratio,omitempty"`
+	Reverse        reverse        `xml:"reverse,omitempty"`
+	Parameters     []*Parameter   `xml:"parameter,omitempty"`
+	Extensions
 }
 
 type effectID string
@@ -414,40 +475,45 @@ type effectType string // enum effecttype
 
 type wipeCode int
 
+type wipeAccuracy int
+
 type startRatio float32
 
 type endRatio float32
 
-type reverse bool
+type reverse = fcpBool
 
 // Parameter describes a parameter for an effect.
 type Parameter struct {
 	ParameterID     string           `xml:"parameterid,omitempty"`
 	Name            name             `xml:"name,omitempty"`
 	Value           *Value           `xml:"value,omitempty"`
-	KeyFrame        *KeyFrame        `xml:"keyframe,omitempty"`
+	KeyFrames       []*KeyFrame      `xml:"keyframe,omitempty"`
 	ValueMin        valueMin         `xml:"valuemin,omitempty"`
-	ValueMax        valueMin         `xml:"valuemax,omitempty"`
+	ValueMax        valueMax         `xml:"valuemax,omitempty"`
 	ValueList       *ValueList       `xml:"valuelist,omitempty"`
 	Interpolation   *Interpolation   `xml:"interpolation,omitempty"`
 	AppSpecificData *AppSpecificData `xml:"appspecificdata,omitempty"`
+	Extensions
 }
 
 type parameterID string
 
-type valueMin int
+type valueMin float64
 
-type valueMax int
+type valueMax float64
 
 // ValueList describes information about a pop-up list in a parameter.
 type ValueList struct {
-	ValueEntry `xml:"valueentry,omitempty"`
+	ValueEntries []*ValueEntry `xml:"valueentry,omitempty"`
+	Extensions
 }
 
 // ValueEntry describes information about the choice in a pop-up list in a parameter.
 type ValueEntry struct {
 	Name  name  `xml:"name,omitempty"`
 	Value Value `xml:"value,omitempty"`
+	Extensions
 }
 
 // Value describes a fixed value for an effect parameter or a keyframe.
@@ -459,6 +525,7 @@ type Value struct {
 	Alpha int    `xml:"alpha,omitempty"`
 	Horiz horiz  `xml:"horiz,omitempty"`
 	Vert  vert   `xml:"vert,omitempty"`
+	Extensions
 }
 
 // ColorValue describes color information that can be pulled from a value.
@@ -505,6 +572,16 @@ func (v Value) GetNumber() int {
 	return i
 }
 
+// GetFloat pulls collected decimal number data from a value.
+func (v Value) GetFloat() float64 {
+	f, err := strconv.ParseFloat(v.Data, 64)
+	if err != nil {
+		return 0
+	}
+
+	return f
+}
+
 // GetPosition pulls collected position data from a value.
 func (v Value) GetPosition() PositionValue {
 	return PositionValue{
@@ -522,6 +599,7 @@ type KeyFrame struct {
 	OutScale      outScale       `xml:"outscale,omitempty"`
 	InBEZ         *InBEZ         `xml:"inbez,omitempty"`
 	OutBEZ        *OutBEZ        `xml:"outbez,omitempty"`
+	Extensions
 }
 
 type when int
@@ -532,6 +610,7 @@ type inScale int
 type InBEZ struct {
 	Horiz horiz `xml:"horiz,omitempty"`
 	Vert  vert  `xml:"vert,omitempty"`
+	Extensions
 }
 
 type outScale int
@@ -540,15 +619,17 @@ type outScale int
 type OutBEZ struct {
 	Horiz horiz `xml:"horiz,omitempty"`
 	Vert  vert  `xml:"vert,omitempty"`
+	Extensions
 }
 
-type horiz int
+type horiz float64
 
-type vert int
+type vert float64
 
 // Interpolation describes the type of curve interpretation and data to use in the parent element.
 type Interpolation struct {
 	Name name `xml:"name"`
+	Extensions
 }
 
 // Section: Sequence Settings
@@ -557,6 +638,7 @@ type Interpolation struct {
 type Format struct {
 	SampleCharacteristics *SampleCharacteristics `xml:"samplecharacteristics,omitempty"`
 	AppSpecificData       *AppSpecificData       `xml:"appspecificdata,omitempty"`
+	Extensions
 }
 
 // SampleCharacteristics describes characteristics of video or audio media.
@@ -569,6 +651,7 @@ type SampleCharacteristics struct {
 	Rate             *Rate            `xml:"rate,omitempty"`
 	ColorDepth       colorDepth       `xml:"colordepth,omitempty"`
 	Codec            *Codec           `xml:"codec,omitempty"`
+	Extensions
 }
 
 type width int
@@ -585,6 +668,7 @@ type colorDepth int // enum colordepth
 type Codec struct {
 	Name            name             `xml:"name,omitempty"`
 	AppSpecificData *AppSpecificData `xml:"appspecificdata,omitempty"`
+	Extensions
 }
 
 type depth int // enum 8|16
@@ -593,7 +677,8 @@ type sampleRate int // enum 32000|44100|48000
 
 // Outputs describes information about audio outputs.
 type Outputs struct {
-	Group *Group `xml:"group,omitempty"`
+	Groups []*Group `xml:"group,omitempty"`
+	Extensions
 }
 
 // Group describes information about a group of audio output channels.
@@ -601,7 +686,8 @@ type Group struct {
 	Index       index        `xml:"index,omitempty"`
 	NumChannels channelCount `xml:"numchannels,omitempty"`
 	DownMix     downMix      `xml:"downmix,omitempty"`
-	Channel     *Channel     `xml:"channel,omitempty"`
+	Channels    []*Channel   `xml:"channel,omitempty"`
+	Extensions
 }
 
 type index int
@@ -611,6 +697,7 @@ type downMix int // enum downmix
 // Channel describes the output device index of a channel in a group.
 type Channel struct {
 	Index index `xml:"index,omitempty"`
+	Extensions
 }
 
 // Section: Application Specific Data
@@ -621,6 +708,7 @@ type AppSpecificData struct {
 	AppManufacturer appManufacturer `xml:"appmanufacturer,omitempty"`
 	AppVersion      appVersion      `xml:"appversion,omitempty"`
 	// Data Data
+	Extensions
 }
 
 type appName string
@@ -640,9 +728,9 @@ type appVersion string
 // 	renderMode
 // }
 
-type useYUV bool
+type useYUV = fcpBool
 
-type useSuperWhite bool
+type useSuperWhite = fcpBool
 
 type renderMode string // enum rendermode
 
@@ -676,7 +764,9 @@ type dataRate int
 // Section: Film Data
 
 // FilmData describes metadata imported from Cinema Tools.
-type FilmData struct{}
+type FilmData struct {
+	Extensions
+}
 
 // Section: Import Options
 
@@ -690,26 +780,27 @@ type FilmData struct{}
 // 	filterIncludeSequenceSettings
 // }
 
-type createNewProject bool
+type createNewProject = fcpBool
 
 type targetProjectName string
 
 type defSequencePresetName string
 
-type filterReconnectMediaFiles bool // default: true
+type filterReconnectMediaFiles = fcpBool // default: true
 
-type filterIncludeMarkers bool // default: true
+type filterIncludeMarkers = fcpBool // default: true
 
-type filterIncludeEffects bool // default: true
+type filterIncludeEffects = fcpBool // default: true
 
-type filterIncludeSequenceSettings bool // default: true
+type filterIncludeSequenceSettings = fcpBool // default: true
 
-// ImportRawXEML imports XML into a raw XEML data tree
+// ImportRawXEML imports XML into a raw XEML data tree, panicking on malformed input. Use ParseRawXEML to handle
+// import errors.
 func ImportRawXEML(s []byte) RawXEML {
-	var xs RawXEML
-	if err := xml.Unmarshal(s, &xs); err != nil {
+	xs, err := ParseRawXEML(s)
+	if err != nil {
 		panic(err)
 	}
 
-	return RawXEML(xs)
+	return xs
 }
@@ -2,6 +2,8 @@ package converter
 
 import (
 	"encoding/xml"
+	"io/ioutil"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -285,10 +287,15 @@ func TestXEMLMarshalling(t *testing.T) {
 			},
 			Media: &Media{
 				Video: &Video{
-					Track: &Track{
-						ClipItem: &ClipItem{
-							Link: &Link{
-								LinkClipPref: "foo",
+					Tracks: []*Track{
+						{
+							ClipItems: []*ClipItem{
+								{
+									Links: []*Link{
+										{LinkClipRef: "foo"},
+										{LinkClipRef: "bar"},
+									},
+								},
 							},
 						},
 					},
@@ -315,7 +322,10 @@ func TestXEMLMarshalling(t *testing.T) {
 								<start>0</start>
 								<end>0</end>
 								<link>
-									<linkclippref>foo</linkclippref>
+									<linkclipref>foo</linkclipref>
+								</link>
+								<link>
+									<linkclipref>bar</linkclipref>
 								</link>
 							</clipitem>
 						</track>
@@ -332,3 +342,55 @@ func TestXEMLMarshalling(t *testing.T) {
 		t.Log("Got: " + a)
 	}
 }
+
+func TestImportingRepeatedElements(t *testing.T) {
+	s, err := ioutil.ReadFile("export-examples/resolve-export.xml")
+	if err != nil {
+		t.Fatal("example export could not be read: " + err.Error())
+	}
+
+	x, err := ParseRawXEML(s)
+	if err != nil {
+		t.Fatal("example export could not be imported: " + err.Error())
+	}
+
+	video := x.Sequence.Media.Video.Tracks[0].ClipItems[0]
+
+	if len(video.Filters) != 3 {
+		t.Errorf("clip item filters not imported: %d", len(video.Filters))
+	}
+
+	if len(video.Filters[1].Effect.Parameters) != 4 {
+		t.Error("effect parameters not imported")
+	}
+
+	if video.Filters[1].Effect.Parameters[2].Name != "Rotation" {
+		t.Error("effect parameter order not preserved")
+	}
+
+	if len(video.Links) != 2 {
+		t.Error("clip item links not imported")
+	}
+
+	if len(x.Sequence.Media.Audio.Tracks) != 1 || x.Sequence.Media.Audio.Tracks[0].ClipItems[0].ID != "NAMI.mp4 105" {
+		t.Fatal("audio track clip items not imported")
+	}
+
+	if len(x.Sequence.Media.Audio.Tracks[0].ClipItems[0].Filters) != 2 {
+		t.Error("audio clip item filters not imported")
+	}
+
+	r, err := xml.Marshal(x)
+	if err != nil {
+		t.Fatal("imported export could not be marshalled: " + err.Error())
+	}
+
+	rx, err := ParseRawXEML(r)
+	if err != nil {
+		t.Fatal("marshalled export could not be imported: " + err.Error())
+	}
+
+	if !reflect.DeepEqual(x, rx) {
+		t.Error("marshalled export does not match the imported export")
+	}
+}
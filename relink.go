@@ -0,0 +1,276 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// RelinkRule describes how the paths of files are remapped, e.g. from a macOS volume to an NFS mount.
+type RelinkRule struct {
+	// From is a leading part of a path, matched at a path separator, e.g. /Volumes/Media. It is a regular expression
+	// when Regexp is set, e.g. ^([A-Za-z]):/ to match Windows drive letters.
+	From string
+	// To replaces what From matches, e.g. /mnt/media. It may refer to groups of a regular expression, e.g. /mnt/$1/
+	To     string
+	Regexp bool
+}
+
+// RelinkOptions describes how Relink finds the media of files.
+type RelinkOptions struct {
+	// Rules remap paths. The first rule that matches a path applies, and paths no rule matches are kept.
+	Rules []RelinkRule
+	// SearchRoots are directories searched for files of the same name as media that cannot be found, including
+	// their subdirectories.
+	SearchRoots []string
+	// Sizes gives the size in bytes of files by their original path URL, e.g. from a media manifest, to pick between
+	// files of the same name found under the search roots.
+	Sizes map[string]int64
+	// SkipCheck rewrites paths by the rules without checking that media exists or searching for it, e.g. for media
+	// that is only mounted on another machine.
+	SkipCheck bool
+}
+
+// RelinkResult describes the media found for a file.
+type RelinkResult struct {
+	// File is the canonical file, see MediaRegistry.
+	File *File
+	// OldURL is the path URL of the file before it was relinked.
+	OldURL string
+	// NewURL is the path URL the file was relinked to, empty when it was not relinked.
+	NewURL string
+	// Candidates lists the paths of the files found for a file that is ambiguous.
+	Candidates []string
+}
+
+// RelinkReport describes the files Relink relinked or could not relink. Files whose media is where they say it is
+// are not reported.
+type RelinkReport struct {
+	Relinked []*RelinkResult
+	// Ambiguous lists files for which more than one file was found under the search roots.
+	Ambiguous []*RelinkResult
+	// Missing lists files whose media could not be found, and files whose path URL is not a file URL on this
+	// machine.
+	Missing []*RelinkResult
+}
+
+// Relink points the files of a document at their media, rewriting their path URLs in place. The path of every
+// unique file is remapped by the rules. When no media exists at the remapped path, the search roots are searched
+// for files of the same name, and of the same size when it is known. Files that are found at a new path have every
+// definition with the same path URL rewritten, keeping the localhost of the original URL. Files that are ambiguous
+// or missing are left as they are.
+func (x *RawXEML) Relink(o RelinkOptions) (*RelinkReport, error) {
+	rl := &relinker{options: o}
+	for _, r := range o.Rules {
+		c, err := compileRelinkRule(r)
+		if err != nil {
+			return nil, err
+		}
+		rl.rules = append(rl.rules, c)
+	}
+
+	reg := x.MediaRegistry()
+	report := &RelinkReport{}
+	for _, f := range reg.Files {
+		oldURL := string(f.PathURL)
+		if oldURL == "" {
+			continue
+		}
+
+		result := &RelinkResult{File: f, OldURL: oldURL}
+		p, err := DecodeFileURL(oldURL)
+		if err != nil {
+			report.Missing = append(report.Missing, result)
+			continue
+		}
+
+		found, candidates := rl.find(oldURL, p)
+		switch {
+		case len(candidates) > 1:
+			result.Candidates = candidates
+			report.Ambiguous = append(report.Ambiguous, result)
+			continue
+		case found == "":
+			report.Missing = append(report.Missing, result)
+			continue
+		case found == p:
+			continue
+		}
+
+		result.NewURL = EncodeFileURL(found, strings.HasPrefix(strings.ToLower(oldURL), "file://localhost/"))
+		for _, occ := range reg.Occurrences(f) {
+			if string(occ.File.PathURL) == oldURL {
+				occ.File.PathURL = pathURL(result.NewURL)
+			}
+		}
+		report.Relinked = append(report.Relinked, result)
+	}
+
+	return report, nil
+}
+
+type relinker struct {
+	options RelinkOptions
+	rules   []*regexp.Regexp
+	// files under the search roots by name, indexed on first use
+	index map[string][]string
+	sizes map[string]int64
+}
+
+// compileRelinkRule turns a rule into a regular expression, matching prefixes at a path separator.
+func compileRelinkRule(r RelinkRule) (*regexp.Regexp, error) {
+	if r.Regexp {
+		re, err := regexp.Compile(r.From)
+		if err != nil {
+			return nil, fmt.Errorf("relink rule %q is not a valid regular expression: %w", r.From, err)
+		}
+		return re, nil
+	}
+
+	if r.From == "" {
+		return nil, errors.New("relink rule has no prefix")
+	}
+
+	prefix := strings.TrimSuffix(r.From, "/")
+
+	return regexp.MustCompile("^" + regexp.QuoteMeta(prefix) + "(?:/|$)"), nil
+}
+
+// remap applies the first rule that matches a path.
+func (rl *relinker) remap(p string) string {
+	for i, re := range rl.rules {
+		loc := re.FindStringSubmatchIndex(p)
+		if loc == nil {
+			continue
+		}
+
+		r := rl.options.Rules[i]
+		if !r.Regexp {
+			// the separator after the prefix, if any, is kept
+			return strings.TrimSuffix(r.To, "/") + p[len(strings.TrimSuffix(r.From, "/")):]
+		}
+
+		return p[:loc[0]] + string(re.ExpandString(nil, r.To, p, loc)) + p[loc[1]:]
+	}
+
+	return p
+}
+
+// find returns the path of the media of a file, or the candidates found when there is more than one.
+func (rl *relinker) find(oldURL string, p string) (string, []string) {
+	mapped := rl.remap(p)
+	if rl.options.SkipCheck || isFile(mapped) {
+		return mapped, nil
+	}
+
+	if len(rl.options.SearchRoots) == 0 {
+		return "", nil
+	}
+
+	rl.indexRoots()
+	candidates := rl.index[path.Base(mapped)]
+	if size, ok := rl.options.Sizes[oldURL]; ok {
+		var sized []string
+		for _, c := range candidates {
+			if rl.sizes[c] == size {
+				sized = append(sized, c)
+			}
+		}
+		candidates = sized
+	}
+
+	switch len(candidates) {
+	case 0:
+		return "", nil
+	case 1:
+		return candidates[0], nil
+	}
+
+	return "", candidates
+}
+
+func (rl *relinker) indexRoots() {
+	if rl.index != nil {
+		return
+	}
+
+	rl.index = map[string][]string{}
+	rl.sizes = map[string]int64{}
+	for _, root := range rl.options.SearchRoots {
+		// unreadable directories are skipped, their media is reported missing
+		_ = filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+			if err != nil || !info.Mode().IsRegular() {
+				return nil
+			}
+
+			p = filepath.ToSlash(p)
+			rl.index[info.Name()] = append(rl.index[info.Name()], p)
+			rl.sizes[p] = info.Size()
+
+			return nil
+		})
+	}
+}
+
+func isFile(p string) bool {
+	info, err := os.Stat(filepath.FromSlash(p))
+
+	return err == nil && info.Mode().IsRegular()
+}
+
+var windowsDrive = regexp.MustCompile(`^/[A-Za-z]:(/|$)`)
+
+// DecodeFileURL decodes the path of a file URL, e.g. file://localhost/Volumes/Media/A001.mov or file:///C:/A001.mov,
+// to a path with forward slashes, e.g. /Volumes/Media/A001.mov or C:/A001.mov. Files on other hosts become UNC
+// paths such as //server/share/A001.mov. Values that are not URLs are taken as paths.
+func DecodeFileURL(s string) (string, error) {
+	if !strings.HasPrefix(strings.ToLower(s), "file:") {
+		if strings.Contains(s, "://") {
+			return "", fmt.Errorf("%q is not a file URL", s)
+		}
+		return strings.ReplaceAll(s, `\`, "/"), nil
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", err
+	}
+
+	p := strings.ReplaceAll(u.Path, `\`, "/")
+	if u.Host != "" && !strings.EqualFold(u.Host, "localhost") {
+		return "//" + u.Host + p, nil
+	}
+
+	if windowsDrive.MatchString(p) {
+		p = p[1:]
+	}
+
+	return p, nil
+}
+
+// EncodeFileURL encodes a path with forward slashes as a file URL, e.g. file:///Volumes/Media/A001.mov, or
+// file://localhost/Volumes/Media/A001.mov as Final Cut Pro 7 and Premiere Pro write them.
+func EncodeFileURL(p string, localhost bool) string {
+	u := url.URL{Scheme: "file", Path: p}
+	if strings.HasPrefix(p, "//") {
+		parts := strings.SplitN(p[2:], "/", 2)
+		u.Host = parts[0]
+		u.Path = "/"
+		if len(parts) == 2 {
+			u.Path += parts[1]
+		}
+	} else if !strings.HasPrefix(p, "/") {
+		u.Path = "/" + p
+	}
+
+	if localhost && u.Host == "" {
+		u.Host = "localhost"
+	}
+
+	return u.String()
+}
@@ -0,0 +1,145 @@
+package converter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const namiURL = "file://localhost/Volumes/Deep%20Search/Cargill/Cargill%2002%20-%208TB%20-%20Seagate/From%20Cargill/Assets/Additional%20MP4s/NAMI.mp4"
+
+func TestDecodingFileURLs(t *testing.T) {
+	for s, want := range map[string]string{
+		namiURL:                                "/Volumes/Deep Search/Cargill/Cargill 02 - 8TB - Seagate/From Cargill/Assets/Additional MP4s/NAMI.mp4",
+		"file:///Volumes/Media/A001.mov":       "/Volumes/Media/A001.mov",
+		"file:///C:/Media/A001.mov":            "C:/Media/A001.mov",
+		"file://localhost/D%3a/A001.mov":       "D:/A001.mov",
+		"file://server/share/A001.mov":         "//server/share/A001.mov",
+		`C:\Media\A001.mov`:                    "C:/Media/A001.mov",
+		"/Volumes/Media/A001.mov":              "/Volumes/Media/A001.mov",
+		"file:///Volumes/Media/A%23001%20.mov": "/Volumes/Media/A#001 .mov",
+	} {
+		p, err := DecodeFileURL(s)
+		if err != nil || p != want {
+			t.Errorf("%s decoded to %q: %v", s, p, err)
+		}
+	}
+
+	if _, err := DecodeFileURL("http://example.com/A001.mov"); err == nil {
+		t.Error("URL that is not a file URL not reported")
+	}
+
+	for p, want := range map[string]string{
+		"/mnt/media/A 001.mov":    "file:///mnt/media/A%20001.mov",
+		"C:/Media/A001.mov":       "file:///C:/Media/A001.mov",
+		"//server/share/A001.mov": "file://server/share/A001.mov",
+	} {
+		if s := EncodeFileURL(p, false); s != want {
+			t.Errorf("%s encoded to %s", p, s)
+		}
+	}
+
+	if s := EncodeFileURL("/mnt/A.mov", true); s != "file://localhost/mnt/A.mov" {
+		t.Errorf("localhost not kept: %s", s)
+	}
+}
+
+// mediaDir creates a directory of empty media files for a test, returning its path with forward slashes.
+func mediaDir(t *testing.T, files map[string]int) string {
+	dir, err := ioutil.TempDir("", "relink")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, size := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return filepath.ToSlash(dir)
+}
+
+func TestRelinkingByRules(t *testing.T) {
+	dir := mediaDir(t, map[string]int{"Cargill 02 - 8TB - Seagate/From Cargill/Assets/Additional MP4s/NAMI.mp4": 1})
+	defer os.RemoveAll(dir)
+
+	x := ImportRawXEML(mustRead(t, "export-examples/premier-export.xml"))
+	report, err := x.Relink(RelinkOptions{Rules: []RelinkRule{
+		{From: "/Volumes/Other", To: "/mnt/other"},
+		{From: "/Volumes/Deep Search/Cargill/", To: dir},
+	}})
+	if err != nil {
+		t.Fatal("relinking failed: " + err.Error())
+	}
+
+	if len(report.Relinked) != 1 || len(report.Ambiguous) != 0 || len(report.Missing) != 0 {
+		t.Fatalf("report does not match expectations: %d %d %d", len(report.Relinked), len(report.Ambiguous), len(report.Missing))
+	}
+
+	want := EncodeFileURL(dir+"/Cargill 02 - 8TB - Seagate/From Cargill/Assets/Additional MP4s/NAMI.mp4", true)
+	if r := report.Relinked[0]; r.OldURL != namiURL || r.NewURL != want {
+		t.Errorf("relinked URL does not match expectations: %s", r.NewURL)
+	}
+
+	for _, o := range x.MediaRegistry().Occurrences(x.MediaRegistry().Lookup("file-2")) {
+		if o.File.PathURL != "" && string(o.File.PathURL) != want {
+			t.Errorf("%s: file not relinked", o.Path)
+		}
+	}
+
+	again, _ := x.Relink(RelinkOptions{})
+	if len(again.Relinked)+len(again.Ambiguous)+len(again.Missing) != 0 {
+		t.Error("file already linked reported")
+	}
+}
+
+func TestRelinkingBySearching(t *testing.T) {
+	dir := mediaDir(t, map[string]int{"a/NAMI.mp4": 10, "b/NAMI.mp4": 20, "c/other.mp4": 10})
+	defer os.RemoveAll(dir)
+
+	x := ImportRawXEML(mustRead(t, "export-examples/resolve-export.xml"))
+	report, err := x.Relink(RelinkOptions{SearchRoots: []string{dir}})
+	if err != nil {
+		t.Fatal("relinking failed: " + err.Error())
+	}
+
+	if len(report.Ambiguous) != 1 || len(report.Ambiguous[0].Candidates) != 2 {
+		t.Fatal("file found more than once not reported as ambiguous")
+	}
+
+	report, _ = x.Relink(RelinkOptions{SearchRoots: []string{dir}, Sizes: map[string]int64{report.Ambiguous[0].OldURL: 20}})
+	if len(report.Relinked) != 1 || report.Relinked[0].NewURL != EncodeFileURL(dir+"/b/NAMI.mp4", false) {
+		t.Fatal("file not found by name and size")
+	}
+
+	report, _ = x.Relink(RelinkOptions{SearchRoots: []string{dir + "/c"}, Rules: []RelinkRule{{From: "/", To: "/missing"}}})
+	if len(report.Missing) != 1 {
+		t.Error("missing file not reported")
+	}
+}
+
+func TestRelinkingWithoutChecking(t *testing.T) {
+	x := RawXEML{Clip: &Clip{File: &File{ID: "file-1", PathURL: "file:///E:/Shoot/A001.mov"}}}
+
+	report, err := x.Relink(RelinkOptions{
+		Rules:     []RelinkRule{{From: `^([A-Za-z]):/`, To: "/mnt/$1/", Regexp: true}},
+		SkipCheck: true,
+	})
+	if err != nil {
+		t.Fatal("relinking failed: " + err.Error())
+	}
+
+	if len(report.Relinked) != 1 || x.Clip.File.PathURL != "file:///mnt/E/Shoot/A001.mov" {
+		t.Errorf("drive letter not remapped: %s", x.Clip.File.PathURL)
+	}
+
+	if _, err := x.Relink(RelinkOptions{Rules: []RelinkRule{{From: "([", Regexp: true}}}); err == nil {
+		t.Error("invalid rule not reported")
+	}
+}
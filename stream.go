@@ -0,0 +1,461 @@
+package converter
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// StreamContext describes where a streamed element was found.
+type StreamContext struct {
+	// Path is the element path of the element, e.g. xmeml/sequence/media/video/track[2]/clipitem[14]
+	Path string
+	// Bins names the bins containing the element, outermost first.
+	Bins []string
+	// Sequence is the sequence containing the element, holding the elements read so far apart from its media.
+	Sequence *Sequence
+	// Media is video or audio for tracks and track items.
+	Media string
+	// Track is the index of the track among the tracks of its media, counting from 1 like link track indices.
+	Track int
+	// Nested counts the clip items holding the nested sequences a track item is in, and is 0 for track items of a
+	// sequence that is not nested. Sequence is then the innermost nested sequence.
+	Nested int
+}
+
+// StreamHandler describes the callbacks of a streaming import. Callbacks that are nil are skipped. An error returned
+// by a callback stops the import and is returned as is.
+type StreamHandler struct {
+	// Clip is called for every clip of a project, bin or document.
+	Clip func(c *Clip, ctx StreamContext) error
+	// Sequence is called at the end of every sequence. Its media is not kept.
+	Sequence func(s *Sequence, ctx StreamContext) error
+	// Track is called at the end of every track. Its items are not kept.
+	Track func(t *Track, ctx StreamContext) error
+	// ClipItem is called for every clip item of a track, after its file elements and the items of its nested
+	// sequence.
+	ClipItem func(ci *ClipItem, ctx StreamContext) error
+	// GeneratorItem is called for every generator item of a track.
+	GeneratorItem func(g *GeneratorItem, ctx StreamContext) error
+	// TransitionItem is called for every transition item of a track.
+	TransitionItem func(ti *TransitionItem, ctx StreamContext) error
+	// File is called for every file element of a clip or clip item, including those of nested sequences.
+	File func(f *File, ctx StreamContext) error
+}
+
+// StreamRawXEML imports XML from r element by element, calling the handler as clips, sequences, tracks and track
+// items are read. Only the element being handled and the sequence containing it are held in memory, so that very
+// large documents can be scanned. A clip item holding a nested sequence is held whole, and the items of its nested
+// sequence are called back from it. Problems are returned as an *ImportError, the same way as ParseRawXEML.
+func StreamRawXEML(r io.Reader, h StreamHandler) error {
+	_, err := streamRawXEML(r, h, false)
+
+	return err
+}
+
+// StreamRawXEMLLenient imports XML from r element by element the same way as StreamRawXEML, collecting recoverable
+// problems as warnings rather than aborting.
+func StreamRawXEMLLenient(r io.Reader, h StreamHandler) ([]*ImportError, error) {
+	return streamRawXEML(r, h, true)
+}
+
+func streamRawXEML(r io.Reader, h StreamHandler, lenient bool) ([]*ImportError, error) {
+	// buffered whatever r is, as the lexer reads a byte at a time
+	t := newImportTracker(bufio.NewReader(r), reflect.TypeOf(RawXEML{}), lenient)
+	s := &streamer{t: t, d: xml.NewTokenDecoder(t), h: h}
+
+	if err := s.document(); err != nil {
+		if ce, ok := err.(*streamCallbackError); ok {
+			return t.warnings, ce.err
+		}
+
+		return t.warnings, t.wrap(err)
+	}
+
+	return t.warnings, nil
+}
+
+// streamCallbackError carries an error returned by a callback so that it is not wrapped as an import problem.
+type streamCallbackError struct {
+	err error
+}
+
+func (e *streamCallbackError) Error() string {
+	return e.err.Error()
+}
+
+type streamer struct {
+	t *importTracker
+	d *xml.Decoder
+	h StreamHandler
+}
+
+// call runs a callback, marking the error it returns.
+func (s *streamer) call(err error) error {
+	if err != nil {
+		return &streamCallbackError{err}
+	}
+
+	return nil
+}
+
+// path returns the element path of the element that was started last.
+func (s *streamer) path() string {
+	return s.t.top().path
+}
+
+// children calls fn for every child element of the element being read, until its end element. fn must read the
+// child element up to and including its end element.
+func (s *streamer) children(fn func(start xml.StartElement) error) error {
+	for {
+		tok, err := s.d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if err := fn(tok); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// merge unmarshals a child element into v, which has been unmarshalled from parent so far, the same way as
+// unmarshalling the whole parent would. A nil child unmarshals the attributes of parent only.
+func (s *streamer) merge(v interface{}, parent xml.StartElement, child *xml.StartElement) error {
+	toks := []xml.Token{parent}
+	if child != nil {
+		toks = append(toks, *child)
+		for depth := 1; depth > 0; {
+			tok, err := s.d.Token()
+			if err != nil {
+				return err
+			}
+
+			switch tok.(type) {
+			case xml.StartElement:
+				depth++
+			case xml.EndElement:
+				depth--
+			}
+			toks = append(toks, xml.CopyToken(tok))
+		}
+	}
+	toks = append(toks, parent.End())
+
+	return xml.NewTokenDecoder(&tokenList{toks}).Decode(v)
+}
+
+// tokenList replays a list of tokens.
+type tokenList struct {
+	toks []xml.Token
+}
+
+// Token implements xml.TokenReader.
+func (l *tokenList) Token() (xml.Token, error) {
+	if len(l.toks) == 0 {
+		return nil, io.EOF
+	}
+
+	tok := l.toks[0]
+	l.toks = l.toks[1:]
+
+	return tok, nil
+}
+
+func (s *streamer) document() error {
+	for {
+		tok, err := s.d.Token()
+		if err != nil {
+			return err
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		if start.Name.Local != "xmeml" {
+			return fmt.Errorf("expected element type <xmeml> but have <%s>", start.Name.Local)
+		}
+
+		return s.children(func(start xml.StartElement) error {
+			switch start.Name.Local {
+			case "project":
+				return s.project(nil)
+			case "bin":
+				return s.bin(nil)
+			case "clip":
+				return s.clip(start, StreamContext{Path: s.path()})
+			case "sequence":
+				return s.sequence(start, StreamContext{Path: s.path()})
+			}
+
+			return s.d.Skip()
+		})
+	}
+}
+
+func (s *streamer) project(bins []string) error {
+	return s.children(func(start xml.StartElement) error {
+		if start.Name.Local == "children" {
+			return s.binChildren(bins)
+		}
+
+		return s.d.Skip()
+	})
+}
+
+func (s *streamer) bin(bins []string) error {
+	// copied so that items of sibling bins do not share a backing array
+	inner := append(append([]string{}, bins...), "")
+
+	return s.children(func(start xml.StartElement) error {
+		switch start.Name.Local {
+		case "name":
+			var n name
+			if err := s.d.DecodeElement(&n, &start); err != nil {
+				return err
+			}
+			inner[len(inner)-1] = string(n)
+
+			return nil
+		case "children":
+			return s.binChildren(inner)
+		}
+
+		return s.d.Skip()
+	})
+}
+
+func (s *streamer) binChildren(bins []string) error {
+	return s.children(func(start xml.StartElement) error {
+		switch start.Name.Local {
+		case "clip":
+			return s.clip(start, StreamContext{Path: s.path(), Bins: bins})
+		case "sequence":
+			return s.sequence(start, StreamContext{Path: s.path(), Bins: bins})
+		case "bin":
+			return s.bin(bins)
+		}
+
+		return s.d.Skip()
+	})
+}
+
+func (s *streamer) clip(start xml.StartElement, ctx StreamContext) error {
+	c := &Clip{}
+	if err := s.d.DecodeElement(c, &start); err != nil {
+		return err
+	}
+
+	if c.File != nil && s.h.File != nil {
+		fileCtx := ctx
+		fileCtx.Path = childPath(ctx.Path, "file", 0)
+		if err := s.call(s.h.File(c.File, fileCtx)); err != nil {
+			return err
+		}
+	}
+
+	if s.h.Clip != nil {
+		return s.call(s.h.Clip(c, ctx))
+	}
+
+	return nil
+}
+
+func (s *streamer) sequence(start xml.StartElement, ctx StreamContext) error {
+	seq := &Sequence{}
+	if err := s.merge(seq, start, nil); err != nil {
+		return err
+	}
+	ctx.Sequence = seq
+
+	parent := xml.StartElement{Name: start.Name}
+	err := s.children(func(start xml.StartElement) error {
+		if start.Name.Local == "media" {
+			return s.media(ctx)
+		}
+
+		return s.merge(seq, parent, &start)
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.h.Sequence != nil {
+		return s.call(s.h.Sequence(seq, ctx))
+	}
+
+	return nil
+}
+
+func (s *streamer) media(ctx StreamContext) error {
+	return s.children(func(start xml.StartElement) error {
+		kind := start.Name.Local
+		if kind != "video" && kind != "audio" {
+			return s.d.Skip()
+		}
+
+		track := 0
+		return s.children(func(start xml.StartElement) error {
+			if start.Name.Local != "track" {
+				return s.d.Skip()
+			}
+
+			track++
+			trackCtx := ctx
+			trackCtx.Path = s.path()
+			trackCtx.Media = kind
+			trackCtx.Track = track
+
+			return s.track(start, trackCtx)
+		})
+	})
+}
+
+func (s *streamer) track(start xml.StartElement, ctx StreamContext) error {
+	t := &Track{}
+	if err := s.merge(t, start, nil); err != nil {
+		return err
+	}
+
+	parent := xml.StartElement{Name: start.Name}
+	err := s.children(func(start xml.StartElement) error {
+		itemCtx := ctx
+		itemCtx.Path = s.path()
+
+		switch start.Name.Local {
+		case "clipitem":
+			return s.clipItem(start, itemCtx)
+		case "generatoritem":
+			g := &GeneratorItem{}
+			if err := s.d.DecodeElement(g, &start); err != nil || s.h.GeneratorItem == nil {
+				return err
+			}
+
+			return s.call(s.h.GeneratorItem(g, itemCtx))
+		case "transitionitem":
+			ti := &TransitionItem{}
+			if err := s.d.DecodeElement(ti, &start); err != nil || s.h.TransitionItem == nil {
+				return err
+			}
+
+			return s.call(s.h.TransitionItem(ti, itemCtx))
+		}
+
+		return s.merge(t, parent, &start)
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.h.Track != nil {
+		return s.call(s.h.Track(t, ctx))
+	}
+
+	return nil
+}
+
+func (s *streamer) clipItem(start xml.StartElement, ctx StreamContext) error {
+	ci := &ClipItem{}
+	if err := s.d.DecodeElement(ci, &start); err != nil {
+		return err
+	}
+
+	if s.h.File != nil {
+		var err error
+		ci.walkFiles(ctx.Path, func(f *File, path string) {
+			if err == nil {
+				fileCtx := ctx
+				fileCtx.Path = path
+				err = s.h.File(f, fileCtx)
+			}
+		})
+		if err != nil {
+			return s.call(err)
+		}
+	}
+
+	if err := s.nested(ci, ctx); err != nil {
+		return err
+	}
+
+	if s.h.ClipItem != nil {
+		return s.call(s.h.ClipItem(ci, ctx))
+	}
+
+	return nil
+}
+
+// nested calls back the track items of the sequence nested in a clip item in document order, the items nested in a
+// clip item before it.
+func (s *streamer) nested(ci *ClipItem, ctx StreamContext) error {
+	if ci.Sequence == nil || ci.Sequence.Media == nil {
+		return nil
+	}
+
+	mediaPath := childPath(childPath(ctx.Path, "sequence", 0), "media", 0)
+	kinds := []struct {
+		name   string
+		tracks []*Track
+	}{{"video", nil}, {"audio", nil}}
+	if v := ci.Sequence.Media.Video; v != nil {
+		kinds[0].tracks = v.Tracks
+	}
+	if a := ci.Sequence.Media.Audio; a != nil {
+		kinds[1].tracks = a.Tracks
+	}
+
+	for _, kind := range kinds {
+		for i, t := range kind.tracks {
+			trackCtx := ctx
+			trackCtx.Path = childPath(childPath(mediaPath, kind.name, 0), "track", i)
+			trackCtx.Sequence = ci.Sequence
+			trackCtx.Media = kind.name
+			trackCtx.Track = i + 1
+			trackCtx.Nested = ctx.Nested + 1
+
+			counts := map[string]int{}
+			itemCtx := func(element string) StreamContext {
+				c := trackCtx
+				c.Path = childPath(trackCtx.Path, element, counts[element])
+				counts[element]++
+
+				return c
+			}
+
+			for _, item := range t.Items() {
+				var err error
+				switch item := item.(type) {
+				case *ClipItem:
+					c := itemCtx("clipitem")
+					if err = s.nested(item, c); err == nil && s.h.ClipItem != nil {
+						err = s.call(s.h.ClipItem(item, c))
+					}
+				case *GeneratorItem:
+					c := itemCtx("generatoritem")
+					if s.h.GeneratorItem != nil {
+						err = s.call(s.h.GeneratorItem(item, c))
+					}
+				case *TransitionItem:
+					c := itemCtx("transitionitem")
+					if s.h.TransitionItem != nil {
+						err = s.call(s.h.TransitionItem(item, c))
+					}
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
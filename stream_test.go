@@ -0,0 +1,317 @@
+package converter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestStreamingExports(t *testing.T) {
+	for _, path := range []string{"export-examples/premier-export.xml", "export-examples/resolve-export.xml"} {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal("example export could not be opened: " + err.Error())
+		}
+
+		var sequences []*Sequence
+		var items []string
+		var files []string
+		tracks := map[string]int{}
+		err = StreamRawXEML(f, StreamHandler{
+			Sequence: func(s *Sequence, ctx StreamContext) error {
+				sequences = append(sequences, s)
+				return nil
+			},
+			Track: func(tr *Track, ctx StreamContext) error {
+				if len(tr.ClipItems) != 0 {
+					t.Error("streamed track kept its items")
+				}
+				if ctx.Media == "" || ctx.Track < 1 {
+					t.Error("track context not set")
+				}
+				tracks[ctx.Media]++
+				return nil
+			},
+			ClipItem: func(ci *ClipItem, ctx StreamContext) error {
+				if ctx.Sequence == nil || ctx.Sequence.Rate == nil {
+					t.Error("sequence header not available to clip items")
+				}
+				items = append(items, ctx.Path+" "+ci.ID)
+				return nil
+			},
+			File: func(f *File, ctx StreamContext) error {
+				files = append(files, ctx.Path+" "+f.ID)
+				return nil
+			},
+		})
+		f.Close()
+		if err != nil {
+			t.Fatal("example export could not be streamed: " + err.Error())
+		}
+
+		x, _ := ParseRawXEML(mustRead(t, path))
+
+		if len(sequences) != 1 || sequences[0].Name != x.Sequence.Name || !sequences[0].Rate.Equal(*x.Sequence.Rate) {
+			t.Fatalf("%s: sequence not streamed", path)
+		}
+		if sequences[0].Media != nil || !reflect.DeepEqual(sequences[0].Markers, x.Sequence.Markers) {
+			t.Errorf("%s: streamed sequence does not match expectations", path)
+		}
+
+		if tracks["video"] != len(x.Sequence.Media.Video.Tracks) || tracks["audio"] != len(x.Sequence.Media.Audio.Tracks) {
+			t.Errorf("%s: tracks not streamed: %v", path, tracks)
+		}
+
+		var wantItems []string
+		for i, tr := range x.Sequence.Media.Video.Tracks {
+			for j, ci := range tr.ClipItems {
+				wantItems = append(wantItems, childPath(childPath("xmeml/sequence/media/video", "track", i), "clipitem", j)+" "+ci.ID)
+			}
+		}
+		for i, tr := range x.Sequence.Media.Audio.Tracks {
+			for j, ci := range tr.ClipItems {
+				wantItems = append(wantItems, childPath(childPath("xmeml/sequence/media/audio", "track", i), "clipitem", j)+" "+ci.ID)
+			}
+		}
+		if !reflect.DeepEqual(items, wantItems) {
+			t.Errorf("%s: clip items not streamed in document order", path)
+		}
+
+		var wantFiles []string
+		x.walkFiles(func(f *File, path string) {
+			wantFiles = append(wantFiles, path+" "+f.ID)
+		})
+		if !reflect.DeepEqual(files, wantFiles) {
+			t.Errorf("%s: files not streamed in document order", path)
+		}
+	}
+}
+
+func TestStreamingBins(t *testing.T) {
+	xc := `<xmeml version="5">
+		<project>
+			<name>Feature</name>
+			<children>
+				<bin>
+					<name>Dailies</name>
+					<children>
+						<clip id="masterclip-1">
+							<name>A001</name>
+							<duration>1000</duration>
+							<rate>
+								<timebase>25</timebase>
+							</rate>
+							<file id="file-1">
+								<name>A001.mov</name>
+							</file>
+						</clip>
+					</children>
+				</bin>
+				<bin>
+					<name>Cuts</name>
+					<children>
+						<sequence id="sequence-1" MZ.EditLine="0">
+							<name>Cut 1</name>
+							<duration>0</duration>
+							<rate>
+								<timebase>25</timebase>
+							</rate>
+						</sequence>
+					</children>
+				</bin>
+			</children>
+		</project>
+	</xmeml>`
+
+	var names []string
+	err := StreamRawXEML(strings.NewReader(xc), StreamHandler{
+		Clip: func(c *Clip, ctx StreamContext) error {
+			names = append(names, strings.Join(ctx.Bins, "/")+" "+string(c.Name)+" "+ctx.Path)
+			return nil
+		},
+		Sequence: func(s *Sequence, ctx StreamContext) error {
+			if s.ID != "sequence-1" || len(s.UnknownAttrs) != 1 {
+				t.Error("sequence attributes not streamed")
+			}
+			names = append(names, strings.Join(ctx.Bins, "/")+" "+string(s.Name)+" "+ctx.Path)
+			return nil
+		},
+		File: func(f *File, ctx StreamContext) error {
+			names = append(names, strings.Join(ctx.Bins, "/")+" "+string(f.Name)+" "+ctx.Path)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal("project could not be streamed: " + err.Error())
+	}
+
+	want := []string{
+		"Dailies A001.mov xmeml/project/children/bin/children/clip/file",
+		"Dailies A001 xmeml/project/children/bin/children/clip",
+		"Cuts Cut 1 xmeml/project/children/bin[2]/children/sequence",
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("bin items not streamed: %q", names)
+	}
+}
+
+func TestStreamingNestedSequences(t *testing.T) {
+	xc := `<xmeml version="5">
+		<sequence>
+			<name>Cut 1</name>
+			<rate>
+				<timebase>25</timebase>
+			</rate>
+			<media>
+				<video>
+					<track>
+						<clipitem id="clipitem-1">
+							<name>Reel 1</name>
+							<sequence id="sequence-2">
+								<name>Reel 1</name>
+								<media>
+									<video>
+										<track>
+											<clipitem id="clipitem-2">
+												<name>A001</name>
+											</clipitem>
+											<transitionitem>
+												<start>40</start>
+												<end>60</end>
+											</transitionitem>
+											<clipitem id="clipitem-3">
+												<name>A002</name>
+											</clipitem>
+										</track>
+									</video>
+								</media>
+							</sequence>
+						</clipitem>
+						<clipitem id="clipitem-4">
+							<name>A003</name>
+						</clipitem>
+					</track>
+				</video>
+			</media>
+		</sequence>
+	</xmeml>`
+
+	var items []string
+	err := StreamRawXEML(strings.NewReader(xc), StreamHandler{
+		ClipItem: func(ci *ClipItem, ctx StreamContext) error {
+			items = append(items, fmt.Sprintf("%s %d %s %s", ci.ID, ctx.Nested, ctx.Sequence.Name, ctx.Path))
+			return nil
+		},
+		TransitionItem: func(ti *TransitionItem, ctx StreamContext) error {
+			items = append(items, fmt.Sprintf("transition %d %s %s", ctx.Nested, ctx.Sequence.Name, ctx.Path))
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal("sequence could not be streamed: " + err.Error())
+	}
+
+	nested := "xmeml/sequence/media/video/track/clipitem/sequence/media/video/track"
+	want := []string{
+		"clipitem-2 1 Reel 1 " + nested + "/clipitem",
+		"transition 1 Reel 1 " + nested + "/transitionitem",
+		"clipitem-3 1 Reel 1 " + nested + "/clipitem[2]",
+		"clipitem-1 0 Cut 1 xmeml/sequence/media/video/track/clipitem",
+		"clipitem-4 0 Cut 1 xmeml/sequence/media/video/track/clipitem[2]",
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("nested track items not streamed: %q", items)
+	}
+}
+
+func TestStreamingInBoundedMemory(t *testing.T) {
+	const n = 30000
+
+	// the document is written as it is read, so that it is never held in memory
+	pr, pw := io.Pipe()
+	go func() {
+		fmt.Fprint(pw, `<xmeml version="5"><sequence><name>Long</name><rate><timebase>25</timebase></rate><media><video><track>`)
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(pw, `<clipitem id="clipitem-%d"><name>Shot %d</name><start>%d</start><end>%d</end>`+
+				`<in>0</in><out>10</out><file id="file-%d"><name>Shot %d.mov</name></file></clipitem>`, i+1, i, i*10, i*10+10, i+1, i)
+		}
+		fmt.Fprint(pw, `</track></video></media></sequence></xmeml>`)
+		pw.Close()
+	}()
+
+	var peak uint64
+	var m runtime.MemStats
+	count := 0
+	err := StreamRawXEML(pr, StreamHandler{
+		ClipItem: func(ci *ClipItem, ctx StreamContext) error {
+			count++
+			if count%(n/10) == 0 {
+				runtime.GC()
+				runtime.ReadMemStats(&m)
+				if m.HeapAlloc > peak {
+					peak = m.HeapAlloc
+				}
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal("document could not be streamed: " + err.Error())
+	}
+
+	// the document is about 5 MB
+	if count != n || peak > 4<<20 {
+		t.Errorf("document not streamed in bounded memory: %d clip items, %d bytes", count, peak)
+	}
+}
+
+func TestStreamingErrors(t *testing.T) {
+	stop := errors.New("stop")
+	calls := 0
+	s := mustRead(t, "export-examples/premier-export.xml")
+	err := StreamRawXEML(bytes.NewReader(s), StreamHandler{
+		ClipItem: func(ci *ClipItem, ctx StreamContext) error {
+			calls++
+			return stop
+		},
+	})
+	if err != stop || calls != 1 {
+		t.Error("streaming did not stop at a callback error")
+	}
+
+	xc := `<xmeml version="5"><sequence><media><video><track><clipitem><start>zero</start></clipitem></track></video></media></sequence></xmeml>`
+	err = StreamRawXEML(strings.NewReader(xc), StreamHandler{})
+	ie, ok := err.(*ImportError)
+	if !ok || ie.Kind != ImportTypeMismatch || ie.Path != "xmeml/sequence/media/video/track/clipitem/start" {
+		t.Errorf("streaming problem not reported: %v", err)
+	}
+
+	warnings, err := StreamRawXEMLLenient(strings.NewReader(xc), StreamHandler{})
+	if err != nil || len(warnings) != 1 {
+		t.Error("lenient streaming did not carry on past a problem")
+	}
+
+	if err := StreamRawXEML(strings.NewReader(`<xmeml version="5"><sequence>`), StreamHandler{}); err == nil {
+		t.Error("truncated document not reported")
+	}
+
+	if err := StreamRawXEML(strings.NewReader(`<fcpxml/>`), StreamHandler{}); err == nil {
+		t.Error("foreign document not reported")
+	}
+}
+
+func mustRead(t *testing.T, path string) []byte {
+	s, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal("example export could not be read: " + err.Error())
+	}
+
+	return s
+}
@@ -0,0 +1,254 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	timeCodeDropFrame    = "DF"
+	timeCodeNonDropFrame = "NDF"
+)
+
+// Section: Rate Arithmetic
+
+// FrameRate returns the exact frame rate of a rate as a fraction of frames per second, e.g. 24000/1001 for an
+// NTSC rate with a time base of 24.
+func (r Rate) FrameRate() (num int64, den int64) {
+	if r.NTSC {
+		return int64(r.TimeBase) * 1000, 1001
+	}
+
+	return int64(r.TimeBase), 1
+}
+
+// FrameDuration returns the exact duration of one frame as a fraction of a second, e.g. 1001/24000.
+func (r Rate) FrameDuration() (num int64, den int64) {
+	num, den = r.FrameRate()
+
+	return den, num
+}
+
+// FPS returns the frame rate of a rate in frames per second, e.g. 23.976...
+func (r Rate) FPS() float64 {
+	num, den := r.FrameRate()
+
+	return float64(num) / float64(den)
+}
+
+// Seconds converts a frame count to seconds.
+func (r Rate) Seconds(frames int) float64 {
+	num, den := r.FrameDuration()
+
+	return float64(frames) * float64(num) / float64(den)
+}
+
+// Frames converts seconds to the nearest frame count.
+func (r Rate) Frames(seconds float64) int {
+	return int(math.Round(seconds * r.FPS()))
+}
+
+// SupportsDropFrame reports whether drop frame timecode is defined for a rate, i.e. 29.97 and 59.94.
+func (r Rate) SupportsDropFrame() bool {
+	return bool(r.NTSC) && r.TimeBase > 0 && r.TimeBase%30 == 0
+}
+
+// Equal reports whether two rates describe the same time scale, ignoring unknown data.
+func (r Rate) Equal(o Rate) bool {
+	return r.TimeBase == o.TimeBase && r.NTSC == o.NTSC
+}
+
+// ConvertFrames converts a frame count from one rate to the nearest frame count of another rate, keeping the
+// same real time.
+func ConvertFrames(frames int, from Rate, to Rate) int {
+	fromNum, fromDen := from.FrameRate()
+	toNum, toDen := to.FrameRate()
+	if fromNum == 0 || toDen == 0 {
+		return 0
+	}
+
+	return roundDiv(int64(frames)*toNum*fromDen, toDen*fromNum)
+}
+
+// roundDiv divides a by b, rounding halves away from zero.
+func roundDiv(a int64, b int64) int {
+	if b < 0 {
+		a, b = -a, -b
+	}
+
+	if a < 0 {
+		return -int((-a + b/2) / b)
+	}
+
+	return int((a + b/2) / b)
+}
+
+// Section: Timecode Arithmetic
+
+// FormatTimeCode converts a frame count to a timecode string at a rate. Drop frame timecode is written with a
+// semicolon before the frames, and is only used for rates that support it. Frame counts outside of a day wrap
+// around.
+func FormatTimeCode(frames int, r Rate, dropFrame bool) string {
+	tb := r.TimeBase
+	if tb <= 0 {
+		return ""
+	}
+
+	dropFrame = dropFrame && r.SupportsDropFrame()
+
+	day := tb * 60 * 60 * 24
+	if dropFrame {
+		day -= 24 * 6 * 9 * dropFrames(r)
+	}
+	frames %= day
+	if frames < 0 {
+		frames += day
+	}
+
+	sep := ":"
+	if dropFrame {
+		sep = ";"
+		frames = addDroppedFrames(frames, r)
+	}
+
+	ff := frames % tb
+	ss := frames / tb % 60
+	mm := frames / (tb * 60) % 60
+	hh := frames / (tb * 60 * 60)
+
+	width := len(strconv.Itoa(tb - 1))
+	if width < 2 {
+		width = 2
+	}
+
+	return fmt.Sprintf("%02d:%02d:%02d%s%0*d", hh, mm, ss, sep, width, ff)
+}
+
+// ParseTimeCode converts a timecode string to a frame count at a rate. Timecode with a semicolon, comma or period
+// before the frames is read as drop frame.
+func ParseTimeCode(s string, r Rate) (int, error) {
+	s = strings.TrimSpace(s)
+	i := strings.LastIndexAny(s, ":;,.")
+	if i < 0 {
+		return 0, fmt.Errorf("timecode %q has no frames", s)
+	}
+
+	return parseTimeCode(s, r, s[i] != ':')
+}
+
+func parseTimeCode(s string, r Rate, dropFrame bool) (int, error) {
+	tb := r.TimeBase
+	if tb <= 0 {
+		return 0, errors.New("timecode rate has no time base")
+	}
+
+	if dropFrame && !r.SupportsDropFrame() {
+		return 0, fmt.Errorf("drop frame timecode %q is not supported at %d fps", s, tb)
+	}
+
+	parts := strings.FieldsFunc(strings.TrimSpace(s), func(c rune) bool {
+		return c == ':' || c == ';' || c == ',' || c == '.'
+	})
+	if len(parts) != 4 {
+		return 0, fmt.Errorf("timecode %q is not formatted as hh:mm:ss:ff", s)
+	}
+
+	var v [4]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("timecode %q is not formatted as hh:mm:ss:ff", s)
+		}
+		v[i] = n
+	}
+
+	hh, mm, ss, ff := v[0], v[1], v[2], v[3]
+	if mm > 59 || ss > 59 || ff >= tb {
+		return 0, fmt.Errorf("timecode %q is out of range at %d fps", s, tb)
+	}
+
+	frames := ((hh*60+mm)*60+ss)*tb + ff
+	if !dropFrame {
+		return frames, nil
+	}
+
+	drop := dropFrames(r)
+	if ss == 0 && mm%10 != 0 && ff < drop {
+		return 0, fmt.Errorf("timecode %q is dropped in drop frame timecode", s)
+	}
+
+	minutes := hh*60 + mm
+
+	return frames - drop*(minutes-minutes/10), nil
+}
+
+// dropFrames returns the number of frame numbers dropped every minute, except every tenth minute.
+func dropFrames(r Rate) int {
+	return r.TimeBase / 15
+}
+
+// addDroppedFrames converts a frame count to the frame number it is labelled with in drop frame timecode.
+func addDroppedFrames(frames int, r Rate) int {
+	drop := dropFrames(r)
+	perMinute := r.TimeBase*60 - drop
+	perTenMinutes := r.TimeBase*60*10 - drop*9
+
+	tens := frames / perTenMinutes
+	rem := frames % perTenMinutes
+
+	frames += drop * 9 * tens
+	if rem > drop {
+		frames += drop * ((rem - drop) / perMinute)
+	}
+
+	return frames
+}
+
+// Section: Timecode Elements
+
+// DropFrame reports whether a timecode is displayed as drop frame.
+func (tc TimeCode) DropFrame() bool {
+	return strings.EqualFold(string(tc.DisplayFormat), timeCodeDropFrame)
+}
+
+// Frames returns the frame count of a timecode, from its string when set and from its frame otherwise.
+func (tc TimeCode) Frames() (int, error) {
+	if tc.TimeCodeString == "" {
+		return int(tc.Frame), nil
+	}
+
+	if tc.Rate == nil {
+		return 0, errors.New("timecode has no rate")
+	}
+
+	if tc.DisplayFormat == "" {
+		return ParseTimeCode(string(tc.TimeCodeString), *tc.Rate)
+	}
+
+	return parseTimeCode(string(tc.TimeCodeString), *tc.Rate, tc.DropFrame())
+}
+
+// SetFrames sets both the frame and the string of a timecode from a frame count, respecting its display format.
+func (tc *TimeCode) SetFrames(frames int) {
+	tc.Frame = frame(frames)
+	if tc.Rate != nil {
+		tc.TimeCodeString = timeCodeString(FormatTimeCode(frames, *tc.Rate, tc.DropFrame()))
+	}
+}
+
+// FormatOffset formats a frame offset from a timecode, e.g. the record timecode of a frame in a sequence.
+func (tc TimeCode) FormatOffset(offset int) string {
+	if tc.Rate == nil {
+		return ""
+	}
+
+	start, err := tc.Frames()
+	if err != nil {
+		start = int(tc.Frame)
+	}
+
+	return FormatTimeCode(start+offset, *tc.Rate, tc.DropFrame())
+}
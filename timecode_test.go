@@ -0,0 +1,112 @@
+package converter
+
+import "testing"
+
+func TestFrameRates(t *testing.T) {
+	num, den := Rate{TimeBase: 24, NTSC: true}.FrameRate()
+	if num != 24000 || den != 1001 {
+		t.Errorf("NTSC frame rate does not match expectations: %d/%d", num, den)
+	}
+
+	num, den = Rate{TimeBase: 25}.FrameDuration()
+	if num != 1 || den != 25 {
+		t.Errorf("PAL frame duration does not match expectations: %d/%d", num, den)
+	}
+
+	if s := (Rate{TimeBase: 30, NTSC: true}).Seconds(30000); s != 1001 {
+		t.Errorf("frames not converted to seconds: %f", s)
+	}
+
+	if f := (Rate{TimeBase: 24, NTSC: true}).Frames(1001); f != 24000 {
+		t.Errorf("seconds not converted to frames: %d", f)
+	}
+}
+
+func TestConvertingFramesBetweenRates(t *testing.T) {
+	if f := ConvertFrames(24000, Rate{TimeBase: 24, NTSC: true}, Rate{TimeBase: 30, NTSC: true}); f != 30000 {
+		t.Errorf("frames not converted between NTSC rates: %d", f)
+	}
+
+	if f := ConvertFrames(24, Rate{TimeBase: 24, NTSC: true}, Rate{TimeBase: 25}); f != 25 {
+		t.Errorf("frames not rounded between rates: %d", f)
+	}
+
+	if f := ConvertFrames(-48, Rate{TimeBase: 24}, Rate{TimeBase: 48}); f != -96 {
+		t.Errorf("negative frames not converted: %d", f)
+	}
+}
+
+func TestNonDropFrameTimeCode(t *testing.T) {
+	r := Rate{TimeBase: 24, NTSC: true}
+
+	if tc := FormatTimeCode(86400+23, r, false); tc != "01:00:00:23" {
+		t.Error("non drop frame timecode not formatted: " + tc)
+	}
+
+	if tc := FormatTimeCode(100, r, true); tc != "00:00:04:04" {
+		t.Error("drop frame requested for a rate without drop frame: " + tc)
+	}
+
+	f, err := ParseTimeCode("01:00:00:23", r)
+	if err != nil || f != 86423 {
+		t.Errorf("non drop frame timecode not parsed: %d %v", f, err)
+	}
+
+	if _, err := ParseTimeCode("00:00:00:24", r); err == nil {
+		t.Error("out of range frames not rejected")
+	}
+}
+
+func TestDropFrameTimeCode(t *testing.T) {
+	r := Rate{TimeBase: 30, NTSC: true}
+
+	cases := map[int]string{
+		0:      "00:00:00;00",
+		1799:   "00:00:59;29",
+		1800:   "00:01:00;02",
+		17982:  "00:10:00;00",
+		107892: "01:00:00;00",
+	}
+
+	for f, s := range cases {
+		if tc := FormatTimeCode(f, r, true); tc != s {
+			t.Errorf("drop frame timecode of %d does not match expectations: %s", f, tc)
+		}
+
+		if pf, err := ParseTimeCode(s, r); err != nil || pf != f {
+			t.Errorf("drop frame timecode %s not parsed: %d %v", s, pf, err)
+		}
+	}
+
+	if _, err := ParseTimeCode("00:01:00;00", r); err == nil {
+		t.Error("dropped frame number not rejected")
+	}
+
+	if tc := FormatTimeCode(3600, Rate{TimeBase: 60, NTSC: true}, true); tc != "00:01:00;04" {
+		t.Error("59.94 drop frame timecode not formatted: " + tc)
+	}
+}
+
+func TestTimeCodeElementFrames(t *testing.T) {
+	tc := TimeCode{
+		TimeCodeString: "01:00:00;00",
+		DisplayFormat:  "DF",
+		Rate:           &Rate{TimeBase: 30, NTSC: true},
+	}
+
+	f, err := tc.Frames()
+	if err != nil || f != 107892 {
+		t.Errorf("timecode element frames not parsed: %d %v", f, err)
+	}
+
+	if s := tc.FormatOffset(1800); s != "01:01:00;02" {
+		t.Error("timecode offset not formatted: " + s)
+	}
+
+	tc.DisplayFormat = "NDF"
+	tc.SetFrames(108000)
+
+	if tc.TimeCodeString != "01:00:00:00" || tc.Frame != 108000 {
+		t.Error("timecode element frames not set: " + string(tc.TimeCodeString))
+	}
+}
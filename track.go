@@ -0,0 +1,274 @@
+package converter
+
+import "sort"
+
+// trackEdit describes the record range of a clip item once transitions have been taken into account.
+type trackEdit struct {
+	item  *ClipItem
+	start int
+	end   int
+	in    *trackTransition
+	out   *trackTransition
+}
+
+// trackGenerator describes the record range of a generator item once transitions have been taken into account.
+type trackGenerator struct {
+	item  *GeneratorItem
+	start int
+	end   int
+}
+
+// trackTransition describes a transition, the edit point it is aligned to and the items on either side of it. An
+// item on either side is a clip item or a generator item, never both.
+type trackTransition struct {
+	item          *TransitionItem
+	start         int
+	end           int
+	cut           int
+	from          *trackEdit
+	to            *trackEdit
+	fromGenerator *trackGenerator
+	toGenerator   *trackGenerator
+}
+
+// Items lists the clip items, generator items and transition items of a track, each a *ClipItem, *GeneratorItem or
+// *TransitionItem, in the order they were read. Items added after the track was read, or to a track that was not
+// read, are placed in record order ahead of the first read item starting after them. A transition comes before an
+// item starting at the same frame, as a fade in from black starts at the start of its clip.
+func (t *Track) Items() []interface{} {
+	kept, added := keepOrder(t.order, t.ClipItems, t.GeneratorItems, t.TransitionItems)
+	sort.SliceStable(added, func(i, j int) bool {
+		return placedBefore(added[i], added[j])
+	})
+
+	items := make([]interface{}, 0, len(kept)+len(added))
+	for _, k := range kept {
+		for len(added) > 0 && itemStart(k) >= 0 && placedBefore(added[0], k) {
+			items = append(items, added[0])
+			added = added[1:]
+		}
+		items = append(items, k)
+	}
+
+	return append(items, added...)
+}
+
+func (t *Track) items() []interface{} {
+	return t.Items()
+}
+
+// placedBefore reports whether a track item is placed ahead of another in record order.
+func placedBefore(a, b interface{}) bool {
+	sa, sb := itemStart(a), itemStart(b)
+	if sa != sb {
+		return sa < sb
+	}
+	_, ta := a.(*TransitionItem)
+	_, tb := b.(*TransitionItem)
+
+	return ta && !tb
+}
+
+// itemStart returns the record frame a track item starts at, taken from its end when its start is -1. It is -1 when
+// neither is known.
+func itemStart(item interface{}) int {
+	switch it := item.(type) {
+	case *ClipItem:
+		if it.Start < 0 && it.End >= 0 {
+			return int(it.End) - (int(it.Out) - int(it.In))
+		}
+		return int(it.Start)
+	case *GeneratorItem:
+		if it.Start < 0 && it.End >= 0 {
+			return int(it.End) - (int(it.Out) - int(it.In))
+		}
+		return int(it.Start)
+	case *TransitionItem:
+		return int(it.Start)
+	}
+
+	return -1
+}
+
+// editTrack resolves the edit point of every transition of a track, and the record range of clips that start or
+// end in a transition, which are written with a start or end of -1. Both are returned in record order.
+func editTrack(t *Track) ([]*trackEdit, []*trackTransition) {
+	edits, _, trs := layTrack(t)
+
+	return edits, trs
+}
+
+// layTrack resolves a track the way editTrack does, along with the record range of its generator items. The items
+// are taken in the order of Items: a start of -1 is the edit point of the transition ahead of the item, and an end
+// of -1 the edit point of the transition after it, and the items on either side of a transition are its neighbours
+// in that order.
+func layTrack(t *Track) ([]*trackEdit, []*trackGenerator, []*trackTransition) {
+	var edits []*trackEdit
+	var gens []*trackGenerator
+	var trs []*trackTransition
+
+	// placed holds the resolved item of every entry of Items, a *trackEdit, *trackGenerator or *trackTransition
+	items := t.Items()
+	placed := make([]interface{}, len(items))
+	for i, item := range items {
+		if ti, ok := item.(*TransitionItem); ok {
+			tr := &trackTransition{item: ti, start: int(ti.Start), end: int(ti.End)}
+			switch ti.Alignment {
+			case alignmentStart, alignmentStartBlack:
+				tr.cut = tr.start
+			case alignmentEnd, alignmentEndBlack:
+				tr.cut = tr.end
+			default:
+				tr.cut = (tr.start + tr.end) / 2
+			}
+			trs = append(trs, tr)
+			placed[i] = tr
+		}
+	}
+
+	// cutAt returns the edit point of the transition at an index of Items, if there is one
+	cutAt := func(i int) (int, bool) {
+		if i < 0 || i >= len(placed) {
+			return 0, false
+		}
+		if tr, ok := placed[i].(*trackTransition); ok {
+			return tr.cut, true
+		}
+
+		return 0, false
+	}
+
+	// lay resolves the record range of the item at an index of Items
+	lay := func(i int, s, e, length int) (int, int) {
+		if s < 0 {
+			if cut, ok := cutAt(i - 1); ok {
+				s = cut
+			}
+		}
+		if e < 0 {
+			if cut, ok := cutAt(i + 1); ok {
+				e = cut
+			}
+		}
+		switch {
+		case s < 0 && e >= 0:
+			s = e - length
+		case e < 0 && s >= 0:
+			e = s + length
+		}
+
+		return s, e
+	}
+
+	for i, item := range items {
+		switch it := item.(type) {
+		case *ClipItem:
+			c := &trackEdit{item: it}
+			c.start, c.end = lay(i, int(it.Start), int(it.End), int(it.Out)-int(it.In))
+			edits = append(edits, c)
+			placed[i] = c
+		case *GeneratorItem:
+			g := &trackGenerator{item: it}
+			g.start, g.end = lay(i, int(it.Start), int(it.End), int(it.Out)-int(it.In))
+			gens = append(gens, g)
+			placed[i] = g
+		}
+	}
+
+	for i, p := range placed {
+		tr, ok := p.(*trackTransition)
+		if !ok {
+			continue
+		}
+
+		if i > 0 && tr.item.Alignment != alignmentStartBlack {
+			switch prev := placed[i-1].(type) {
+			case *trackEdit:
+				if prev.end == tr.cut && prev.out == nil {
+					tr.from, prev.out = prev, tr
+				}
+			case *trackGenerator:
+				if prev.end == tr.cut {
+					tr.fromGenerator = prev
+				}
+			}
+		}
+		if i+1 < len(placed) && tr.item.Alignment != alignmentEndBlack {
+			switch next := placed[i+1].(type) {
+			case *trackEdit:
+				if next.start == tr.cut && next.in == nil {
+					tr.to, next.in = next, tr
+				}
+			case *trackGenerator:
+				if next.start == tr.cut {
+					tr.toGenerator = next
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].start < edits[j].start
+	})
+	sort.SliceStable(gens, func(i, j int) bool {
+		return gens[i].start < gens[j].start
+	})
+	sort.SliceStable(trs, func(i, j int) bool {
+		return trs[i].start < trs[j].start
+	})
+
+	return edits, gens, trs
+}
+
+// TransitionOverlap describes the frames a transition borrows from the clip items on either side of its edit point.
+type TransitionOverlap struct {
+	Transition *TransitionItem
+	// Cut is the record frame of the edit point the transition is aligned to.
+	Cut int
+	// From is the outgoing clip item, nil when the transition starts from black or from a generator item.
+	From *ClipItem
+	// To is the incoming clip item, nil when the transition ends in black or in a generator item.
+	To *ClipItem
+	// FromGenerator is the outgoing generator item, e.g. a slug or colour matte, nil when there is none.
+	FromGenerator *GeneratorItem
+	// ToGenerator is the incoming generator item, nil when there is none.
+	ToGenerator *GeneratorItem
+	// FromFrames is the number of frames the outgoing item plays after the edit point, past its out point.
+	FromFrames int
+	// ToFrames is the number of frames the incoming item plays before the edit point, ahead of its in point.
+	ToFrames int
+}
+
+// TransitionOverlaps lists the transitions of a track in record order, along with the frames they borrow from the
+// clip or generator items on either side of their edit points. A transition with neither on a side starts from or
+// ends in black.
+func (t *Track) TransitionOverlaps() []*TransitionOverlap {
+	_, trs := editTrack(t)
+
+	var overlaps []*TransitionOverlap
+	for _, tr := range trs {
+		o := &TransitionOverlap{Transition: tr.item, Cut: tr.cut}
+		if tr.from != nil {
+			o.From = tr.from.item
+		}
+		if tr.fromGenerator != nil {
+			o.FromGenerator = tr.fromGenerator.item
+		}
+		if o.From != nil || o.FromGenerator != nil {
+			o.FromFrames = tr.end - tr.cut
+		}
+
+		if tr.to != nil {
+			o.To = tr.to.item
+		}
+		if tr.toGenerator != nil {
+			o.ToGenerator = tr.toGenerator.item
+		}
+		if o.To != nil || o.ToGenerator != nil {
+			o.ToFrames = tr.cut - tr.start
+		}
+		overlaps = append(overlaps, o)
+	}
+
+	return overlaps
+}
@@ -0,0 +1,283 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+)
+
+const trackItemsXML = `<xmeml version="5">
+	<sequence>
+		<name>Cut</name>
+		<duration>150</duration>
+		<rate>
+			<timebase>25</timebase>
+		</rate>
+		<media>
+			<video>
+				<track>
+					<transitionitem>
+						<start>0</start>
+						<end>10</end>
+						<alignment>start-black</alignment>
+						<effect>
+							<name>Cross Dissolve</name>
+							<effectid>Cross Dissolve</effectid>
+							<effecttype>transition</effecttype>
+							<mediatype>video</mediatype>
+						</effect>
+					</transitionitem>
+					<clipitem id="clipitem-1">
+						<name>Shot 1</name>
+						<start>0</start>
+						<end>-1</end>
+						<in>100</in>
+						<out>150</out>
+					</clipitem>
+					<transitionitem>
+						<start>40</start>
+						<end>60</end>
+						<alignment>center</alignment>
+						<effect>
+							<name>Edge Wipe</name>
+							<effectid>Edge Wipe</effectid>
+							<effectcategory>Wipe</effectcategory>
+							<effecttype>transition</effecttype>
+							<mediatype>video</mediatype>
+							<wipecode>1</wipecode>
+							<wipeaccuracy>100</wipeaccuracy>
+							<startratio>0.25</startratio>
+							<endratio>0.75</endratio>
+							<reverse>TRUE</reverse>
+						</effect>
+					</transitionitem>
+					<clipitem id="clipitem-2">
+						<name>Shot 2</name>
+						<start>-1</start>
+						<end>100</end>
+						<in>0</in>
+						<out>50</out>
+					</clipitem>
+					<generatoritem id="slug-1">
+						<name>Slug</name>
+						<duration>3600</duration>
+						<rate>
+							<timebase>25</timebase>
+						</rate>
+						<in>0</in>
+						<out>50</out>
+						<start>100</start>
+						<end>150</end>
+						<enabled>TRUE</enabled>
+						<effect>
+							<name>Slug</name>
+							<effectid>slug</effectid>
+							<effecttype>generator</effecttype>
+							<mediatype>video</mediatype>
+						</effect>
+					</generatoritem>
+					<enabled>TRUE</enabled>
+				</track>
+			</video>
+		</media>
+	</sequence>
+</xmeml>`
+
+func TestImportingTrackItems(t *testing.T) {
+	x := ImportRawXEML([]byte(trackItemsXML))
+	track := x.Sequence.Media.Video.Tracks[0]
+
+	if len(track.ClipItems) != 2 || len(track.TransitionItems) != 2 || len(track.GeneratorItems) != 1 {
+		t.Fatal("track items not imported")
+	}
+
+	if g := track.GeneratorItems[0]; g.ID != "slug-1" || g.Start != 100 || g.Effect == nil || g.Effect.EffectID != "slug" {
+		t.Error("generator item not imported")
+	}
+
+	wipe := track.TransitionItems[1]
+	if wipe.Alignment != alignmentCenter || wipe.Effect.WipeCode != 1 || wipe.Effect.WipeAccuracy != 100 {
+		t.Error("transition item not imported")
+	}
+
+	if wipe.Effect.StartRatio != 0.25 || wipe.Effect.EndRatio != 0.75 || !wipe.Effect.Reverse {
+		t.Error("transition ratios not imported")
+	}
+}
+
+func TestWritingTrackItemsInOrder(t *testing.T) {
+	x := ImportRawXEML([]byte(trackItemsXML))
+
+	b, err := xml.Marshal(x.Sequence.Media.Video.Tracks[0])
+	if err != nil {
+		t.Fatal("track could not be marshalled: " + err.Error())
+	}
+
+	d := xml.NewDecoder(bytes.NewReader(b))
+	var names []string
+	depth := 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			break
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if depth == 1 {
+				names = append(names, tok.Name.Local)
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+	}
+
+	want := []string{"transitionitem", "clipitem", "transitionitem", "clipitem", "generatoritem", "enabled"}
+	if len(names) != len(want) {
+		t.Fatalf("track children do not match expectations: %v", names)
+	}
+	for i, n := range want {
+		if names[i] != n {
+			t.Errorf("track child %d is a %s, not a %s", i, names[i], n)
+		}
+	}
+}
+
+func TestTransitionOverlaps(t *testing.T) {
+	x := ImportRawXEML([]byte(trackItemsXML))
+	track := x.Sequence.Media.Video.Tracks[0]
+
+	overlaps := track.TransitionOverlaps()
+	if len(overlaps) != 2 {
+		t.Fatalf("transition overlaps do not match expectations: %d", len(overlaps))
+	}
+
+	fade := overlaps[0]
+	if fade.From != nil || fade.To != track.ClipItems[0] || fade.Cut != 0 || fade.FromFrames != 0 || fade.ToFrames != 0 {
+		t.Error("fade from black does not match expectations")
+	}
+
+	wipe := overlaps[1]
+	if wipe.From != track.ClipItems[0] || wipe.To != track.ClipItems[1] || wipe.Cut != 50 {
+		t.Error("wipe neighbours do not match expectations")
+	}
+
+	if wipe.FromFrames != 10 || wipe.ToFrames != 10 {
+		t.Errorf("wipe overlap does not match expectations: %d %d", wipe.FromFrames, wipe.ToFrames)
+	}
+}
+
+const equalClipsXML = `<track>
+	<clipitem id="clipitem-1">
+		<name>A</name>
+		<start>0</start>
+		<end>-1</end>
+		<in>0</in>
+		<out>10</out>
+	</clipitem>
+	<transitionitem>
+		<start>5</start>
+		<end>15</end>
+		<alignment>center</alignment>
+	</transitionitem>
+	<clipitem id="clipitem-2">
+		<name>B</name>
+		<start>-1</start>
+		<end>-1</end>
+		<in>0</in>
+		<out>20</out>
+	</clipitem>
+	<transitionitem>
+		<start>25</start>
+		<end>35</end>
+		<alignment>center</alignment>
+	</transitionitem>
+	<clipitem id="clipitem-3">
+		<name>C</name>
+		<start>-1</start>
+		<end>-1</end>
+		<in>0</in>
+		<out>20</out>
+	</clipitem>
+	<transitionitem>
+		<start>45</start>
+		<end>55</end>
+		<alignment>center</alignment>
+	</transitionitem>
+	<generatoritem id="slug-1">
+		<name>Slug</name>
+		<start>-1</start>
+		<end>60</end>
+		<in>0</in>
+		<out>10</out>
+	</generatoritem>
+</track>`
+
+func TestEditingEqualClipsBetweenTransitions(t *testing.T) {
+	var track Track
+	if err := xml.Unmarshal([]byte(equalClipsXML), &track); err != nil {
+		t.Fatal("track could not be unmarshalled: " + err.Error())
+	}
+
+	edits, gens, trs := layTrack(&track)
+	want := [][2]int{{0, 10}, {10, 30}, {30, 50}}
+	if len(edits) != len(want) {
+		t.Fatalf("edits do not match expectations: %d", len(edits))
+	}
+	for i, w := range want {
+		if c := edits[i]; c.item != track.ClipItems[i] || c.start != w[0] || c.end != w[1] {
+			t.Errorf("clip %s edited at %d-%d, not %d-%d", c.item.Name, c.start, c.end, w[0], w[1])
+		}
+	}
+
+	if len(gens) != 1 || gens[0].start != 50 || gens[0].end != 60 {
+		t.Error("generator not placed at the edit point of the transition ahead of it")
+	}
+
+	if len(trs) != 3 || trs[1].from != edits[1] || trs[1].to != edits[2] {
+		t.Error("transition neighbours not taken in document order")
+	}
+
+	if trs[2].from != edits[2] || trs[2].to != nil || trs[2].toGenerator != gens[0] {
+		t.Error("generator not taken as the incoming item of a transition")
+	}
+
+	overlaps := track.TransitionOverlaps()
+	if o := overlaps[2]; o.To != nil || o.ToGenerator != track.GeneratorItems[0] || o.ToFrames != 5 {
+		t.Error("transition to a generator reported as a fade to black")
+	}
+
+	x := RawXEML{Sequence: &Sequence{Rate: &Rate{TimeBase: 25}, Media: &Media{Video: &Video{Tracks: []*Track{&track}}}}}
+	for _, f := range Validate(x, ValidationOptions{}) {
+		if f.Rule == "track-overlap" || f.Rule == "transition-neighbours" {
+			t.Error("track edited by document order reported: " + f.String())
+		}
+	}
+
+	b := marshalElement(t, track, "track")
+	var again Track
+	if err := xml.Unmarshal([]byte(b), &again); err != nil {
+		t.Fatal("track could not be unmarshalled again: " + err.Error())
+	}
+	items := again.Items()
+	if len(items) != 7 || items[2].(*ClipItem).Name != "B" || items[4].(*ClipItem).Name != "C" {
+		t.Error("track items not written in document order: " + b)
+	}
+}
+
+func TestPlacingAddedTrackItems(t *testing.T) {
+	var track Track
+	if err := xml.Unmarshal([]byte(equalClipsXML), &track); err != nil {
+		t.Fatal("track could not be unmarshalled: " + err.Error())
+	}
+
+	d := &ClipItem{Name: "D", Start: 60, End: 70, In: 0, Out: 10}
+	track.ClipItems = append(track.ClipItems, d)
+	fade := &TransitionItem{Start: 0, End: 5, Alignment: alignmentStartBlack}
+	track.TransitionItems = append([]*TransitionItem{fade}, track.TransitionItems...)
+
+	items := track.Items()
+	if len(items) != 9 || items[0] != fade || items[1] != track.ClipItems[0] || items[8] != d {
+		t.Error("added track items not placed in record order")
+	}
+}
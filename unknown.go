@@ -0,0 +1,97 @@
+package converter
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// Extensions describes the attributes and child elements of an element that the raw model does not know, e.g. the
+// TL.SQ* attributes and pproTicksIn elements written by Premiere. Unknown attributes are written back after the known
+// attributes. Unknown elements are written back in the place they were read, among the known children, so that
+// unknown data survives an import and marshal.
+type Extensions struct {
+	UnknownAttrs    []xml.Attr        `xml:",any,attr"`
+	UnknownElements []*UnknownElement `xml:",any"`
+	// children names the child elements in the order they were read, known or not. It is nil when the element was
+	// not read, and empty when it was read without children.
+	children []layoutChild
+}
+
+// layoutChild describes a child element as it was read.
+type layoutChild struct {
+	name string
+	// empty is set for a simple value read without any content, e.g. <good/>
+	empty bool
+}
+
+func (e *Extensions) extensions() *Extensions {
+	return e
+}
+
+// UnknownElement describes an element that the raw model does not know, along with its attributes and content.
+type UnknownElement struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr
+	Content []xml.Token
+}
+
+// UnmarshalXML keeps the content of an unknown element as tokens. Whitespace between its children is dropped, as
+// it is indentation rather than content.
+func (u *UnknownElement) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	u.XMLName = start.Name
+	u.Attrs = start.Attr
+	u.Content = nil
+
+	hasChildren := false
+	for depth := 0; ; {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch tok.(type) {
+		case xml.StartElement:
+			depth++
+			hasChildren = true
+		case xml.EndElement:
+			if depth == 0 {
+				if hasChildren {
+					u.Content = trimIndentation(u.Content)
+				}
+
+				return nil
+			}
+			depth--
+		}
+		u.Content = append(u.Content, xml.CopyToken(tok))
+	}
+}
+
+// MarshalXML writes an unknown element as it was read.
+func (u *UnknownElement) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: u.XMLName, Attr: u.Attrs}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for _, tok := range u.Content {
+		if err := e.EncodeToken(tok); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// trimIndentation drops character data that only holds whitespace.
+func trimIndentation(content []xml.Token) []xml.Token {
+	var trimmed []xml.Token
+	for _, tok := range content {
+		if cd, ok := tok.(xml.CharData); ok && strings.TrimSpace(string(cd)) == "" {
+			continue
+		}
+		trimmed = append(trimmed, tok)
+	}
+
+	return trimmed
+}
@@ -0,0 +1,202 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestKeepingUnknownData(t *testing.T) {
+	xc := `<xmeml version="4">
+		<sequence id="sequence-1" TL.SQHeaderWidth="292" MZ.EditLine="0">
+			<name>Cut</name>
+			<duration>10</duration>
+			<rate>
+				<timebase>25</timebase>
+			</rate>
+			<media>
+				<video>
+					<track TL.SQTrackShy="0">
+						<clipitem id="clipitem-1">
+							<name>Shot</name>
+							<start>0</start>
+							<end>10</end>
+							<pproTicksIn>0</pproTicksIn>
+							<colorinfo>
+								<lut>Rec709</lut>
+								<asc_sat />
+							</colorinfo>
+							<pproTicksOut>2224862640000</pproTicksOut>
+						</clipitem>
+					</track>
+				</video>
+			</media>
+		</sequence>
+	</xmeml>`
+
+	x := ImportRawXEML([]byte(xc))
+
+	s := x.Sequence
+	if len(s.UnknownAttrs) != 2 || s.UnknownAttrs[0].Name.Local != "TL.SQHeaderWidth" || s.UnknownAttrs[1].Value != "0" {
+		t.Error("unknown sequence attributes not kept in order")
+	}
+
+	track := s.Media.Video.Tracks[0]
+	if len(track.UnknownAttrs) != 1 || track.UnknownAttrs[0].Name.Local != "TL.SQTrackShy" {
+		t.Error("unknown track attributes not kept")
+	}
+
+	unknown := track.ClipItems[0].UnknownElements
+	if len(unknown) != 3 {
+		t.Fatalf("unknown clip item elements not kept: %d", len(unknown))
+	}
+
+	names := []string{"pproTicksIn", "colorinfo", "pproTicksOut"}
+	for i, n := range names {
+		if unknown[i].XMLName.Local != n {
+			t.Errorf("unknown element %d is %s, not %s", i, unknown[i].XMLName.Local, n)
+		}
+	}
+
+	b, err := xml.Marshal(x)
+	if err != nil {
+		t.Fatal("raw XEML could not be marshalled: " + err.Error())
+	}
+
+	want := `<colorinfo><lut>Rec709</lut><asc_sat></asc_sat></colorinfo>`
+	if !bytes.Contains(b, []byte(want)) {
+		t.Error("unknown element not written back: " + string(b))
+	}
+
+	if !bytes.Contains(b, []byte(`<sequence id="sequence-1" TL.SQHeaderWidth="292" MZ.EditLine="0">`)) {
+		t.Error("unknown attributes not written back: " + string(b))
+	}
+}
+
+func TestKeepingUnknownDataFromExports(t *testing.T) {
+	for _, path := range []string{"export-examples/premier-export.xml", "export-examples/resolve-export.xml"} {
+		s, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal("example export could not be read: " + err.Error())
+		}
+
+		x := ImportRawXEML(s)
+
+		b, err := xml.MarshalIndent(x, "", "\t")
+		if err != nil {
+			t.Fatal("raw XEML could not be marshalled: " + err.Error())
+		}
+
+		y, err := ParseRawXEML(b)
+		if err != nil {
+			t.Fatal("marshalled raw XEML could not be imported: " + err.Error())
+		}
+
+		if !reflect.DeepEqual(x, y) {
+			t.Errorf("%s not preserved by a marshal", path)
+		}
+	}
+
+	s, _ := ioutil.ReadFile("export-examples/premier-export.xml")
+	b, _ := xml.Marshal(ImportRawXEML(s))
+	for _, want := range []string{`TL.SQAudioVisibleBase="0"`, `<pproTicksIn>0</pproTicksIn>`, `<numOutputChannels>2</numOutputChannels>`, `<originalvideofilename></originalvideofilename>`} {
+		if !bytes.Contains(b, []byte(want)) {
+			t.Errorf("%s not written back", want)
+		}
+	}
+}
+
+// xmlNode describes an element for comparing documents. Character data next to child elements is left out, as it is
+// indentation, or stray text the model drops.
+type xmlNode struct {
+	name     string
+	attrs    []xml.Attr
+	text     string
+	children []*xmlNode
+}
+
+func readXMLNode(t *testing.T, b []byte) *xmlNode {
+	d := xml.NewDecoder(bytes.NewReader(b))
+	var stack []*xmlNode
+	var root *xmlNode
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return root
+		}
+		if err != nil {
+			t.Fatal("document could not be read: " + err.Error())
+		}
+
+		switch tt := tok.(type) {
+		case xml.StartElement:
+			n := &xmlNode{name: tt.Name.Local, attrs: tt.Attr}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(tt)
+			}
+		case xml.EndElement:
+			n := stack[len(stack)-1]
+			if len(n.children) > 0 {
+				n.text = ""
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+// compareXMLNodes returns the path of the first element that differs between two documents, or an empty string.
+func compareXMLNodes(want *xmlNode, got *xmlNode, path string) string {
+	if want.name != got.name || want.text != got.text || !reflect.DeepEqual(want.attrs, got.attrs) {
+		return fmt.Sprintf("%s: want <%s %v>%q, got <%s %v>%q", path, want.name, want.attrs, want.text, got.name,
+			got.attrs, got.text)
+	}
+
+	for i, c := range want.children {
+		if i >= len(got.children) {
+			return fmt.Sprintf("%s: child <%s> missing", path, c.name)
+		}
+		if d := compareXMLNodes(c, got.children[i], fmt.Sprintf("%s/%s[%d]", path, c.name, i)); d != "" {
+			return d
+		}
+	}
+
+	if len(got.children) > len(want.children) {
+		return fmt.Sprintf("%s: child <%s> added", path, got.children[len(want.children)].name)
+	}
+
+	return ""
+}
+
+func TestWritingExportsElementByElement(t *testing.T) {
+	exports := map[string]BoolCase{
+		"export-examples/premier-export.xml": BoolUpper,
+		"export-examples/resolve-export.xml": BoolLower,
+	}
+	for path, bc := range exports {
+		s, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal("example export could not be read: " + err.Error())
+		}
+
+		var b bytes.Buffer
+		if err := WriteXEML(&b, ImportRawXEML(s), XEMLOptions{Indent: "\t", BoolCase: bc}); err != nil {
+			t.Fatal("raw XEML could not be written: " + err.Error())
+		}
+
+		if d := compareXMLNodes(readXMLNode(t, s), readXMLNode(t, b.Bytes()), "xmeml"); d != "" {
+			t.Errorf("%s not written back as it was read: %s", path, d)
+		}
+	}
+}
@@ -0,0 +1,535 @@
+package converter
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Severity ranks how serious a validation finding is.
+type Severity int
+
+const (
+	// SeverityInfo describes something worth knowing that does not need fixing.
+	SeverityInfo Severity = iota
+	// SeverityWarning describes something that is likely to be interpreted differently by different applications.
+	SeverityWarning
+	// SeverityError describes something that is broken and cannot be conformed reliably.
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+
+	return "unknown"
+}
+
+// Finding describes a problem found by a validation rule, along with where it was found.
+type Finding struct {
+	Rule     string
+	Severity Severity
+	// Path is the element path of the problem, e.g. xmeml/sequence/media/video/track[2]/clipitem[14]
+	Path    string
+	Message string
+}
+
+func (f *Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s: %s", f.Path, f.Severity, f.Rule, f.Message)
+}
+
+// Findings describes every problem found while validating a document.
+type Findings []*Finding
+
+// Max returns the most serious severity of findings, or -1 when there are none.
+func (fs Findings) Max() Severity {
+	max := Severity(-1)
+	for _, f := range fs {
+		if f.Severity > max {
+			max = f.Severity
+		}
+	}
+
+	return max
+}
+
+// Rule describes a named validation check and the severity of its findings.
+type Rule struct {
+	Name        string
+	Description string
+	Severity    Severity
+	check       func(v *validator)
+}
+
+var validationRules = []Rule{
+	{
+		Name:        "sequence-rate",
+		Description: "every sequence outside a clip item has a rate",
+		Severity:    SeverityError,
+		check:       checkSequenceRate,
+	},
+	{
+		Name:        "rate-timebase",
+		Description: "every rate has a time base between 1 and 120",
+		Severity:    SeverityError,
+		check:       checkRateTimeBase,
+	},
+	{
+		Name:        "rate-common",
+		Description: "every rate is a common frame rate, and NTSC only applies to time bases that have an NTSC rate",
+		Severity:    SeverityWarning,
+		check:       checkRateCommon,
+	},
+	{
+		Name:        "enum-value",
+		Description: "enumerated values, e.g. alphatype and displayformat, are legal",
+		Severity:    SeverityError,
+		check:       checkEnumValues,
+	},
+	{
+		Name:        "enum-extension",
+		Description: "compositemode and pixelaspectratio values, which other applications extend, are known to Final Cut Pro 7",
+		Severity:    SeverityWarning,
+		check:       checkEnumExtensions,
+	},
+	{
+		Name:        "clip-duration",
+		Description: "clip and generator items play as many frames as their source range holds",
+		Severity:    SeverityError,
+		check:       checkClipDuration,
+	},
+	{
+		Name:        "track-overlap",
+		Description: "clip and generator items on a track do not overlap",
+		Severity:    SeverityError,
+		check:       checkTrackOverlap,
+	},
+	{
+		Name:        "transition-neighbours",
+		Description: "transitions that are not aligned to black have a clip item on either side of their edit point",
+		Severity:    SeverityWarning,
+		check:       checkTransitionNeighbours,
+	},
+	{
+		Name:        "link-target",
+		Description: "links point at tracks and clip items that exist",
+		Severity:    SeverityError,
+		check:       checkLinkTargets,
+	},
+	{
+		Name:        "file-reference",
+		Description: "every file id that is referenced is defined",
+		Severity:    SeverityError,
+		check:       checkFileReferences,
+	},
+	{
+		Name:        "file-conflict",
+		Description: "file ids are not redefined with different values",
+		Severity:    SeverityWarning,
+		check:       checkFileConflicts,
+	},
+	{
+		Name:        "marker-range",
+		Description: "markers end after they start",
+		Severity:    SeverityWarning,
+		check:       checkMarkerRanges,
+	},
+}
+
+// ValidationRules lists the rule catalogue of Validate in the order rules run.
+func ValidationRules() []Rule {
+	return append([]Rule{}, validationRules...)
+}
+
+// ValidationOptions describes which rules Validate runs and how serious their findings are.
+type ValidationOptions struct {
+	// Disabled names the rules that are not run.
+	Disabled []string
+	// Severities overrides the severity of rules by name.
+	Severities map[string]Severity
+}
+
+// Validate checks the structure of a raw XEML data tree against every enabled rule. Findings are returned in
+// catalogue order, and in document order for each rule.
+func Validate(x RawXEML, o ValidationOptions) Findings {
+	disabled := map[string]bool{}
+	for _, name := range o.Disabled {
+		disabled[name] = true
+	}
+
+	v := &validator{x: &x}
+	for _, r := range validationRules {
+		if disabled[r.Name] {
+			continue
+		}
+
+		v.rule = r.Name
+		v.severity = r.Severity
+		if s, ok := o.Severities[r.Name]; ok {
+			v.severity = s
+		}
+		r.check(v)
+	}
+
+	return v.findings
+}
+
+type validator struct {
+	x        *RawXEML
+	rule     string
+	severity Severity
+	findings Findings
+}
+
+func (v *validator) report(path string, format string, a ...interface{}) {
+	v.findings = append(v.findings, &Finding{
+		Rule:     v.rule,
+		Severity: v.severity,
+		Path:     path,
+		Message:  fmt.Sprintf(format, a...),
+	})
+}
+
+// sequences calls fn for every sequence of a document, including sequences nested in clip items, along with the
+// rate its frames are counted in.
+func (v *validator) sequences(fn func(s *Sequence, rate *Rate, path string, nested bool)) {
+	var walk func(s *Sequence, parent *Rate, path string, nested bool)
+	walk = func(s *Sequence, parent *Rate, path string, nested bool) {
+		rate := parent
+		if s.Rate != nil && s.Rate.TimeBase > 0 {
+			rate = s.Rate
+		}
+		fn(s, rate, path, nested)
+
+		v.tracks(s, path, func(t *Track, media string, path string) {
+			for i, ci := range t.ClipItems {
+				if ci.Sequence != nil {
+					itemRate := rate
+					if ci.Rate != nil && ci.Rate.TimeBase > 0 {
+						itemRate = ci.Rate
+					}
+					walk(ci.Sequence, itemRate, childPath(childPath(path, "clipitem", i), "sequence", 0), true)
+				}
+			}
+		})
+	}
+
+	_ = v.x.Walk(func(item BinItem) error {
+		if item.Sequence != nil {
+			walk(item.Sequence, nil, item.Path, false)
+		}
+
+		return nil
+	})
+}
+
+// tracks calls fn for every track of a sequence, video tracks first.
+func (v *validator) tracks(s *Sequence, path string, fn func(t *Track, media string, path string)) {
+	if s.Media == nil {
+		return
+	}
+
+	mediaPath := childPath(path, "media", 0)
+	if s.Media.Video != nil {
+		for i, t := range s.Media.Video.Tracks {
+			fn(t, "video", childPath(childPath(mediaPath, "video", 0), "track", i))
+		}
+	}
+	if s.Media.Audio != nil {
+		for i, t := range s.Media.Audio.Tracks {
+			fn(t, "audio", childPath(childPath(mediaPath, "audio", 0), "track", i))
+		}
+	}
+}
+
+// allTracks calls fn for every track of every sequence of a document, along with the rate of its sequence.
+func (v *validator) allTracks(fn func(s *Sequence, rate *Rate, t *Track, media string, path string)) {
+	v.sequences(func(s *Sequence, rate *Rate, path string, nested bool) {
+		v.tracks(s, path, func(t *Track, media string, path string) {
+			fn(s, rate, t, media, path)
+		})
+	})
+}
+
+// elements calls fn for every element and attribute of a document that is part of the model, along with its path.
+func (v *validator) elements(fn func(e reflect.Value, path string)) {
+	walkElements(reflect.ValueOf(v.x).Elem(), "xmeml", fn)
+}
+
+func walkElements(e reflect.Value, path string, fn func(e reflect.Value, path string)) {
+	for e.Kind() == reflect.Ptr {
+		if e.IsNil() {
+			return
+		}
+		e = e.Elem()
+	}
+
+	fn(e, path)
+	if e.Kind() != reflect.Struct {
+		return
+	}
+
+	t := e.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("xml")
+		if f.PkgPath != "" || f.Anonymous || f.Name == "XMLName" || tag == "-" || strings.Contains(tag, ",any") ||
+			strings.Contains(tag, ",chardata") || strings.Contains(tag, ",innerxml") {
+			continue
+		}
+
+		tagName := strings.Split(tag, ",")[0]
+		if tagName == "" {
+			tagName = f.Name
+		}
+
+		fv := e.Field(i)
+		if strings.Contains(tag, ",attr") {
+			fn(fv, path+"@"+tagName)
+			continue
+		}
+
+		if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+			for j := 0; j < fv.Len(); j++ {
+				walkElements(fv.Index(j), childPath(path, tagName, j), fn)
+			}
+			continue
+		}
+
+		walkElements(fv, childPath(path, tagName, 0), fn)
+	}
+}
+
+// Section: Rules
+
+func checkSequenceRate(v *validator) {
+	v.sequences(func(s *Sequence, rate *Rate, path string, nested bool) {
+		if !nested && rate == nil {
+			v.report(path, "sequence %q has no rate", s.Name)
+		}
+	})
+}
+
+func checkRateTimeBase(v *validator) {
+	v.elements(func(e reflect.Value, path string) {
+		r, ok := e.Interface().(Rate)
+		if ok && (r.TimeBase <= 0 || r.TimeBase > 120) {
+			v.report(childPath(path, "timebase", 0), "time base %d is not between 1 and 120", r.TimeBase)
+		}
+	})
+}
+
+var commonTimeBases = map[int]bool{24: true, 25: true, 30: true, 48: true, 50: true, 60: true, 100: true, 120: true}
+
+func checkRateCommon(v *validator) {
+	v.elements(func(e reflect.Value, path string) {
+		r, ok := e.Interface().(Rate)
+		if !ok || r.TimeBase <= 0 || r.TimeBase > 120 {
+			return
+		}
+
+		switch {
+		case !commonTimeBases[r.TimeBase]:
+			v.report(childPath(path, "timebase", 0), "time base %d is not a common frame rate", r.TimeBase)
+		case bool(r.NTSC) && r.TimeBase%24 != 0 && r.TimeBase%30 != 0:
+			v.report(childPath(path, "ntsc", 0), "time base %d has no NTSC rate", r.TimeBase)
+		}
+	})
+}
+
+func checkEnumValues(v *validator) {
+	checkEnums(v, false)
+}
+
+func checkEnumExtensions(v *validator) {
+	checkEnums(v, true)
+}
+
+// checkEnums reports the values of enumerations, either open or not, that are not listed, see openEnum.
+func checkEnums(v *validator, open bool) {
+	v.elements(func(e reflect.Value, path string) {
+		en, ok := e.Interface().(enum)
+		if !ok || e.Kind() != reflect.String || e.String() == "" {
+			return
+		}
+		if _, isOpen := en.(openEnum); isOpen != open {
+			return
+		}
+
+		for _, ev := range en.enumValues() {
+			if e.String() == ev {
+				return
+			}
+		}
+
+		v.report(path, "%q is not one of %s", e.String(), strings.Join(en.enumValues(), ", "))
+	})
+}
+
+// hasTimeRemap reports whether a clip item changes the speed of its source, so that its record and source ranges
+// differ in length.
+func hasTimeRemap(filters []*Filter) bool {
+	for _, f := range filters {
+		if f.Effect != nil && strings.EqualFold(string(f.Effect.EffectID), "timeremap") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func checkClipDuration(v *validator) {
+	check := func(start start, end end, in in, out out, itemRate *Rate, seqRate *Rate, path string) {
+		if start < 0 || end < 0 || int(out) <= int(in) {
+			return
+		}
+
+		length := int(out) - int(in)
+		if itemRate != nil && seqRate != nil && itemRate.TimeBase > 0 {
+			length = ConvertFrames(length, *itemRate, *seqRate)
+		}
+		if int(end)-int(start) != length {
+			v.report(path, "plays %d frames from a source range of %d frames", int(end)-int(start), length)
+		}
+	}
+
+	v.allTracks(func(s *Sequence, rate *Rate, t *Track, media string, path string) {
+		for i, ci := range t.ClipItems {
+			if !hasTimeRemap(ci.Filters) {
+				check(ci.Start, ci.End, ci.In, ci.Out, ci.Rate, rate, childPath(path, "clipitem", i))
+			}
+		}
+		for i, g := range t.GeneratorItems {
+			check(g.Start, g.End, g.In, g.Out, g.Rate, rate, childPath(path, "generatoritem", i))
+		}
+	})
+}
+
+func checkTrackOverlap(v *validator) {
+	type placed struct {
+		start int
+		end   int
+		path  string
+	}
+
+	v.allTracks(func(s *Sequence, rate *Rate, t *Track, media string, path string) {
+		index := map[*ClipItem]int{}
+		for i, ci := range t.ClipItems {
+			index[ci] = i
+		}
+		generators := map[*GeneratorItem]int{}
+		for i, g := range t.GeneratorItems {
+			generators[g] = i
+		}
+
+		var items []placed
+		edits, gens, _ := layTrack(t)
+		for _, c := range edits {
+			if c.start >= 0 && c.end >= 0 {
+				items = append(items, placed{c.start, c.end, childPath(path, "clipitem", index[c.item])})
+			}
+		}
+		for _, g := range gens {
+			if g.start >= 0 && g.end >= 0 {
+				items = append(items, placed{g.start, g.end, childPath(path, "generatoritem", generators[g.item])})
+			}
+		}
+
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].start < items[j].start
+		})
+
+		for i := 1; i < len(items); i++ {
+			if prev := items[i-1]; prev.end > items[i].start {
+				v.report(items[i].path, "overlaps %s by %d frames", prev.path, prev.end-items[i].start)
+			}
+		}
+	})
+}
+
+func checkTransitionNeighbours(v *validator) {
+	v.allTracks(func(s *Sequence, rate *Rate, t *Track, media string, path string) {
+		index := map[*TransitionItem]int{}
+		for i, ti := range t.TransitionItems {
+			index[ti] = i
+		}
+
+		_, trs := editTrack(t)
+		for _, tr := range trs {
+			itemPath := childPath(path, "transitionitem", index[tr.item])
+			if tr.from == nil && tr.fromGenerator == nil && tr.item.Alignment != alignmentStartBlack {
+				v.report(itemPath, "no clip or generator item ends at its edit point at frame %d", tr.cut)
+			}
+			if tr.to == nil && tr.toGenerator == nil && tr.item.Alignment != alignmentEndBlack {
+				v.report(itemPath, "no clip or generator item starts at its edit point at frame %d", tr.cut)
+			}
+		}
+	})
+}
+
+func checkLinkTargets(v *validator) {
+	v.sequences(func(s *Sequence, rate *Rate, path string, nested bool) {
+		idx := newLinkIndex(s)
+		tracks := idx.tracks
+
+		v.tracks(s, path, func(t *Track, media string, path string) {
+			for i, ci := range t.ClipItems {
+				for j, l := range ci.Links {
+					linkPath := childPath(childPath(path, "clipitem", i), "link", j)
+					if l.LinkClipRef != "" && idx.ids[string(l.LinkClipRef)] == nil {
+						v.report(childPath(linkPath, "linkclipref", 0), "there is no clip item %q", l.LinkClipRef)
+					}
+
+					if l.TrackIndex == 0 && l.ClipIndex == 0 {
+						// Resolve links by clip reference only
+						continue
+					}
+
+					linkMedia := string(l.MediaType)
+					if linkMedia == "" {
+						linkMedia = media
+					}
+
+					ts := tracks[linkMedia]
+					if l.TrackIndex < 1 || int(l.TrackIndex) > len(ts) {
+						v.report(childPath(linkPath, "trackindex", 0), "there is no %s track %d", linkMedia, l.TrackIndex)
+						continue
+					}
+
+					items := ts[l.TrackIndex-1].ClipItems
+					if l.ClipIndex < 1 || int(l.ClipIndex) > len(items) {
+						v.report(childPath(linkPath, "clipindex", 0), "%s track %d has no clip item %d", linkMedia, l.TrackIndex, l.ClipIndex)
+					}
+				}
+			}
+		})
+	})
+}
+
+func checkFileReferences(v *validator) {
+	for _, o := range v.x.MediaRegistry().Undefined {
+		v.report(o.Path, "file id %q is never defined", o.File.ID)
+	}
+}
+
+func checkFileConflicts(v *validator) {
+	for _, c := range v.x.MediaRegistry().Conflicts {
+		v.report(c.Path, "file id %q is redefined with different values", c.ID)
+	}
+}
+
+func checkMarkerRanges(v *validator) {
+	v.elements(func(e reflect.Value, path string) {
+		m, ok := e.Interface().(Marker)
+		if ok && m.Out >= 0 && int(m.Out) < int(m.In) {
+			v.report(path, "ends at %d before it starts at %d", m.Out, m.In)
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package converter
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+const invalidXML = `<xmeml version="5">
+	<sequence>
+		<name>Cut</name>
+		<duration>100</duration>
+		<rate>
+			<timebase>25</timebase>
+		</rate>
+		<media>
+			<video>
+				<track>
+					<clipitem id="clipitem-1">
+						<name>Shot 1</name>
+						<start>0</start>
+						<end>50</end>
+						<in>0</in>
+						<out>50</out>
+						<file id="file-1"/>
+						<link>
+							<linkclipref>clipitem-1</linkclipref>
+							<mediatype>video</mediatype>
+							<trackindex>3</trackindex>
+							<clipindex>1</clipindex>
+						</link>
+					</clipitem>
+					<clipitem id="clipitem-2">
+						<name>Shot 2</name>
+						<rate>
+							<timebase>0</timebase>
+						</rate>
+						<start>40</start>
+						<end>100</end>
+						<in>0</in>
+						<out>50</out>
+						<marker>
+							<name>Backwards</name>
+							<in>20</in>
+							<out>10</out>
+						</marker>
+					</clipitem>
+				</track>
+			</video>
+		</media>
+	</sequence>
+</xmeml>`
+
+func TestValidatingInvalidStructure(t *testing.T) {
+	x := ImportRawXEML([]byte(invalidXML))
+	x.Sequence.Media.Video.Tracks[0].ClipItems[0].CompositeMode = "glow"
+
+	findings := Validate(x, ValidationOptions{})
+
+	want := []Finding{
+		{"rate-timebase", SeverityError, "xmeml/sequence/media/video/track/clipitem[2]/rate/timebase", ""},
+		{"enum-extension", SeverityWarning, "xmeml/sequence/media/video/track/clipitem/compositemode", ""},
+		{"clip-duration", SeverityError, "xmeml/sequence/media/video/track/clipitem[2]", ""},
+		{"track-overlap", SeverityError, "xmeml/sequence/media/video/track/clipitem[2]", ""},
+		{"link-target", SeverityError, "xmeml/sequence/media/video/track/clipitem/link/trackindex", ""},
+		{"file-reference", SeverityError, "xmeml/sequence/media/video/track/clipitem/file", ""},
+		{"marker-range", SeverityWarning, "xmeml/sequence/media/video/track/clipitem[2]/marker", ""},
+	}
+
+	if len(findings) != len(want) {
+		for _, f := range findings {
+			t.Log(f)
+		}
+		t.Fatalf("findings do not match expectations: %d", len(findings))
+	}
+
+	for i, w := range want {
+		f := findings[i]
+		if f.Rule != w.Rule || f.Severity != w.Severity || f.Path != w.Path {
+			t.Errorf("finding %d does not match expectations: %s", i, f)
+		}
+	}
+
+	if findings.Max() != SeverityError {
+		t.Error("most serious finding not an error")
+	}
+}
+
+func TestSwitchingValidationRules(t *testing.T) {
+	x := ImportRawXEML([]byte(invalidXML))
+
+	findings := Validate(x, ValidationOptions{
+		Disabled:   []string{"rate-timebase", "clip-duration", "link-target", "file-reference", "marker-range"},
+		Severities: map[string]Severity{"track-overlap": SeverityInfo},
+	})
+
+	if len(findings) != 1 || findings[0].Rule != "track-overlap" || findings[0].Severity != SeverityInfo {
+		t.Fatal("rules not switched by the validation options")
+	}
+
+	if findings.Max() != SeverityInfo || (Findings{}).Max() >= SeverityInfo {
+		t.Error("most serious finding does not match expectations")
+	}
+
+	for _, r := range ValidationRules() {
+		if r.Name == "" || r.Description == "" {
+			t.Error("rule catalogue entry not described")
+		}
+	}
+}
+
+func TestValidatingExports(t *testing.T) {
+	for _, path := range []string{"export-examples/premier-export.xml", "export-examples/resolve-export.xml"} {
+		s, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal("example export could not be read: " + err.Error())
+		}
+
+		for _, f := range Validate(ImportRawXEML(s), ValidationOptions{}) {
+			t.Errorf("%s: %s", path, f)
+		}
+	}
+}
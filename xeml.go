@@ -0,0 +1,532 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// XEML describes XEML data after validation and inheritance has taken place. Values missing from an element are
+// inherited from its parent, and references between elements are resolved.
+type XEML struct {
+	Version  int
+	Project  *XEMLBin
+	Bin      *XEMLBin
+	Clip     *XEMLClip
+	Sequence *XEMLSequence
+}
+
+// XEMLBin describes a resolved project or bin and the clips, sequences and bins it contains.
+type XEMLBin struct {
+	Name      string
+	Clips     []*XEMLClip
+	Sequences []*XEMLSequence
+	Bins      []*XEMLBin
+	// Items holds every *XEMLClip, *XEMLSequence and *XEMLBin in document order, see Children.Items.
+	Items []interface{}
+}
+
+// XEMLRate describes a resolved time scale.
+type XEMLRate struct {
+	TimeBase int
+	NTSC     bool
+}
+
+// XEMLTimeCode describes a resolved timecode.
+type XEMLTimeCode struct {
+	String        string
+	Frame         int
+	DisplayFormat string
+	Rate          XEMLRate
+}
+
+// XEMLSequence describes a resolved sequence.
+type XEMLSequence struct {
+	ID       string
+	Name     string
+	Duration int
+	Rate     XEMLRate
+	In       int
+	Out      int
+	TimeCode *XEMLTimeCode
+	Video    []*XEMLTrack
+	Audio    []*XEMLTrack
+	Markers  []*XEMLMarker
+	Raw      *Sequence
+}
+
+// XEMLTrack describes a resolved video or audio track.
+type XEMLTrack struct {
+	Enabled         bool
+	Locked          bool
+	ClipItems       []*XEMLClipItem
+	GeneratorItems  []*XEMLGeneratorItem
+	TransitionItems []*XEMLTransitionItem
+	// Items holds every *XEMLClipItem, *XEMLGeneratorItem and *XEMLTransitionItem in document order, see Track.Items.
+	Items []interface{}
+	Raw   *Track
+}
+
+// XEMLClipItem describes a resolved clip in a track. A clip item nesting a sequence by id shares the XEMLSequence of
+// its definition.
+type XEMLClipItem struct {
+	ID           string
+	Name         string
+	Duration     int
+	Rate         XEMLRate
+	In           int
+	Out          int
+	Start        int
+	End          int
+	Enabled      bool
+	MasterClipID string
+	MasterClip   *XEMLClip
+	File         *XEMLFile
+	TimeCode     *XEMLTimeCode
+	Markers      []*XEMLMarker
+	Sequence     *XEMLSequence
+	Raw          *ClipItem
+}
+
+// XEMLGeneratorItem describes a resolved generator in a track.
+type XEMLGeneratorItem struct {
+	ID       string
+	Name     string
+	Duration int
+	Rate     XEMLRate
+	In       int
+	Out      int
+	Start    int
+	End      int
+	Enabled  bool
+	EffectID string
+	Raw      *GeneratorItem
+}
+
+// XEMLTransitionItem describes a resolved transition between items in a track.
+type XEMLTransitionItem struct {
+	Rate      XEMLRate
+	Start     int
+	End       int
+	Alignment string
+	EffectID  string
+	Raw       *TransitionItem
+}
+
+// XEMLClip describes a resolved clip in the Browser.
+type XEMLClip struct {
+	ID           string
+	Name         string
+	Duration     int
+	Rate         XEMLRate
+	In           int
+	Out          int
+	MasterClipID string
+	IsMasterClip bool
+	Enabled      bool
+	File         *XEMLFile
+	Markers      []*XEMLMarker
+	Raw          *Clip
+}
+
+// XEMLFile describes a resolved media file. Every reference to the same file id shares one XEMLFile.
+type XEMLFile struct {
+	ID       string
+	Name     string
+	PathURL  string
+	Duration int
+	Rate     XEMLRate
+	TimeCode *XEMLTimeCode
+	Raw      *File
+}
+
+// XEMLMarker describes a resolved marker.
+type XEMLMarker struct {
+	Name    string
+	Comment string
+	In      int
+	Out     int
+}
+
+// PackageError describes an element that could not be resolved while packaging.
+type PackageError struct {
+	Path string
+	Err  error
+}
+
+func (e *PackageError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+// PackageErrors describes every element that could not be resolved while packaging.
+type PackageErrors []*PackageError
+
+func (e PackageErrors) Error() string {
+	s := make([]string, len(e))
+	for i, pe := range e {
+		s[i] = pe.Error()
+	}
+
+	return strings.Join(s, "\n")
+}
+
+// PackageXEML packages a raw XEML data tree into resolved XEML. The XEML is returned even when some references
+// cannot be resolved; every unresolved reference is reported in the returned PackageErrors.
+func PackageXEML(r RawXEML) (*XEML, error) {
+	p := &packager{
+		media:       r.MediaRegistry(),
+		files:       map[string]*XEMLFile{},
+		masterClips: map[string]*XEMLClip{},
+		sequences:   map[string]*XEMLSequence{},
+	}
+
+	x := &XEML{Version: r.Version}
+
+	if r.Project != nil {
+		x.Project = p.bin(string(r.Project.Name), r.Project.Children, "xmeml/project")
+	}
+
+	if r.Bin != nil {
+		x.Bin = p.bin(string(r.Bin.Name), r.Bin.Children, "xmeml/bin")
+	}
+
+	if r.Clip != nil {
+		x.Clip = p.clip(r.Clip, "xmeml/clip")
+	}
+
+	if r.Sequence != nil {
+		x.Sequence = p.sequence(r.Sequence, "xmeml/sequence", nil)
+	}
+
+	p.resolveMasterClips()
+	p.resolveSequences()
+
+	if len(p.errs) > 0 {
+		return x, p.errs
+	}
+
+	return x, nil
+}
+
+// childPath appends a child element to an element path. The index is that of the child amongst its siblings of
+// the same name, and is only written for repeated children.
+func childPath(parent string, name string, index int) string {
+	if index > 0 {
+		return fmt.Sprintf("%s/%s[%d]", parent, name, index+1)
+	}
+
+	return parent + "/" + name
+}
+
+type masterClipRef struct {
+	item *XEMLClipItem
+	path string
+}
+
+type sequenceRef struct {
+	item *XEMLClipItem
+	id   string
+	path string
+}
+
+type packager struct {
+	media        *MediaRegistry
+	files        map[string]*XEMLFile
+	masterClips  map[string]*XEMLClip
+	masterRefs   []masterClipRef
+	sequences    map[string]*XEMLSequence
+	sequenceRefs []sequenceRef
+	errs         PackageErrors
+}
+
+func (p *packager) fail(path string, format string, a ...interface{}) {
+	p.errs = append(p.errs, &PackageError{Path: path, Err: fmt.Errorf(format, a...)})
+}
+
+func (p *packager) rate(r *Rate, parent *XEMLRate, path string) XEMLRate {
+	if r != nil && r.TimeBase > 0 {
+		return XEMLRate{TimeBase: r.TimeBase, NTSC: bool(r.NTSC)}
+	}
+
+	if parent != nil {
+		return *parent
+	}
+
+	p.fail(childPath(path, "rate", 0), "no rate is set or inherited")
+
+	return XEMLRate{}
+}
+
+func (p *packager) timeCode(tc *TimeCode, parent XEMLRate, path string) *XEMLTimeCode {
+	if tc == nil {
+		return nil
+	}
+
+	return &XEMLTimeCode{
+		String:        string(tc.TimeCodeString),
+		Frame:         int(tc.Frame),
+		DisplayFormat: string(tc.DisplayFormat),
+		Rate:          p.rate(tc.Rate, &parent, childPath(path, "timecode", 0)),
+	}
+}
+
+func (p *packager) markers(ms []*Marker) []*XEMLMarker {
+	var xms []*XEMLMarker
+	for _, m := range ms {
+		xms = append(xms, &XEMLMarker{
+			Name:    string(m.Name),
+			Comment: string(m.Comment),
+			In:      int(m.In),
+			Out:     int(m.Out),
+		})
+	}
+
+	return xms
+}
+
+// file resolves a file occurrence to the shared XEMLFile of its id. Files without an id are never shared.
+func (p *packager) file(f *File, parent XEMLRate, path string) *XEMLFile {
+	if f == nil {
+		return nil
+	}
+
+	if f.ID != "" {
+		if xf, ok := p.files[f.ID]; ok {
+			return xf
+		}
+
+		if def := p.media.Canonical(f); def != nil {
+			f = def
+		} else {
+			p.fail(path, "file id %q is never defined", f.ID)
+		}
+	}
+
+	xf := &XEMLFile{
+		ID:       f.ID,
+		Name:     string(f.Name),
+		PathURL:  string(f.PathURL),
+		Duration: int(f.Duration),
+		Rate:     p.rate(f.Rate, &parent, path),
+		Raw:      f,
+	}
+	xf.TimeCode = p.timeCode(f.TimeCode, xf.Rate, path)
+
+	if f.ID != "" {
+		p.files[f.ID] = xf
+	}
+
+	return xf
+}
+
+// bin packages the clips, sequences and bins of a project or bin in document order.
+func (p *packager) bin(binName string, c *Children, path string) *XEMLBin {
+	xb := &XEMLBin{Name: binName}
+	if c == nil {
+		return xb
+	}
+
+	path = childPath(path, "children", 0)
+	for _, item := range c.Items() {
+		switch it := item.(type) {
+		case *Clip:
+			xc := p.clip(it, childPath(path, "clip", len(xb.Clips)))
+			xb.Clips = append(xb.Clips, xc)
+			xb.Items = append(xb.Items, xc)
+		case *Sequence:
+			xs := p.sequence(it, childPath(path, "sequence", len(xb.Sequences)), nil)
+			xb.Sequences = append(xb.Sequences, xs)
+			xb.Items = append(xb.Items, xs)
+		case *Bin:
+			b := p.bin(string(it.Name), it.Children, childPath(path, "bin", len(xb.Bins)))
+			xb.Bins = append(xb.Bins, b)
+			xb.Items = append(xb.Items, b)
+		}
+	}
+
+	return xb
+}
+
+func (p *packager) clip(c *Clip, path string) *XEMLClip {
+	xc := &XEMLClip{
+		ID:           c.ID,
+		Name:         string(c.Name),
+		Duration:     int(c.Duration),
+		Rate:         p.rate(c.Rate, nil, path),
+		In:           int(c.In),
+		Out:          int(c.Out),
+		MasterClipID: string(c.MasterClipID),
+		IsMasterClip: bool(c.IsMasterClip),
+		Enabled:      bool(c.Enabled),
+		Markers:      p.markers(c.Markers),
+		Raw:          c,
+	}
+	xc.File = p.file(c.File, xc.Rate, childPath(path, "file", 0))
+
+	if xc.MasterClipID != "" && (xc.IsMasterClip || p.masterClips[xc.MasterClipID] == nil) {
+		p.masterClips[xc.MasterClipID] = xc
+	}
+
+	return xc
+}
+
+func (p *packager) sequence(s *Sequence, path string, parent *XEMLRate) *XEMLSequence {
+	xs := &XEMLSequence{
+		ID:       s.ID,
+		Name:     string(s.Name),
+		Duration: int(s.Duration),
+		Rate:     p.rate(s.Rate, parent, path),
+		In:       int(s.In),
+		Out:      int(s.Out),
+		Markers:  p.markers(s.Markers),
+		Raw:      s,
+	}
+	xs.TimeCode = p.timeCode(s.TimeCode, xs.Rate, path)
+
+	if s.Media == nil {
+		return xs
+	}
+	if _, ok := p.sequences[s.ID]; s.ID != "" && !ok {
+		p.sequences[s.ID] = xs
+	}
+
+	mediaPath := childPath(path, "media", 0)
+	if s.Media.Video != nil {
+		xs.Video = p.tracks(s.Media.Video.Tracks, xs.Rate, childPath(mediaPath, "video", 0))
+	}
+	if s.Media.Audio != nil {
+		xs.Audio = p.tracks(s.Media.Audio.Tracks, xs.Rate, childPath(mediaPath, "audio", 0))
+	}
+
+	return xs
+}
+
+func (p *packager) tracks(ts []*Track, parent XEMLRate, path string) []*XEMLTrack {
+	var xts []*XEMLTrack
+	for i, t := range ts {
+		trackPath := childPath(path, "track", i)
+		xt := &XEMLTrack{
+			Enabled: bool(t.Enabled),
+			Locked:  bool(t.Locked),
+			Raw:     t,
+		}
+
+		for _, item := range t.Items() {
+			switch it := item.(type) {
+			case *ClipItem:
+				xci := p.clipItem(it, parent, childPath(trackPath, "clipitem", len(xt.ClipItems)))
+				xt.ClipItems = append(xt.ClipItems, xci)
+				xt.Items = append(xt.Items, xci)
+			case *GeneratorItem:
+				xg := p.generatorItem(it, parent, childPath(trackPath, "generatoritem", len(xt.GeneratorItems)))
+				xt.GeneratorItems = append(xt.GeneratorItems, xg)
+				xt.Items = append(xt.Items, xg)
+			case *TransitionItem:
+				xtr := p.transitionItem(it, parent, childPath(trackPath, "transitionitem", len(xt.TransitionItems)))
+				xt.TransitionItems = append(xt.TransitionItems, xtr)
+				xt.Items = append(xt.Items, xtr)
+			}
+		}
+
+		xts = append(xts, xt)
+	}
+
+	return xts
+}
+
+func (p *packager) clipItem(ci *ClipItem, parent XEMLRate, path string) *XEMLClipItem {
+	xci := &XEMLClipItem{
+		ID:           ci.ID,
+		Name:         string(ci.Name),
+		Duration:     int(ci.Duration),
+		Rate:         p.rate(ci.Rate, &parent, path),
+		In:           int(ci.In),
+		Out:          int(ci.Out),
+		Start:        int(ci.Start),
+		End:          int(ci.End),
+		Enabled:      bool(ci.Enabled),
+		MasterClipID: string(ci.MasterClipID),
+		Markers:      p.markers(ci.Markers),
+		Raw:          ci,
+	}
+	xci.File = p.file(ci.File, xci.Rate, childPath(path, "file", 0))
+
+	// a clip item without its own timecode takes the timecode of its source
+	xci.TimeCode = p.timeCode(ci.TimeCode, xci.Rate, path)
+	if xci.TimeCode == nil && xci.File != nil {
+		xci.TimeCode = xci.File.TimeCode
+	}
+
+	if ci.Sequence != nil {
+		sequencePath := childPath(path, "sequence", 0)
+		xci.Sequence = p.sequence(ci.Sequence, sequencePath, &xci.Rate)
+		if ci.Sequence.Media == nil && ci.Sequence.ID != "" {
+			p.sequenceRefs = append(p.sequenceRefs, sequenceRef{item: xci, id: ci.Sequence.ID, path: sequencePath})
+		}
+	}
+
+	if xci.MasterClipID != "" {
+		p.masterRefs = append(p.masterRefs, masterClipRef{item: xci, path: childPath(path, "masterclipid", 0)})
+	}
+
+	return xci
+}
+
+func (p *packager) generatorItem(g *GeneratorItem, parent XEMLRate, path string) *XEMLGeneratorItem {
+	xg := &XEMLGeneratorItem{
+		ID:       g.ID,
+		Name:     string(g.Name),
+		Duration: int(g.Duration),
+		Rate:     p.rate(g.Rate, &parent, path),
+		In:       int(g.In),
+		Out:      int(g.Out),
+		Start:    int(g.Start),
+		End:      int(g.End),
+		Enabled:  bool(g.Enabled),
+		Raw:      g,
+	}
+	if g.Effect != nil {
+		xg.EffectID = string(g.Effect.EffectID)
+	}
+
+	return xg
+}
+
+func (p *packager) transitionItem(ti *TransitionItem, parent XEMLRate, path string) *XEMLTransitionItem {
+	xt := &XEMLTransitionItem{
+		Rate:      p.rate(ti.Rate, &parent, path),
+		Start:     int(ti.Start),
+		End:       int(ti.End),
+		Alignment: string(ti.Alignment),
+		Raw:       ti,
+	}
+	if ti.Effect != nil {
+		xt.EffectID = string(ti.Effect.EffectID)
+	}
+
+	return xt
+}
+
+// resolveSequences links clip items nesting a sequence by id to the sequence defined with it, once every sequence
+// has been packaged.
+func (p *packager) resolveSequences() {
+	for _, ref := range p.sequenceRefs {
+		xs, ok := p.sequences[ref.id]
+		if !ok {
+			p.fail(ref.path, "sequence id %q is never defined", ref.id)
+			continue
+		}
+		ref.item.Sequence = xs
+	}
+}
+
+// resolveMasterClips links clip items to their master clips once every clip has been packaged.
+func (p *packager) resolveMasterClips() {
+	for _, ref := range p.masterRefs {
+		mc, ok := p.masterClips[ref.item.MasterClipID]
+		if !ok {
+			p.fail(ref.path, "master clip %q is never defined", ref.item.MasterClipID)
+			continue
+		}
+		ref.item.MasterClip = mc
+	}
+}
@@ -0,0 +1,237 @@
+package converter
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestPackagingResolvesFileReferences(t *testing.T) {
+	s, err := ioutil.ReadFile("export-examples/resolve-export.xml")
+	if err != nil {
+		t.Fatal("example export could not be read: " + err.Error())
+	}
+
+	x, err := PackageXEML(ImportRawXEML(s))
+	if err != nil {
+		t.Fatal("example export could not be packaged: " + err.Error())
+	}
+
+	video := x.Sequence.Video[0].ClipItems[0]
+	audio := x.Sequence.Audio[0].ClipItems[0]
+
+	if video.File == nil || video.File != audio.File {
+		t.Fatal("file reference not resolved to its definition")
+	}
+
+	if audio.File.Duration != 9 || audio.File.Name != "NAMI.mp4" {
+		t.Error("file definition not packaged")
+	}
+
+	if audio.TimeCode == nil || audio.TimeCode.String != "00:00:00:00" {
+		t.Error("clip item timecode not inherited from its file")
+	}
+}
+
+func TestPackagingInheritsRates(t *testing.T) {
+	xc := `<xmeml version="5">
+		<sequence>
+			<name>Inherited</name>
+			<rate>
+				<timebase>30</timebase>
+				<ntsc>TRUE</ntsc>
+			</rate>
+			<timecode>
+				<string>01:00:00;00</string>
+				<displayformat>DF</displayformat>
+			</timecode>
+			<media>
+				<video>
+					<track>
+						<clipitem id="clipitem-1">
+							<name>Shot</name>
+							<masterclipid>masterclip-1</masterclipid>
+							<file id="file-1">
+								<name>shot.mov</name>
+							</file>
+						</clipitem>
+					</track>
+				</video>
+			</media>
+		</sequence>
+		<clip>
+			<name>Shot</name>
+			<masterclipid>masterclip-1</masterclipid>
+			<ismasterclip>TRUE</ismasterclip>
+			<rate>
+				<timebase>25</timebase>
+			</rate>
+		</clip>
+	</xmeml>`
+
+	x, err := PackageXEML(ImportRawXEML([]byte(xc)))
+	if err != nil {
+		t.Fatal("sequence could not be packaged: " + err.Error())
+	}
+
+	if x.Sequence.TimeCode.Rate.TimeBase != 30 || !x.Sequence.TimeCode.Rate.NTSC {
+		t.Error("timecode rate not inherited from sequence")
+	}
+
+	ci := x.Sequence.Video[0].ClipItems[0]
+
+	if ci.Rate.TimeBase != 30 {
+		t.Error("clip item rate not inherited from sequence")
+	}
+
+	if ci.File.Rate.TimeBase != 30 {
+		t.Error("file rate not inherited from clip item")
+	}
+
+	if ci.MasterClip != x.Clip {
+		t.Error("master clip id not resolved to master clip")
+	}
+}
+
+func TestPackagingReportsUnresolvedReferences(t *testing.T) {
+	s, err := ioutil.ReadFile("export-examples/premier-export.xml")
+	if err != nil {
+		t.Fatal("example export could not be read: " + err.Error())
+	}
+
+	x, err := PackageXEML(ImportRawXEML(s))
+
+	errs, ok := err.(PackageErrors)
+	if !ok {
+		t.Fatal("unresolved master clips not reported")
+	}
+
+	if len(errs) != 3 {
+		t.Fatalf("unresolved references do not match expectations: %v", errs)
+	}
+
+	if errs[0].Path != "xmeml/sequence/media/video/track/clipitem/masterclipid" {
+		t.Error("unresolved reference path does not match expectations: " + errs[0].Path)
+	}
+
+	if x == nil || x.Sequence.Audio[1].ClipItems[0].File.PathURL == "" {
+		t.Error("packaged XEML not returned alongside unresolved references")
+	}
+}
+
+func TestPackagingInDocumentOrder(t *testing.T) {
+	xc := `<xmeml version="5">
+		<project>
+			<name>Order</name>
+			<children>
+				<sequence id="sequence-2">
+					<name>Nest</name>
+					<rate>
+						<timebase>25</timebase>
+					</rate>
+					<media>
+						<video>
+							<track>
+								<clipitem id="clipitem-1">
+									<name>A</name>
+									<start>0</start>
+									<end>50</end>
+									<in>0</in>
+									<out>50</out>
+								</clipitem>
+								<transitionitem>
+									<start>40</start>
+									<end>60</end>
+									<alignment>center</alignment>
+									<effect>
+										<name>Cross Dissolve</name>
+										<effectid>Cross Dissolve</effectid>
+										<effecttype>transition</effecttype>
+										<mediatype>video</mediatype>
+									</effect>
+								</transitionitem>
+								<generatoritem id="generatoritem-1">
+									<name>Slug</name>
+									<start>50</start>
+									<end>100</end>
+									<in>0</in>
+									<out>50</out>
+									<effect>
+										<name>Slug</name>
+										<effectid>slug</effectid>
+										<effecttype>generator</effecttype>
+										<mediatype>video</mediatype>
+									</effect>
+								</generatoritem>
+							</track>
+						</video>
+					</media>
+				</sequence>
+				<clip id="masterclip-1">
+					<name>Clip</name>
+					<rate>
+						<timebase>25</timebase>
+					</rate>
+				</clip>
+				<sequence id="sequence-1">
+					<name>Cut</name>
+					<rate>
+						<timebase>25</timebase>
+					</rate>
+					<media>
+						<video>
+							<track>
+								<clipitem id="clipitem-2">
+									<name>Nest</name>
+									<start>0</start>
+									<end>50</end>
+									<in>0</in>
+									<out>50</out>
+									<sequence id="sequence-2"/>
+								</clipitem>
+								<clipitem id="clipitem-3">
+									<name>Missing</name>
+									<start>50</start>
+									<end>100</end>
+									<in>0</in>
+									<out>50</out>
+									<sequence id="sequence-3"/>
+								</clipitem>
+							</track>
+						</video>
+					</media>
+				</sequence>
+			</children>
+		</project>
+	</xmeml>`
+
+	x, err := PackageXEML(ImportRawXEML([]byte(xc)))
+
+	errs, ok := err.(PackageErrors)
+	missing := "xmeml/project/children/sequence[2]/media/video/track/clipitem[2]/sequence"
+	if !ok || len(errs) != 1 || errs[0].Path != missing {
+		t.Fatalf("undefined nested sequence not reported: %v", err)
+	}
+
+	p := x.Project
+	if len(p.Items) != 3 || p.Items[0] != p.Sequences[0] || p.Items[1] != p.Clips[0] || p.Items[2] != p.Sequences[1] {
+		t.Fatal("bin items not packaged in document order")
+	}
+
+	nest := x.Project.Sequences[0].Video[0]
+	if len(nest.Items) != 3 || nest.Items[0] != nest.ClipItems[0] || nest.Items[1] != nest.TransitionItems[0] ||
+		nest.Items[2] != nest.GeneratorItems[0] {
+		t.Fatal("track items not packaged in document order")
+	}
+
+	if tr := nest.TransitionItems[0]; tr.Start != 40 || tr.End != 60 || tr.Alignment != "center" || tr.EffectID == "" {
+		t.Error("transition item not packaged")
+	}
+
+	if g := nest.GeneratorItems[0]; g.ID != "generatoritem-1" || g.EffectID != "slug" || g.Start != 50 || g.Out != 50 {
+		t.Error("generator item not packaged")
+	}
+
+	if ci := x.Project.Sequences[1].Video[0].ClipItems[0]; ci.Sequence != x.Project.Sequences[0] {
+		t.Error("nested sequence reference not resolved to its definition")
+	}
+}